@@ -10,18 +10,26 @@ import (
 
 func main() {
 	log.Println("Starting API server...")
-	// Initialize packs calculator service, database and repository.
-	service, cleanup, err := initializePacksCalculatorService()
+	// Initialize services, database and repositories.
+	svc, cleanup, err := initializeServices()
 	if err != nil {
 		log.Fatalf("failed to initialize services: %v", err)
 	}
 	defer cleanup()
 
 	// Initialize handlers.
-	handler := handlers.NewHandler(service)
+	h := routeHandlers{
+		packs:           handlers.NewHandler(svc.packsCalculator),
+		stock:           handlers.NewStockHandler(svc.stock),
+		audit:           handlers.NewAuditHandler(svc.audit),
+		calculations:    handlers.NewCalculationHandler(svc.history),
+		orders:          handlers.NewOrderHandler(svc.orders),
+		recommendations: handlers.NewRecommendationHandler(svc.recommendations),
+		idempotency:     handlers.Idempotency(svc.idempotency),
+	}
 
 	router := gin.Default()
-	registerRoutes(router, handler)
+	registerRoutes(router, h)
 
 	log.Println("API server listening on port 8080...")
 	if err = router.Run(":8080"); err != nil {
@@ -29,31 +37,115 @@ func main() {
 	}
 }
 
+// appServices groups the services the API handlers are built on.
+type appServices struct {
+	packsCalculator services.PacksCalculator
+	stock           services.StockManager
+	audit           services.AuditLog
+	history         services.CalculationHistory
+	orders          services.OrderManager
+	recommendations services.PackRecommender
+	idempotency     services.IdempotencyGuard
+}
+
+// routeHandlers groups the handlers serving the API routes.
+type routeHandlers struct {
+	packs           *handlers.Handler
+	stock           *handlers.StockHandler
+	audit           *handlers.AuditHandler
+	calculations    *handlers.CalculationHandler
+	orders          *handlers.OrderHandler
+	recommendations *handlers.RecommendationHandler
+	idempotency     gin.HandlerFunc
+}
+
 // registerRoutes sets up the API routes for the application.
-func registerRoutes(router *gin.Engine, handlers *handlers.Handler) {
+func registerRoutes(router *gin.Engine, h routeHandlers) {
 	// Serve static files (index.html, styles and js) from the "static" dir.
 	router.Static("/static", "static")
 
+	// Report unknown routes as problem details, like every other API error.
+	router.NoRoute(handlers.NotFound)
+
 	// API endpoints
 	v1 := router.Group("/api/v1")
 	{
-		v1.POST("/packs", handlers.AddPackSize)
-		v1.DELETE("/packs", handlers.DeletePackSize)
-		v1.GET("/calculate", handlers.CalculatePacks)
+		v1.GET("/packs", h.packs.ListPackSizes)
+		v1.GET("/packs/export", h.packs.ExportPackSizes)
+		v1.GET("/packs/analysis", h.packs.AnalysePackSizes)
+		v1.POST("/packs/recommendations", h.recommendations.RecommendPackSizes)
+		v1.POST("/packs/impact", h.packs.AnalyseImpact)
+		v1.GET("/packs/:id", h.packs.GetPackSize)
+		v1.GET("/calculate", h.packs.CalculatePacks)
+		v1.GET("/calculate/cache", h.packs.GetSolutionCacheStats)
+		v1.POST("/calculate", h.packs.CalculateWhatIf)
+		v1.POST("/calculate/batch", h.packs.CalculateBatch)
+		v1.POST("/calculate/stream", h.packs.CalculateStream)
+		v1.GET("/calculations", h.calculations.ListCalculations)
+		v1.GET("/calculations/:id", h.calculations.GetCalculation)
+		v1.GET("/orders", h.orders.ListOrders)
+		v1.GET("/orders/:id", h.orders.GetOrder)
+		v1.GET("/stock", h.stock.GetStock)
+		v1.GET("/audit", h.audit.GetAuditEntries)
+	}
+
+	// v2 serves the newer response schemas; v1 stays for compatibility.
+	v2 := router.Group("/api/v2")
+	{
+		v2.GET("/calculate", h.packs.CalculatePacksV2)
+	}
+
+	// Mutating endpoints replay their response when retried with the same Idempotency-Key.
+	mutating := router.Group("/api/v1", h.idempotency)
+	{
+		mutating.PATCH("/packs/:id", h.packs.UpdatePackSize)
+		mutating.POST("/packs", h.packs.AddPackSize)
+		mutating.PUT("/packs", h.packs.ReplacePackSizes)
+		mutating.DELETE("/packs", h.packs.DeletePackSize)
+		mutating.POST("/packs/import", h.packs.ImportPackSizes)
+
+		mutating.POST("/orders", h.orders.CreateOrder)
+		mutating.POST("/orders/:id/confirm", h.orders.ConfirmOrder)
+		mutating.POST("/orders/:id/pack", h.orders.PackOrder)
+		mutating.POST("/orders/:id/ship", h.orders.ShipOrder)
+		mutating.POST("/orders/:id/cancel", h.orders.CancelOrder)
+
+		mutating.PUT("/stock/:size", h.stock.SetStock)
+		mutating.POST("/stock/:size/adjust", h.stock.AdjustStock)
+		mutating.DELETE("/stock/:size", h.stock.ClearStock)
 	}
 }
 
-// initializePacksCalculatorService sets up the database and returns a new PacksCalculatorService instance.
-func initializePacksCalculatorService() (services.PacksCalculator, func(), error) {
+// initializeServices sets up the database and returns the services built on its repositories.
+func initializeServices() (*appServices, func(), error) {
 	// Initialize the database.
 	db, cleanup, err := repositories.InitAndCloseDB()
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// Initialize pack size repository and service.
+	// Initialize repositories and services.
 	packSizeRepo := repositories.NewSQLPackSizeRepository(db)
-	service := services.NewPacksCalculatorService(packSizeRepo)
+	stockRepo := repositories.NewSQLStockRepository(db)
+	auditRepo := repositories.NewSQLAuditRepository(db)
+	calculationRepo := repositories.NewSQLCalculationRepository(db)
+	orderRepo := repositories.NewSQLOrderRepository(db)
+	idempotencyRepo := repositories.NewSQLIdempotencyRepository(db)
+
+	packsCalculator := services.NewPacksCalculatorService(packSizeRepo, stockRepo, calculationRepo)
+	// Calculations still work while the cache is cold, so failing to warm it is not fatal.
+	if err := packsCalculator.WarmSolutionCache(); err != nil {
+		log.Printf("failed to warm the solution cache: %v", err)
+	}
+	svc := &appServices{
+		packsCalculator: packsCalculator,
+		stock:           services.NewStockService(stockRepo),
+		audit:           services.NewAuditService(auditRepo),
+		history:         services.NewCalculationHistoryService(calculationRepo),
+		orders:          services.NewOrderService(orderRepo, packsCalculator),
+		recommendations: services.NewRecommendationService(calculationRepo),
+		idempotency:     services.NewIdempotencyService(idempotencyRepo),
+	}
 
-	return service, cleanup, nil
+	return svc, cleanup, nil
 }
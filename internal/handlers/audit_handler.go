@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/models"
+	"net/http"
+)
+
+type AuditHandler struct {
+	service services.AuditLog
+}
+
+// NewAuditHandler creates a new AuditHandler with the provided AuditService.
+func NewAuditHandler(auditService services.AuditLog) *AuditHandler {
+	return &AuditHandler{
+		service: auditService,
+	}
+}
+
+// GetAuditEntries handles listing a page of the catalog changes, optionally within a time range and by
+// one actor.
+func (h *AuditHandler) GetAuditEntries(c *gin.Context) {
+	filter := models.AuditFilter{Actor: c.Query("actor")}
+
+	var ok bool
+	if filter.From, ok = timeParam(c, "from"); !ok {
+		return
+	}
+	if filter.To, ok = timeParam(c, "to"); !ok {
+		return
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		respondInvalidParam(c, "to", "must not be before from")
+		return
+	}
+
+	if filter.Limit, filter.Offset, ok = pageParams(c); !ok {
+		return
+	}
+
+	page, err := h.service.GetAuditEntries(filter)
+	if err != nil {
+		respondError(c, err, "Could not get audit entries")
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
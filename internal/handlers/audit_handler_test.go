@@ -0,0 +1,122 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/handlers"
+	"github.com/klemis/packs-calculator/internal/services/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAuditEntries(t *testing.T) {
+	createdAt := time.Date(2024, 8, 14, 9, 30, 0, 0, time.UTC)
+	entry := models.AuditEntry{
+		ID:        7,
+		Actor:     "alice",
+		Action:    models.AuditActionDelete,
+		CreatedAt: createdAt,
+		Before:    &models.CatalogVersion{ID: 3, CreatedAt: createdAt, PackSizes: []models.PackSize{{ID: 4, Size: 2000}, {ID: 1, Size: 250}}},
+		After:     &models.CatalogVersion{ID: 4, CreatedAt: createdAt, PackSizes: []models.PackSize{{ID: 1, Size: 250}}},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		filter         models.AuditFilter
+		mockResponse   *models.AuditPage
+		mockError      error
+		expectService  bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "Filtered by time range and actor",
+			query: "?from=2024-07-01T00:00:00Z&to=2024-09-30T23:59:59Z&actor=alice&limit=1&offset=2",
+			filter: models.AuditFilter{
+				From:   time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+				To:     time.Date(2024, 9, 30, 23, 59, 59, 0, time.UTC),
+				Actor:  "alice",
+				Limit:  1,
+				Offset: 2,
+			},
+			mockResponse:   &models.AuditPage{Entries: []models.AuditEntry{entry}, Total: 3, Limit: 1, Offset: 2},
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"entries":[{"id":7,"actor":"alice","action":"delete","created_at":"2024-08-14T09:30:00Z",` +
+				`"before":{"id":3,"created_at":"2024-08-14T09:30:00Z","packs":[` +
+				`{"id":4,"size":2000,"price":0,"weight":0,"handling_cost":0},{"id":1,"size":250,"price":0,"weight":0,"handling_cost":0}]},` +
+				`"after":{"id":4,"created_at":"2024-08-14T09:30:00Z","packs":[{"id":1,"size":250,"price":0,"weight":0,"handling_cost":0}]}}],` +
+				`"total":3,"limit":1,"offset":2}`,
+		},
+		{
+			name:           "Unfiltered",
+			filter:         models.AuditFilter{Limit: 50},
+			mockResponse:   &models.AuditPage{Entries: []models.AuditEntry{}, Limit: 50},
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"entries":[],"total":0,"limit":50,"offset":0}`,
+		},
+		{
+			name:           "Invalid from",
+			query:          "?from=yesterday",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid from parameter","instance":"/audit","errors":[{"field":"from","message":"must be an RFC 3339 timestamp"}]}`,
+		},
+		{
+			name:           "Range ends before it starts",
+			query:          "?from=2024-09-30T00:00:00Z&to=2024-07-01T00:00:00Z",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid to parameter","instance":"/audit","errors":[{"field":"to","message":"must not be before from"}]}`,
+		},
+		{
+			name:           "Limit too large",
+			query:          "?limit=501",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid limit parameter","instance":"/audit","errors":[{"field":"limit","message":"must be a whole number between 1 and 500"}]}`,
+		},
+		{
+			name:           "Negative offset",
+			query:          "?offset=-1",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid offset parameter","instance":"/audit","errors":[{"field":"offset","message":"must be a whole number of at least 0"}]}`,
+		},
+		{
+			name:           "Service error",
+			filter:         models.AuditFilter{Limit: 50},
+			mockError:      errors.New("some error"),
+			expectService:  true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"type":"/problems/internal-error","title":"Internal server error","status":500,"detail":"Could not get audit entries","instance":"/audit"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockAuditLog(ctrl)
+
+			if tt.expectService {
+				mockService.EXPECT().GetAuditEntries(tt.filter).Return(tt.mockResponse, tt.mockError).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewAuditHandler(mockService)
+			router.GET("/audit", h.GetAuditEntries)
+
+			req, _ := http.NewRequest("GET", "/audit"+tt.query, nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/models"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+type CalculationHandler struct {
+	service services.CalculationHistory
+}
+
+// NewCalculationHandler creates a new CalculationHandler with the provided CalculationHistoryService.
+func NewCalculationHandler(historyService services.CalculationHistory) *CalculationHandler {
+	return &CalculationHandler{
+		service: historyService,
+	}
+}
+
+// ListCalculations handles listing a page of past calculations, optionally filtered by when they
+// were made and by quantity.
+func (h *CalculationHandler) ListCalculations(c *gin.Context) {
+	filter := models.CalculationFilter{MaxQuantity: math.MaxUint64}
+
+	var ok bool
+	if filter.From, ok = timeParam(c, "from"); !ok {
+		return
+	}
+	if filter.To, ok = timeParam(c, "to"); !ok {
+		return
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		respondInvalidParam(c, "to", "must not be before from")
+		return
+	}
+
+	if value := c.Query("min_quantity"); value != "" {
+		minQuantity, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			respondInvalidParam(c, "min_quantity", "must be a whole number between 0 and 18446744073709551615")
+			return
+		}
+		filter.MinQuantity = minQuantity
+	}
+	if value := c.Query("max_quantity"); value != "" {
+		maxQuantity, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			respondInvalidParam(c, "max_quantity", "must be a whole number between 0 and 18446744073709551615")
+			return
+		}
+		filter.MaxQuantity = maxQuantity
+	}
+	if filter.MaxQuantity < filter.MinQuantity {
+		respondInvalidParam(c, "max_quantity", "must not be less than min_quantity")
+		return
+	}
+
+	if filter.Limit, filter.Offset, ok = pageParams(c); !ok {
+		return
+	}
+
+	page, err := h.service.ListCalculations(filter)
+	if err != nil {
+		respondError(c, err, "Could not list calculations")
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetCalculation handles reading a single past calculation by ID.
+func (h *CalculationHandler) GetCalculation(c *gin.Context) {
+	id, ok := recordIDParam(c)
+	if !ok {
+		return
+	}
+
+	record, err := h.service.GetCalculation(id)
+	if err != nil {
+		respondError(c, err, "Could not get calculation")
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
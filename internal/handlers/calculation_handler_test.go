@@ -0,0 +1,171 @@
+package handlers_test
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/handlers"
+	"github.com/klemis/packs-calculator/internal/services/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListCalculations(t *testing.T) {
+	createdAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	record := models.CalculationRecord{
+		ID: 9, Quantity: 251, Strategy: "optimal", CatalogVersion: 5, Packs: map[uint32]uint64{500: 1},
+		Items: 500, Overfill: 249, TotalCost: 4, LatencyMicros: 80, Caller: "alice", CreatedAt: createdAt,
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		filter         models.CalculationFilter
+		mockResponse   *models.CalculationPage
+		mockError      error
+		expectService  bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "Filtered page",
+			query: "?from=2024-09-01T00:00:00Z&to=2024-10-31T00:00:00Z&min_quantity=100&max_quantity=1000&limit=1&offset=4",
+			filter: models.CalculationFilter{
+				From:        time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+				To:          time.Date(2024, 10, 31, 0, 0, 0, 0, time.UTC),
+				MinQuantity: 100,
+				MaxQuantity: 1000,
+				Limit:       1,
+				Offset:      4,
+			},
+			mockResponse:   &models.CalculationPage{Calculations: []models.CalculationRecord{record}, Total: 5, Limit: 1, Offset: 4},
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"calculations":[{"id":9,"quantity":251,"strategy":"optimal","catalog_version":5,"shipping_rate":0,` +
+				`"packs":{"500":1},"items":500,"overfill":249,"total_cost":4,"latency_us":80,"caller":"alice",` +
+				`"created_at":"2024-10-01T12:00:00Z"}],"total":5,"limit":1,"offset":4}`,
+		},
+		{
+			name:           "Defaults",
+			filter:         models.CalculationFilter{MaxQuantity: math.MaxUint64, Limit: 50},
+			mockResponse:   &models.CalculationPage{Calculations: []models.CalculationRecord{}, Limit: 50},
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"calculations":[],"total":0,"limit":50,"offset":0}`,
+		},
+		{
+			name:           "Limit too large",
+			query:          "?limit=501",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid limit parameter","instance":"/calculations","errors":[{"field":"limit","message":"must be a whole number between 1 and 500"}]}`,
+		},
+		{
+			name:           "Quantity range inverted",
+			query:          "?min_quantity=1000&max_quantity=100",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid max_quantity parameter","instance":"/calculations","errors":[{"field":"max_quantity","message":"must not be less than min_quantity"}]}`,
+		},
+		{
+			name:           "Invalid to",
+			query:          "?to=tomorrow",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid to parameter","instance":"/calculations","errors":[{"field":"to","message":"must be an RFC 3339 timestamp"}]}`,
+		},
+		{
+			name:           "Service error",
+			filter:         models.CalculationFilter{MaxQuantity: math.MaxUint64, Limit: 50},
+			mockError:      errors.New("some error"),
+			expectService:  true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"type":"/problems/internal-error","title":"Internal server error","status":500,"detail":"Could not list calculations","instance":"/calculations"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockCalculationHistory(ctrl)
+
+			if tt.expectService {
+				mockService.EXPECT().ListCalculations(tt.filter).Return(tt.mockResponse, tt.mockError).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewCalculationHandler(mockService)
+			router.GET("/calculations", h.ListCalculations)
+
+			req, _ := http.NewRequest("GET", "/calculations"+tt.query, nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
+func TestGetCalculation(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		mockResponse   *models.CalculationRecord
+		mockError      error
+		expectService  bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Valid request",
+			path:           "/calculations/9",
+			mockResponse:   &models.CalculationRecord{ID: 9, Quantity: 251, Strategy: "optimal", Packs: map[uint32]uint64{500: 1}},
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"id":9,"quantity":251,"strategy":"optimal","catalog_version":0,"shipping_rate":0,"packs":{"500":1},` +
+				`"items":0,"overfill":0,"total_cost":0,"latency_us":0,"caller":"","created_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:           "Calculation not found",
+			path:           "/calculations/9",
+			mockError:      models.ErrCalculationNotFound,
+			expectService:  true,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"type":"/problems/calculation-not-found","title":"Calculation not found","status":404,"detail":"calculation not found","instance":"/calculations/9"}`,
+		},
+		{
+			name:           "Invalid id",
+			path:           "/calculations/abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid id parameter","instance":"/calculations/abc","errors":[{"field":"id","message":"must be a positive whole number"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockCalculationHistory(ctrl)
+
+			if tt.expectService {
+				mockService.EXPECT().GetCalculation(uint64(9)).Return(tt.mockResponse, tt.mockError).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewCalculationHandler(mockService)
+			router.GET("/calculations/:id", h.GetCalculation)
+
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/models"
+	"io"
+	"log"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ndjsonContentType is the media type of newline-delimited JSON.
+	ndjsonContentType = "application/x-ndjson"
+	// csvContentType is the media type of CSV files.
+	csvContentType = "text/csv"
+)
+
+// maxStreamLineLength caps the length of a line of a streamed NDJSON order file.
+const maxStreamLineLength = 64 * 1024
+
+// streamCSVColumns are the columns of a streamed CSV result.
+var streamCSVColumns = []string{"line", "id", "quantity", "calculation_id", "packs", "total_cost", "error"}
+
+// CalculateStream handles calculating the packs for every line of an NDJSON or CSV order file, streaming
+// a result per line back in the same format as it is computed. Lines that cannot be read or calculated
+// report their problem inline without ending the stream.
+func (h *Handler) CalculateStream(c *gin.Context) {
+	opts, ok := calculateOptions(c)
+	if !ok {
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+	var items services.BatchItemSeq
+	var writer streamWriter
+	switch mediaType {
+	case ndjsonContentType, "application/ndjson":
+		items = ndjsonItems(c.Request.Body)
+		writer = &ndjsonStreamWriter{encoder: json.NewEncoder(c.Writer)}
+		c.Header("Content-Type", ndjsonContentType)
+	case csvContentType:
+		var err error
+		if items, err = csvItems(c.Request.Body); err != nil {
+			respondError(c, err, "Could not read order file")
+			return
+		}
+		writer = &csvStreamWriter{writer: csv.NewWriter(c.Writer)}
+		c.Header("Content-Type", csvContentType+"; charset=utf-8")
+	default:
+		respondProblem(c, problem{
+			Type:   "unsupported-media-type",
+			Title:  "Unsupported media type",
+			Status: http.StatusUnsupportedMediaType,
+			Detail: "Send the order file as " + ndjsonContentType + " or " + csvContentType,
+		})
+		return
+	}
+
+	// Results are written while the order file is still being read. Without full duplex the server may
+	// hold them back until the whole file is read, which still streams correctly, just later.
+	if err := http.NewResponseController(c.Writer).EnableFullDuplex(); err != nil {
+		log.Printf("streaming calculation results without full duplex: %v", err)
+	}
+
+	err := h.service.CalculateStream(items, opts, func(result services.BatchResult) error {
+		if err := writer.write(newBatchItemResponse(result)); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err == nil {
+		return
+	}
+
+	if !c.Writer.Written() {
+		c.Header("Content-Type", "")
+		respondError(c, err, "Could not calculate packs")
+		return
+	}
+
+	// The results sent so far stand; end the stream with the problem that cut it short.
+	streamProblem := problemFor(err, "Could not calculate packs")
+	streamProblem.Type = problemTypeBase + streamProblem.Type
+	if err := writer.write(batchItemResponse{Error: &streamProblem}); err != nil {
+		log.Printf("failed to report stream error: %v", err)
+	}
+	c.Writer.Flush()
+}
+
+// ndjsonItems reads the order items of an NDJSON file, one JSON object per line. Blank lines are skipped.
+func ndjsonItems(r io.Reader) services.BatchItemSeq {
+	return func(yield func(services.BatchItem, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 4096), maxStreamLineLength)
+
+		line := 0
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+
+			item := services.BatchItem{Line: line}
+			var req models.BatchItemRequest
+			err := json.Unmarshal([]byte(text), &req)
+			switch {
+			case err != nil:
+				err = fmt.Errorf("%w: %s", models.ErrInvalidLine, err)
+			case req.Quantity == nil:
+				err = fmt.Errorf("%w: quantity is required", models.ErrInvalidLine)
+			default:
+				item.ID, item.Quantity = req.ID, *req.Quantity
+			}
+			if !yield(item, err) {
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			yield(services.BatchItem{Line: line + 1}, fmt.Errorf("%w: %s", models.ErrInvalidLine, err))
+		}
+	}
+}
+
+// csvItems reads the header of a CSV order file and returns its order items. The header must name a
+// quantity column and may name an id column; other columns are ignored.
+func csvItems(r io.Reader) (services.BatchItemSeq, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: line 1: the file is empty", models.ErrInvalidLine)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", models.ErrInvalidLine, err)
+	}
+
+	quantityColumn, idColumn := -1, -1
+	for i, column := range header {
+		switch strings.ToLower(strings.TrimSpace(column)) {
+		case "quantity":
+			quantityColumn = i
+		case "id":
+			idColumn = i
+		}
+	}
+	if quantityColumn < 0 {
+		return nil, fmt.Errorf("%w: line 1: the header has no quantity column", models.ErrInvalidLine)
+	}
+
+	return func(yield func(services.BatchItem, error) bool) {
+		for {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			line, _ := reader.FieldPos(0)
+			item := services.BatchItem{Line: line}
+			if err == nil && idColumn >= 0 && idColumn < len(record) {
+				item.ID = strings.TrimSpace(record[idColumn])
+			}
+			var parseErr *csv.ParseError
+			switch {
+			case errors.As(err, &parseErr):
+				item.Line = parseErr.Line
+				err = fmt.Errorf("%w: %s", models.ErrInvalidLine, parseErr.Err)
+			case err != nil:
+				yield(item, fmt.Errorf("%w: %s", models.ErrInvalidLine, err))
+				return
+			case quantityColumn >= len(record):
+				err = fmt.Errorf("%w: quantity is required", models.ErrInvalidLine)
+			default:
+				quantity, parseErr := strconv.ParseUint(strings.TrimSpace(record[quantityColumn]), 10, 64)
+				if parseErr != nil {
+					err = fmt.Errorf("%w: quantity must be a whole number between 0 and 18446744073709551615", models.ErrInvalidLine)
+				}
+				item.Quantity = quantity
+			}
+			if !yield(item, err) {
+				return
+			}
+		}
+	}, nil
+}
+
+// streamWriter writes the results of a streamed calculation in the format of the order file.
+type streamWriter interface {
+	write(result batchItemResponse) error
+}
+
+// ndjsonStreamWriter writes each result as a JSON object on its own line.
+type ndjsonStreamWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonStreamWriter) write(result batchItemResponse) error {
+	return w.encoder.Encode(result)
+}
+
+// csvStreamWriter writes each result as a row of the streamCSVColumns, starting with a header row.
+type csvStreamWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func (w *csvStreamWriter) write(result batchItemResponse) error {
+	if !w.headerWritten {
+		if err := w.writer.Write(streamCSVColumns); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	row := make([]string, len(streamCSVColumns))
+	if result.Line > 0 {
+		row[0] = strconv.Itoa(result.Line)
+		row[2] = strconv.FormatUint(result.Quantity, 10)
+	}
+	row[1] = result.ID
+	if result.Error != nil {
+		row[6] = result.Error.Detail
+	} else {
+		row[3] = strconv.FormatUint(result.CalculationID, 10)
+		row[4] = formatPacks(result.Packs)
+		row[5] = strconv.FormatFloat(result.Cost.Total, 'f', -1, 64)
+	}
+
+	if err := w.writer.Write(row); err != nil {
+		return err
+	}
+	w.writer.Flush()
+
+	return w.writer.Error()
+}
+
+// formatPacks formats packs as size:count pairs separated by semicolons, largest size first.
+func formatPacks(packs map[uint32]uint64) string {
+	sizes := slices.Sorted(maps.Keys(packs))
+	pairs := make([]string, len(sizes))
+	for i, size := range slices.Backward(sizes) {
+		pairs[len(sizes)-1-i] = fmt.Sprintf("%d:%d", size, packs[size])
+	}
+
+	return strings.Join(pairs, ";")
+}
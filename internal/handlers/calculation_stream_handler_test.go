@@ -0,0 +1,168 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/handlers"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/internal/services/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// streamCalculation stands in for the service, calculating every quantity as one 250 pack and
+// running out of stock for quantities above 1000.
+func streamCalculation(items services.BatchItemSeq, _ services.CalculateOptions, emit func(services.BatchResult) error) error {
+	id := uint64(100)
+	for item, err := range items {
+		result := services.BatchResult{Item: item, Err: err}
+		if err == nil && item.Quantity > 1000 {
+			result.Err = &services.InsufficientStockError{Quantity: item.Quantity, Available: 1000, Stock: map[uint32]uint32{250: 4}}
+		}
+		if result.Err == nil {
+			result.Calculation = &models.Calculation{
+				ID: id, Quantity: item.Quantity, Packs: map[uint32]uint64{500: 1, 250: 1}, Cost: models.CostBreakdown{Total: 6.5},
+			}
+			id++
+		}
+		if err := emit(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestCalculateStream(t *testing.T) {
+	tests := []struct {
+		name           string
+		contentType    string
+		payload        string
+		mockError      error
+		expectService  bool
+		expectedStatus int
+		expectedType   string
+		expectedBody   string
+	}{
+		{
+			name:           "NDJSON",
+			contentType:    "application/x-ndjson",
+			payload:        "{\"id\": \"a\", \"quantity\": 700}\n\n{\"quantity\": 2000}\nnot json\n{\"id\": \"b\"}\n",
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedType:   "application/x-ndjson",
+			expectedBody: `{"line":1,"id":"a","quantity":700,"calculation_id":100,"packs":{"250":1,"500":1},"cost":{"lines":null,"price":0,"handling_cost":0,"weight":0,"shipping_cost":0,"total":6.5}}` + "\n" +
+				`{"line":3,"quantity":2000,"error":{"available":1000,"detail":"insufficient stock: 2000 items ordered, 1000 available, short by 1000","quantity":2000,"shortfall":1000,"status":409,"stock":{"250":4},"title":"Insufficient stock","type":"/problems/insufficient-stock"}}` + "\n" +
+				`{"line":4,"quantity":0,"error":{"type":"/problems/invalid-line","title":"Invalid line","status":400,"detail":"invalid line: invalid character 'o' in literal null (expecting 'u')"}}` + "\n" +
+				`{"line":5,"quantity":0,"error":{"type":"/problems/invalid-line","title":"Invalid line","status":400,"detail":"invalid line: quantity is required"}}` + "\n",
+		},
+		{
+			name:           "CSV",
+			contentType:    "text/csv",
+			payload:        "ID,Quantity,note\na,700,x\nb,abc\nc\n,2000\n",
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/csv; charset=utf-8",
+			expectedBody: "line,id,quantity,calculation_id,packs,total_cost,error\n" +
+				"2,a,700,100,500:1;250:1,6.5,\n" +
+				"3,b,0,,,,invalid line: quantity must be a whole number between 0 and 18446744073709551615\n" +
+				"4,c,0,,,,invalid line: quantity is required\n" +
+				"5,,2000,,,,\"insufficient stock: 2000 items ordered, 1000 available, short by 1000\"\n",
+		},
+		{
+			name:           "CSV without quantity column",
+			contentType:    "text/csv",
+			payload:        "id,count\na,700\n",
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   "application/problem+json",
+			expectedBody:   `{"type":"/problems/invalid-line","title":"Invalid line","status":400,"detail":"invalid line: line 1: the header has no quantity column","instance":"/calculate/stream"}`,
+		},
+		{
+			name:           "Unsupported media type",
+			contentType:    "application/json",
+			payload:        `{"quantity": 700}`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedType:   "application/problem+json",
+			expectedBody:   `{"type":"/problems/unsupported-media-type","title":"Unsupported media type","status":415,"detail":"Send the order file as application/x-ndjson or text/csv","instance":"/calculate/stream"}`,
+		},
+		{
+			name:           "No pack sizes",
+			contentType:    "application/x-ndjson",
+			payload:        `{"quantity": 700}`,
+			mockError:      models.ErrNoPackSizes,
+			expectService:  true,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedType:   "application/problem+json",
+			expectedBody:   `{"type":"/problems/no-pack-sizes","title":"No pack sizes configured","status":422,"detail":"no pack sizes configured","instance":"/calculate/stream"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockPacksCalculator(ctrl)
+
+			if tt.expectService {
+				opts := services.CalculateOptions{Caller: "warehouse"}
+				call := mockService.EXPECT().CalculateStream(gomock.Any(), opts, gomock.Any()).Times(1)
+				if tt.mockError != nil {
+					call.Return(tt.mockError)
+				} else {
+					call.DoAndReturn(streamCalculation)
+				}
+			}
+
+			router := gin.Default()
+			h := handlers.NewHandler(mockService)
+			router.POST("/calculate/stream", h.CalculateStream)
+
+			req, _ := http.NewRequest("POST", "/calculate/stream", bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.Header.Set("X-Actor", "warehouse")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Equal(t, tt.expectedType, resp.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
+func TestCalculateStreamInterrupted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockPacksCalculator(ctrl)
+
+	// The history fails after the first chunk has already been streamed.
+	mockService.EXPECT().CalculateStream(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(items services.BatchItemSeq, _ services.CalculateOptions, emit func(services.BatchResult) error) error {
+			err := emit(services.BatchResult{
+				Item:        services.BatchItem{Line: 1, Quantity: 250},
+				Calculation: &models.Calculation{ID: 100, Packs: map[uint32]uint64{250: 1}, Cost: models.CostBreakdown{Total: 2.5}},
+			})
+			if err != nil {
+				return err
+			}
+			return errors.New("insert failed")
+		}).Times(1)
+
+	router := gin.Default()
+	h := handlers.NewHandler(mockService)
+	router.POST("/calculate/stream", h.CalculateStream)
+
+	req, _ := http.NewRequest("POST", "/calculate/stream", bytes.NewBufferString("quantity\n250\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "line,id,quantity,calculation_id,packs,total_cost,error\n"+
+		"1,,250,100,250:1,2.5,\n"+
+		",,,,,,Could not calculate packs\n", resp.Body.String())
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"cmp"
+	"github.com/gin-gonic/gin"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/models"
+	"net/http"
+	"slices"
+)
+
+// calculationV2Response is the v2 schema of a calculation: numeric totals, and the packs as lines
+// ordered from the largest size down, so responses compare byte for byte.
+type calculationV2Response struct {
+	CalculationID  uint64                `json:"calculation_id"`
+	Requested      uint64                `json:"requested"`
+	Shipped        uint64                `json:"shipped"`
+	Overfill       uint64                `json:"overfill"`
+	Shortfall      uint64                `json:"shortfall"`
+	PackCount      uint64                `json:"pack_count"`
+	Packs          []packLineResponse    `json:"packs"`
+	Strategy       string                `json:"strategy"`
+	Mode           string                `json:"mode"`
+	CatalogVersion uint32                `json:"catalog_version"`
+	Cost           models.CostBreakdown  `json:"cost"`
+	Alternatives   []alternativeResponse `json:"alternatives,omitempty"`
+}
+
+// alternativeResponse is one of the best pack combinations for a calculation, with its packs as lines
+// ordered from the largest size down.
+type alternativeResponse struct {
+	Packs     []packLineResponse `json:"packs"`
+	Shipped   uint64             `json:"shipped"`
+	Overfill  uint64             `json:"overfill"`
+	PackCount uint64             `json:"pack_count"`
+	TotalCost float64            `json:"total_cost"`
+}
+
+// packLineResponse is the packs of one size in a calculation.
+type packLineResponse struct {
+	Size  uint32 `json:"size"`
+	Count uint64 `json:"count"`
+	Items uint64 `json:"items"`
+}
+
+// newCalculationV2Response describes a calculation made in the given fill mode in the v2 schema.
+func newCalculationV2Response(calculation *models.Calculation, mode string) calculationV2Response {
+	if mode == "" {
+		mode = services.FillOverfill
+	}
+
+	response := calculationV2Response{
+		CalculationID:  calculation.ID,
+		Requested:      calculation.Quantity,
+		Strategy:       calculation.Strategy,
+		Mode:           mode,
+		CatalogVersion: calculation.CatalogVersion,
+		Cost:           calculation.Cost,
+	}
+	response.Packs, response.Shipped, response.PackCount = packLines(calculation.Packs)
+
+	requested := calculation.Quantity
+	response.Overfill = response.Shipped - min(response.Shipped, requested)
+	response.Shortfall = requested - min(response.Shipped, requested)
+
+	for _, alternative := range calculation.Alternatives {
+		lines, _, _ := packLines(alternative.Packs)
+		response.Alternatives = append(response.Alternatives, alternativeResponse{
+			Packs:     lines,
+			Shipped:   alternative.Items,
+			Overfill:  alternative.Overfill,
+			PackCount: alternative.PackCount,
+			TotalCost: alternative.TotalCost,
+		})
+	}
+
+	return response
+}
+
+// packLines lists a pack combination as lines from the largest size down, with the items it ships and
+// its number of packs.
+func packLines(packs map[uint32]uint64) (lines []packLineResponse, items, count uint64) {
+	lines = make([]packLineResponse, 0, len(packs))
+	for size, n := range packs {
+		if n == 0 {
+			continue
+		}
+
+		lineItems := uint64(size) * n
+		lines = append(lines, packLineResponse{Size: size, Count: n, Items: lineItems})
+		items += lineItems
+		count += n
+	}
+	slices.SortFunc(lines, func(a, b packLineResponse) int { return cmp.Compare(b.Size, a.Size) })
+
+	return lines, items, count
+}
+
+// CalculatePacksV2 handles calculating the packs for a given item quantity, responding with the v2
+// schema. It takes the same query parameters as CalculatePacks, alternatives included.
+func (h *Handler) CalculatePacksV2(c *gin.Context) {
+	quantity, ok := quantityParam(c)
+	if !ok {
+		return
+	}
+
+	opts, ok := calculateOptions(c)
+	if !ok {
+		return
+	}
+	if opts.Alternatives, ok = alternativesParam(c); !ok {
+		return
+	}
+
+	calculation, err := h.service.CalculatePacks(quantity, opts)
+	if err != nil {
+		respondError(c, err, "Could not calculate packs")
+		return
+	}
+
+	c.JSON(http.StatusOK, newCalculationV2Response(calculation, opts.Mode.Name))
+}
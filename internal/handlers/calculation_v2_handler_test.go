@@ -0,0 +1,128 @@
+package handlers_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/handlers"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/internal/services/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCalculatePacksV2(t *testing.T) {
+	cost := models.CostBreakdown{Lines: []models.CostLine{}, Total: 12}
+	costBody := `"cost":{"lines":[],"price":0,"handling_cost":0,"weight":0,"shipping_cost":0,"total":12}`
+
+	tests := []struct {
+		name           string
+		queryParam     string
+		mode           services.FillMode
+		alternatives   int
+		mockResponse   *models.Calculation
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:       "Overfilled order",
+			queryParam: "quantity=12001",
+			mockResponse: &models.Calculation{
+				ID: 7, Quantity: 12001, Strategy: "optimal", CatalogVersion: 4,
+				Packs: map[uint32]uint64{250: 1, 5000: 2, 2000: 1}, Cost: cost,
+			},
+			expectedStatus: http.StatusOK,
+			// Pack lines are listed from the largest size down, whatever the map order.
+			expectedBody: `{"calculation_id":7,"requested":12001,"shipped":12250,"overfill":249,"shortfall":0,"pack_count":4,` +
+				`"packs":[{"size":5000,"count":2,"items":10000},{"size":2000,"count":1,"items":2000},{"size":250,"count":1,"items":250}],` +
+				`"strategy":"optimal","mode":"overfill","catalog_version":4,` + costBody + `}`,
+		},
+		{
+			name:       "Underfilled order",
+			queryParam: "quantity=12001&mode=underfill",
+			mode:       services.FillMode{Name: services.FillUnderfill},
+			mockResponse: &models.Calculation{
+				ID: 8, Quantity: 12001, Strategy: "optimal", CatalogVersion: 4,
+				Packs: map[uint32]uint64{5000: 2, 2000: 1}, Cost: cost,
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"calculation_id":8,"requested":12001,"shipped":12000,"overfill":0,"shortfall":1,"pack_count":3,` +
+				`"packs":[{"size":5000,"count":2,"items":10000},{"size":2000,"count":1,"items":2000}],` +
+				`"strategy":"optimal","mode":"underfill","catalog_version":4,` + costBody + `}`,
+		},
+		{
+			name:       "Nothing to pack",
+			queryParam: "quantity=0",
+			mockResponse: &models.Calculation{
+				ID: 9, Quantity: 0, Strategy: "optimal", CatalogVersion: 4, Packs: map[uint32]uint64{}, Cost: cost,
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"calculation_id":9,"requested":0,"shipped":0,"overfill":0,"shortfall":0,"pack_count":0,"packs":[],` +
+				`"strategy":"optimal","mode":"overfill","catalog_version":4,` + costBody + `}`,
+		},
+		{
+			name:         "Alternatives",
+			queryParam:   "quantity=12001&alternatives=2",
+			alternatives: 2,
+			mockResponse: &models.Calculation{
+				ID: 10, Quantity: 12001, Strategy: "optimal", CatalogVersion: 4,
+				Packs: map[uint32]uint64{5000: 2, 2000: 1, 250: 1}, Cost: cost,
+				Alternatives: []models.PackAlternative{
+					{Packs: map[uint32]uint64{250: 1, 5000: 2, 2000: 1}, Items: 12250, Overfill: 249, PackCount: 4, TotalCost: 12},
+					{Packs: map[uint32]uint64{500: 1, 2000: 6}, Items: 12500, Overfill: 499, PackCount: 7, TotalCost: 15},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"calculation_id":10,"requested":12001,"shipped":12250,"overfill":249,"shortfall":0,"pack_count":4,` +
+				`"packs":[{"size":5000,"count":2,"items":10000},{"size":2000,"count":1,"items":2000},{"size":250,"count":1,"items":250}],` +
+				`"strategy":"optimal","mode":"overfill","catalog_version":4,` + costBody + `,"alternatives":[` +
+				`{"packs":[{"size":5000,"count":2,"items":10000},{"size":2000,"count":1,"items":2000},{"size":250,"count":1,"items":250}],"shipped":12250,"overfill":249,"pack_count":4,"total_cost":12},` +
+				`{"packs":[{"size":2000,"count":6,"items":12000},{"size":500,"count":1,"items":500}],"shipped":12500,"overfill":499,"pack_count":7,"total_cost":15}]}`,
+		},
+		{
+			name:           "No pack sizes",
+			queryParam:     "quantity=12001",
+			mockError:      models.ErrNoPackSizes,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"type":"/problems/no-pack-sizes","title":"No pack sizes configured","status":422,"detail":"no pack sizes configured","instance":"/api/v2/calculate"}`,
+		},
+		{
+			name:           "Missing quantity",
+			queryParam:     "",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid quantity parameter","instance":"/api/v2/calculate","errors":[{"field":"quantity","message":"is required"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockPacksCalculator(ctrl)
+
+			if tt.mockResponse != nil || tt.mockError != nil {
+				quantity := uint64(12001)
+				if tt.mockResponse != nil {
+					quantity = tt.mockResponse.Quantity
+				}
+				opts := services.CalculateOptions{Mode: tt.mode, Alternatives: tt.alternatives, Caller: "warehouse"}
+				mockService.EXPECT().CalculatePacks(quantity, opts).Return(tt.mockResponse, tt.mockError).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewHandler(mockService)
+			router.GET("/api/v2/calculate", h.CalculatePacksV2)
+
+			req, _ := http.NewRequest("GET", "/api/v2/calculate?"+tt.queryParam, nil)
+			req.Header.Set("X-Actor", "warehouse")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Equal(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/models"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultImpactWindow is how far back the calculation history an impact report looks by default.
+const defaultImpactWindow = 30 * 24 * time.Hour
+
+// AnalyseImpact handles reporting how a proposed catalog change would repack orders, without making it.
+func (h *Handler) AnalyseImpact(c *gin.Context) {
+	var req *models.CatalogImpactRequest
+	if err := c.BindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	proposal := services.CatalogProposal{
+		Kind:  req.Change,
+		Packs: packSizes(req.Packs),
+		Size:  req.Size,
+		ID:    req.ID,
+	}
+	if req.Update != nil {
+		proposal.Update = *req.Update
+	}
+	h.respondImpact(c, proposal)
+}
+
+// respondImpact responds with the impact report of a proposed catalog change over the orders the query
+// parameters select: every quantity from quantity_from (default 1) to quantity_to, or else the
+// quantities calculated between from (default 30 days ago) and to.
+func (h *Handler) respondImpact(c *gin.Context, proposal services.CatalogProposal) {
+	opts := services.ImpactOptions{
+		Strategy:     c.Query("strategy"),
+		QuantityFrom: 1,
+		History:      models.CalculationFilter{MinQuantity: 1, MaxQuantity: math.MaxUint64},
+	}
+	params := []struct {
+		name  string
+		value *uint64
+	}{
+		{"quantity_from", &opts.QuantityFrom},
+		{"quantity_to", &opts.QuantityTo},
+	}
+	for _, param := range params {
+		if value := c.Query(param.name); value != "" {
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil || n == 0 {
+				respondInvalidParam(c, param.name, "must be a whole number between 1 and 18446744073709551615")
+				return
+			}
+			*param.value = n
+		}
+	}
+
+	var ok bool
+	if opts.History.From, ok = timeParam(c, "from"); !ok {
+		return
+	}
+	if opts.History.To, ok = timeParam(c, "to"); !ok {
+		return
+	}
+	if opts.QuantityTo == 0 && opts.History.From.IsZero() {
+		opts.History.From = time.Now().Add(-defaultImpactWindow)
+	}
+	if !opts.History.To.IsZero() && opts.History.To.Before(opts.History.From) {
+		respondInvalidParam(c, "to", "must not be before from")
+		return
+	}
+
+	impact, err := h.service.AnalyseImpact(proposal, opts)
+	if err != nil {
+		respondError(c, err, "Could not analyse catalog change impact")
+		return
+	}
+
+	c.JSON(http.StatusOK, impact)
+}
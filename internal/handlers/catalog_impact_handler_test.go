@@ -0,0 +1,208 @@
+package handlers_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/handlers"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/internal/services/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyseImpact(t *testing.T) {
+	impact := &models.CatalogImpact{
+		Change:         services.ChangeRemove,
+		Source:         services.ImpactSourceRange,
+		Strategy:       "min-items",
+		CurrentSizes:   []uint32{500, 250},
+		ProposedSizes:  []uint32{500},
+		Quantities:     2,
+		Orders:         2,
+		ChangedOrders:  1,
+		WorseOrders:    1,
+		OverfillDelta:  big.NewInt(250),
+		PackCountDelta: big.NewInt(0),
+		Worse: []models.QuantityImpact{{
+			Quantity:      1,
+			Orders:        1,
+			Current:       map[uint32]uint64{250: 1},
+			Proposed:      map[uint32]uint64{500: 1},
+			OverfillDelta: models.Delta{Magnitude: 250},
+		}},
+	}
+	size := uint32(300)
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	history := models.CalculationFilter{From: from, MinQuantity: 1, MaxQuantity: math.MaxUint64}
+
+	tests := []struct {
+		name             string
+		method           string
+		path             string
+		payload          string
+		expectedProposal services.CatalogProposal
+		expectedOpts     services.ImpactOptions
+		mockError        error
+		expectedStatus   int
+		expectedBody     string
+	}{
+		{
+			name:             "Standalone report over a range",
+			method:           "POST",
+			path:             "/packs/impact?quantity_from=1&quantity_to=2&strategy=min-items",
+			payload:          `{"change": "remove", "size": 250}`,
+			expectedProposal: services.CatalogProposal{Kind: services.ChangeRemove, Packs: []models.PackSize{}, Size: 250},
+			expectedOpts: services.ImpactOptions{Strategy: "min-items", QuantityFrom: 1, QuantityTo: 2,
+				History: models.CalculationFilter{MinQuantity: 1, MaxQuantity: math.MaxUint64}},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"change":"remove","source":"range","strategy":"min-items","current_sizes":[500,250],"proposed_sizes":[500],` +
+				`"quantities":2,"orders":2,"changed_orders":1,"worse_orders":1,"better_orders":0,"overfill_delta":250,"pack_count_delta":0,` +
+				`"worse":[{"quantity":1,"orders":1,"current":{"250":1},"proposed":{"500":1},"overfill_delta":250,"pack_count_delta":0}]}`,
+		},
+		{
+			name:    "Standalone update over history",
+			method:  "POST",
+			path:    "/packs/impact?from=2024-05-01T00:00:00Z",
+			payload: `{"change": "update", "id": 1, "update": {"size": 300}}`,
+			expectedProposal: services.CatalogProposal{Kind: services.ChangeUpdate, Packs: []models.PackSize{}, ID: 1,
+				Update: models.PackSizeUpdateRequest{Size: &size}},
+			expectedOpts:   services.ImpactOptions{QuantityFrom: 1, History: history},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:             "Dry run of a delete",
+			method:           "DELETE",
+			path:             "/packs?dry_run=true&from=2024-05-01T00:00:00Z",
+			payload:          `{"size": 250}`,
+			expectedProposal: services.CatalogProposal{Kind: services.ChangeRemove, Size: 250},
+			expectedOpts:     services.ImpactOptions{QuantityFrom: 1, History: history},
+			expectedStatus:   http.StatusOK,
+		},
+		{
+			name:             "Dry run of an add",
+			method:           "POST",
+			path:             "/packs?dry_run=true&from=2024-05-01T00:00:00Z",
+			payload:          `{"size": 750, "price": 2.5}`,
+			expectedProposal: services.CatalogProposal{Kind: services.ChangeAdd, Packs: []models.PackSize{{Size: 750, Price: 2.5}}},
+			expectedOpts:     services.ImpactOptions{QuantityFrom: 1, History: history},
+			expectedStatus:   http.StatusOK,
+		},
+		{
+			name:             "Dry run of a replace",
+			method:           "PUT",
+			path:             "/packs?dry_run=true&from=2024-05-01T00:00:00Z",
+			payload:          `{"packs": [{"size": 300}]}`,
+			expectedProposal: services.CatalogProposal{Kind: services.ChangeReplace, Packs: []models.PackSize{{Size: 300}}},
+			expectedOpts:     services.ImpactOptions{QuantityFrom: 1, History: history},
+			expectedStatus:   http.StatusOK,
+		},
+		{
+			name:             "Dry run of an update",
+			method:           "PATCH",
+			path:             "/packs/1?dry_run=true&from=2024-05-01T00:00:00Z",
+			payload:          `{"size": 300}`,
+			expectedProposal: services.CatalogProposal{Kind: services.ChangeUpdate, ID: 1, Update: models.PackSizeUpdateRequest{Size: &size}},
+			expectedOpts:     services.ImpactOptions{QuantityFrom: 1, History: history},
+			expectedStatus:   http.StatusOK,
+		},
+		{
+			name:             "Range too long",
+			method:           "POST",
+			path:             "/packs/impact?quantity_to=20000",
+			payload:          `{"change": "remove", "size": 250}`,
+			expectedProposal: services.CatalogProposal{Kind: services.ChangeRemove, Packs: []models.PackSize{}, Size: 250},
+			expectedOpts: services.ImpactOptions{QuantityFrom: 1, QuantityTo: 20000,
+				History: models.CalculationFilter{MinQuantity: 1, MaxQuantity: math.MaxUint64}},
+			mockError:      fmt.Errorf("%w: at most 10000 quantities can be recalculated", services.ErrInvalidImpactRange),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-impact-range","title":"Invalid impact range","status":400,"detail":"invalid impact range: at most 10000 quantities can be recalculated","instance":"/packs/impact"}`,
+		},
+		{
+			name:           "Invalid dry run",
+			method:         "DELETE",
+			path:           "/packs?dry_run=maybe",
+			payload:        `{"size": 250}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid dry_run parameter","instance":"/packs","errors":[{"field":"dry_run","message":"must be true or false"}]}`,
+		},
+		{
+			name:           "Invalid quantity range",
+			method:         "POST",
+			path:           "/packs/impact?quantity_from=0",
+			payload:        `{"change": "remove", "size": 250}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid quantity_from parameter","instance":"/packs/impact","errors":[{"field":"quantity_from","message":"must be a whole number between 1 and 18446744073709551615"}]}`,
+		},
+		{
+			name:           "History window ending before it starts",
+			method:         "POST",
+			path:           "/packs/impact?from=2024-05-02T00:00:00Z&to=2024-05-01T00:00:00Z",
+			payload:        `{"change": "remove", "size": 250}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid to parameter","instance":"/packs/impact","errors":[{"field":"to","message":"must not be before from"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockPacksCalculator(ctrl)
+			if tt.expectedProposal.Kind != "" {
+				mockService.EXPECT().AnalyseImpact(tt.expectedProposal, tt.expectedOpts).Return(impact, tt.mockError).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewHandler(mockService)
+			router.POST("/packs/impact", h.AnalyseImpact)
+			router.POST("/packs", h.AddPackSize)
+			router.PUT("/packs", h.ReplacePackSizes)
+			router.DELETE("/packs", h.DeletePackSize)
+			router.PATCH("/packs/:id", h.UpdatePackSize)
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBuffer([]byte(tt.payload)))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestAnalyseImpactDefaultWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockPacksCalculator(ctrl)
+	var opts services.ImpactOptions
+	mockService.EXPECT().AnalyseImpact(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ services.CatalogProposal, o services.ImpactOptions) (*models.CatalogImpact, error) {
+			opts = o
+			return &models.CatalogImpact{}, nil
+		}).Times(1)
+
+	router := gin.Default()
+	h := handlers.NewHandler(mockService)
+	router.DELETE("/packs", h.DeletePackSize)
+
+	req, _ := http.NewRequest("DELETE", "/packs?dry_run=true", bytes.NewBuffer([]byte(`{"size": 250}`)))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.WithinDuration(t, time.Now().Add(-30*24*time.Hour), opts.History.From, time.Minute)
+	assert.True(t, opts.History.To.IsZero())
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/klemis/packs-calculator/internal/services"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// catalogContentTypes maps the catalog file formats to their media types.
+var catalogContentTypes = map[string]string{
+	services.CatalogFormatCSV:  "text/csv",
+	services.CatalogFormatJSON: "application/json",
+}
+
+// ExportPackSizes handles downloading the pack catalog as a CSV or JSON file.
+func (h *Handler) ExportPackSizes(c *gin.Context) {
+	format := c.DefaultQuery("format", services.CatalogFormatJSON)
+	contentType, ok := catalogContentTypes[format]
+	if !ok {
+		respondInvalidParam(c, "format", "must be one of: csv json")
+		return
+	}
+
+	var file bytes.Buffer
+	if err := h.service.ExportPackSizes(&file, format); err != nil {
+		respondError(c, err, "Could not export pack sizes")
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="packs.`+format+`"`)
+	c.Data(http.StatusOK, contentType+"; charset=utf-8", file.Bytes())
+}
+
+// ImportPackSizes handles uploading a CSV or JSON catalog file, either as the request body or as the
+// "file" field of a multipart form. The format is taken from the format parameter, else from the
+// uploaded file name or the content type.
+func (h *Handler) ImportPackSizes(c *gin.Context) {
+	opts := services.ImportOptions{
+		Mode:  c.DefaultQuery("mode", services.ImportModeMerge),
+		Actor: actor(c),
+	}
+	if opts.Mode != services.ImportModeMerge && opts.Mode != services.ImportModeReplace {
+		respondInvalidParam(c, "mode", "must be one of: merge replace")
+		return
+	}
+
+	var ok bool
+	if opts.DryRun, ok = dryRunParam(c); !ok {
+		return
+	}
+
+	var file io.Reader = c.Request.Body
+	var fileName string
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			respondInvalidParam(c, "file", "is required")
+			return
+		}
+
+		upload, err := header.Open()
+		if err != nil {
+			respondError(c, err, "Could not read uploaded file")
+			return
+		}
+		defer upload.Close()
+
+		file, fileName = upload, header.Filename
+	}
+
+	opts.Format = catalogFormat(c.Query("format"), fileName, c.ContentType())
+	if _, ok := catalogContentTypes[opts.Format]; !ok {
+		respondInvalidParam(c, "format", "must be one of: csv json")
+		return
+	}
+
+	report, err := h.service.ImportPackSizes(file, opts)
+	if err != nil {
+		respondError(c, err, "Could not import pack sizes")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// catalogFormat picks the format of an imported catalog: the explicit format parameter, else the
+// extension of the uploaded file name, else the content type of the request body.
+func catalogFormat(param, fileName, contentType string) string {
+	if param != "" {
+		return param
+	}
+	if fileName != "" {
+		return strings.ToLower(strings.TrimPrefix(filepath.Ext(fileName), "."))
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	for format, catalogType := range catalogContentTypes {
+		if mediaType == catalogType {
+			return format
+		}
+	}
+
+	return ""
+}
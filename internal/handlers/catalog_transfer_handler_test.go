@@ -0,0 +1,242 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/handlers"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/internal/services/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportPackSizes(t *testing.T) {
+	tests := []struct {
+		name                string
+		query               string
+		format              string
+		mockError           error
+		expectService       bool
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "CSV export",
+			query:               "?format=csv",
+			format:              services.CatalogFormatCSV,
+			expectService:       true,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "size,price,weight,handling_cost\n250,2.5,0,0\n",
+		},
+		{
+			name:                "JSON export by default",
+			format:              services.CatalogFormatJSON,
+			expectService:       true,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"packs":[{"size":250,"price":2.5,"weight":0,"handling_cost":0}]}` + "\n",
+		},
+		{
+			name:                "Unknown format",
+			query:               "?format=xlsx",
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/problem+json",
+			expectedBody:        `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid format parameter","instance":"/packs/export","errors":[{"field":"format","message":"must be one of: csv json"}]}`,
+		},
+		{
+			name:                "Service error",
+			query:               "?format=csv",
+			format:              services.CatalogFormatCSV,
+			mockError:           errors.New("some error"),
+			expectService:       true,
+			expectedStatus:      http.StatusInternalServerError,
+			expectedContentType: "application/problem+json",
+			expectedBody:        `{"type":"/problems/internal-error","title":"Internal server error","status":500,"detail":"Could not export pack sizes","instance":"/packs/export"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockPacksCalculator(ctrl)
+
+			if tt.expectService {
+				mockService.EXPECT().ExportPackSizes(gomock.Any(), tt.format).DoAndReturn(func(w io.Writer, format string) error {
+					if tt.mockError != nil {
+						return tt.mockError
+					}
+					_, err := io.WriteString(w, tt.expectedBody)
+					return err
+				}).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewHandler(mockService)
+			router.GET("/packs/export", h.ExportPackSizes)
+
+			req, _ := http.NewRequest("GET", "/packs/export"+tt.query, nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Equal(t, tt.expectedContentType, resp.Header().Get("Content-Type"))
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, tt.expectedBody, resp.Body.String())
+				assert.Equal(t, `attachment; filename="packs.`+tt.format+`"`, resp.Header().Get("Content-Disposition"))
+			} else {
+				assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestImportPackSizes(t *testing.T) {
+	report := &models.ImportReport{
+		Mode: "replace", DryRun: true, Rows: 2, Added: []uint32{750}, Updated: []uint32{},
+		Unchanged: []uint32{250}, Removed: []uint32{500},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		contentType    string
+		opts           services.ImportOptions
+		mockError      error
+		expectService  bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Dry run of a CSV replacement",
+			query:          "?mode=replace&dry_run=true",
+			contentType:    "text/csv",
+			opts:           services.ImportOptions{Format: "csv", Mode: "replace", DryRun: true, Actor: "alice"},
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"mode":"replace","dry_run":true,"rows":2,"added":[750],"updated":[],"unchanged":[250],"removed":[500]}`,
+		},
+		{
+			name:           "Format parameter wins over the content type",
+			query:          "?format=json",
+			contentType:    "text/plain",
+			opts:           services.ImportOptions{Format: "json", Mode: "merge", Actor: "alice"},
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"mode":"replace","dry_run":true,"rows":2,"added":[750],"updated":[],"unchanged":[250],"removed":[500]}`,
+		},
+		{
+			name:           "Invalid lines",
+			contentType:    "text/csv",
+			opts:           services.ImportOptions{Format: "csv", Mode: "merge", Actor: "alice"},
+			mockError:      &services.CatalogImportError{Lines: []models.ImportLineError{{Line: 3, Field: "size", Message: "must be a positive whole number"}}},
+			expectService:  true,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"type":"/problems/invalid-catalog","title":"Invalid catalog","status":422,"detail":"catalog rejected: 1 invalid lines","instance":"/packs/import","lines":[{"line":3,"field":"size","message":"must be a positive whole number"}]}`,
+		},
+		{
+			name:           "Unreadable file",
+			contentType:    "application/json",
+			opts:           services.ImportOptions{Format: "json", Mode: "merge", Actor: "alice"},
+			mockError:      errors.Join(models.ErrInvalidCatalog, errors.New("unexpected EOF")),
+			expectService:  true,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-catalog-file","title":"Invalid catalog file","status":400,"detail":"invalid catalog file\nunexpected EOF","instance":"/packs/import"}`,
+		},
+		{
+			name:           "Unknown format",
+			contentType:    "text/plain",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid format parameter","instance":"/packs/import","errors":[{"field":"format","message":"must be one of: csv json"}]}`,
+		},
+		{
+			name:           "Unknown mode",
+			query:          "?mode=append",
+			contentType:    "text/csv",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid mode parameter","instance":"/packs/import","errors":[{"field":"mode","message":"must be one of: merge replace"}]}`,
+		},
+		{
+			name:           "Invalid dry run",
+			query:          "?dry_run=maybe",
+			contentType:    "text/csv",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid dry_run parameter","instance":"/packs/import","errors":[{"field":"dry_run","message":"must be true or false"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockPacksCalculator(ctrl)
+
+			if tt.expectService {
+				mockService.EXPECT().ImportPackSizes(gomock.Any(), tt.opts).
+					DoAndReturn(func(r io.Reader, opts services.ImportOptions) (*models.ImportReport, error) {
+						file, err := io.ReadAll(r)
+						assert.NoError(t, err)
+						assert.Equal(t, "size\n250\n750\n", string(file))
+						if tt.mockError != nil {
+							return nil, tt.mockError
+						}
+						return report, nil
+					}).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewHandler(mockService)
+			router.POST("/packs/import", h.ImportPackSizes)
+
+			req, _ := http.NewRequest("POST", "/packs/import"+tt.query, bytes.NewBufferString("size\n250\n750\n"))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.Header.Set("X-Actor", "alice")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
+func TestImportPackSizesUpload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockPacksCalculator(ctrl)
+	mockService.EXPECT().ImportPackSizes(gomock.Any(), services.ImportOptions{Format: "csv", Mode: "merge", Actor: "anonymous"}).
+		DoAndReturn(func(r io.Reader, opts services.ImportOptions) (*models.ImportReport, error) {
+			file, err := io.ReadAll(r)
+			assert.NoError(t, err)
+			assert.Equal(t, "size\n250\n", string(file))
+			return &models.ImportReport{Mode: "merge"}, nil
+		}).Times(1)
+
+	router := gin.Default()
+	h := handlers.NewHandler(mockService)
+	router.POST("/packs/import", h.ImportPackSizes)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "catalog.CSV")
+	_, _ = part.Write([]byte("size\n250\n"))
+	_ = form.Close()
+
+	req, _ := http.NewRequest("POST", "/packs/import", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"mode":"merge","dry_run":false,"rows":0,"added":null,"updated":null,"unchanged":null,"removed":null}`, resp.Body.String())
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/models"
+	"net/http"
+)
+
+// domainError describes how a domain error is reported to clients.
+type domainError struct {
+	err    error
+	status int
+	slug   string
+	title  string
+}
+
+// domainErrors maps the domain errors to their HTTP status and problem type.
+var domainErrors = []domainError{
+	{models.ErrPackSizeExists, http.StatusConflict, "pack-size-exists", "Pack size already exists"},
+	{models.ErrPackSizeNotFound, http.StatusNotFound, "pack-size-not-found", "Pack size not found"},
+	{models.ErrNoPackSizes, http.StatusUnprocessableEntity, "no-pack-sizes", "No pack sizes configured"},
+	{models.ErrInvalidSize, http.StatusBadRequest, "invalid-size", "Invalid pack size"},
+	{models.ErrDuplicateSize, http.StatusBadRequest, "duplicate-size", "Duplicate pack size"},
+	{models.ErrInvalidCatalog, http.StatusBadRequest, "invalid-catalog-file", "Invalid catalog file"},
+	{models.ErrCatalogVersionNotFound, http.StatusNotFound, "catalog-version-not-found", "Catalog version not found"},
+	{models.ErrCalculationNotFound, http.StatusNotFound, "calculation-not-found", "Calculation not found"},
+	{models.ErrInvalidLine, http.StatusBadRequest, "invalid-line", "Invalid line"},
+	{models.ErrOrderNotFound, http.StatusNotFound, "order-not-found", "Order not found"},
+	{models.ErrInvalidOrderTransition, http.StatusConflict, "invalid-order-transition", "Invalid order transition"},
+	{models.ErrNotEnoughStock, http.StatusConflict, "not-enough-stock", "Not enough stock to ship"},
+	{models.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, "idempotency-key-reused", "Idempotency key reused"},
+	{models.ErrIdempotencyKeyInProgress, http.StatusConflict, "idempotency-key-in-progress", "Request in progress"},
+	{models.ErrStockNotTracked, http.StatusNotFound, "stock-not-tracked", "Stock not tracked for pack size"},
+	{models.ErrInvalidStockAdjustment, http.StatusConflict, "invalid-stock-adjustment", "Stock adjustment not possible"},
+	{services.ErrUnknownStrategy, http.StatusBadRequest, "unknown-strategy", "Unknown strategy"},
+	{services.ErrInvalidFillMode, http.StatusBadRequest, "invalid-fill-mode", "Invalid fill mode"},
+	{services.ErrNoExactFit, http.StatusUnprocessableEntity, "no-exact-fit", "No exact fit"},
+	{services.ErrOverfillExceeded, http.StatusUnprocessableEntity, "overfill-tolerance-exceeded", "Overfill tolerance exceeded"},
+	{services.ErrAlternativesUnsupported, http.StatusBadRequest, "alternatives-unsupported", "Alternatives not supported"},
+	{services.ErrQuantityTooLarge, http.StatusUnprocessableEntity, "quantity-too-large", "Order quantity too large"},
+	{services.ErrInvalidAnalysisRange, http.StatusBadRequest, "invalid-analysis-range", "Invalid analysis range"},
+	{services.ErrAnalysisTooLarge, http.StatusUnprocessableEntity, "analysis-too-large", "Pack sizes too large to analyse"},
+	{services.ErrInvalidRecommendation, http.StatusBadRequest, "invalid-recommendation", "Invalid recommendation constraints"},
+	{services.ErrNoOrderQuantities, http.StatusUnprocessableEntity, "no-order-quantities", "No order quantities"},
+	{services.ErrInvalidCatalogChange, http.StatusBadRequest, "invalid-catalog-change", "Invalid catalog change"},
+	{services.ErrInvalidImpactRange, http.StatusBadRequest, "invalid-impact-range", "Invalid impact range"},
+}
+
+// respondError reports err as a problem of its domain error type. Unexpected errors are
+// reported as 500 with the fallback detail so internal details do not leak.
+func respondError(c *gin.Context, err error, fallback string) {
+	respondProblem(c, problemFor(err, fallback))
+}
+
+// problemFor describes err as a problem of its domain error type, or as an internal error with the
+// fallback detail.
+func problemFor(err error, fallback string) problem {
+	var stockErr *services.InsufficientStockError
+	if errors.As(err, &stockErr) {
+		return problem{
+			Type:   "insufficient-stock",
+			Title:  "Insufficient stock",
+			Status: http.StatusConflict,
+			Detail: stockErr.Error(),
+			Extensions: map[string]any{
+				"quantity":  stockErr.Quantity,
+				"available": stockErr.Available,
+				"shortfall": stockErr.Shortfall(),
+				"stock":     stockErr.Stock,
+			},
+		}
+	}
+
+	var importErr *services.CatalogImportError
+	if errors.As(err, &importErr) {
+		return problem{
+			Type:       "invalid-catalog",
+			Title:      "Invalid catalog",
+			Status:     http.StatusUnprocessableEntity,
+			Detail:     importErr.Error(),
+			Extensions: map[string]any{"lines": importErr.Lines},
+		}
+	}
+
+	for _, known := range domainErrors {
+		if errors.Is(err, known.err) {
+			return problem{
+				Type:   known.slug,
+				Title:  known.title,
+				Status: known.status,
+				Detail: err.Error(),
+			}
+		}
+	}
+
+	return problem{
+		Type:   "internal-error",
+		Title:  "Internal server error",
+		Status: http.StatusInternalServerError,
+		Detail: fallback,
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/models"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// idempotencyKeyHeader names the key that makes retries of a request take effect only once.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength caps the length of an idempotency key.
+const maxIdempotencyKeyLength = 255
+
+// Idempotency returns middleware that handles requests carrying an Idempotency-Key header only once.
+// Retries with the same key and request replay the stored response; reusing a key for a different
+// request is rejected. Server errors are not stored, so the request can be retried with the same key.
+func Idempotency(guard services.IdempotencyGuard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(idempotencyKeyHeader))
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			respondInvalidParam(c, idempotencyKeyHeader, "must be at most 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			respondProblem(c, problem{
+				Type:   "invalid-request",
+				Title:  "Invalid request",
+				Status: http.StatusBadRequest,
+				Detail: "Invalid request body: " + err.Error(),
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		stored, err := guard.Begin(key, requestHash(c.Request.Method, c.Request.URL.RequestURI(), body))
+		if err != nil {
+			respondError(c, err, "Could not check idempotency key")
+			return
+		}
+		if stored != nil {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(stored.Status, stored.ContentType, stored.Body)
+			c.Abort()
+			return
+		}
+
+		// A handler that panics never completes the key, so it is released before the panic carries on
+		// to the recovery middleware rather than left in progress.
+		defer func() {
+			if r := recover(); r != nil {
+				abandon(guard, key)
+				panic(r)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			abandon(guard, key)
+			return
+		}
+
+		response := models.StoredResponse{
+			Status:      recorder.Status(),
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}
+		if err := guard.Complete(key, response); err != nil {
+			log.Printf("failed to store response for idempotency key %q: %v", key, err)
+		}
+	}
+}
+
+// abandon releases key after its request failed, logging when the key could not be released.
+func abandon(guard services.IdempotencyGuard, key string) {
+	if err := guard.Abandon(key); err != nil {
+		log.Printf("failed to release idempotency key %q: %v", key, err)
+	}
+}
+
+// requestHash fingerprints a request, so that a reused idempotency key can be told apart from a retry.
+// The query is part of it, as it can change what a request does, such as a dry run.
+func requestHash(method, uri string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + uri + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body written through it.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,198 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/handlers"
+	"github.com/klemis/packs-calculator/internal/services/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotency(t *testing.T) {
+	tests := []struct {
+		name           string
+		key            string
+		handlerStatus  int
+		mockSetup      func(guard *mocks.MockIdempotencyGuard)
+		expectHandled  bool
+		expectedStatus int
+		expectedBody   string
+		expectReplayed bool
+	}{
+		{
+			name:           "Without a key",
+			handlerStatus:  http.StatusOK,
+			mockSetup:      func(guard *mocks.MockIdempotencyGuard) {},
+			expectHandled:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"message":"handled"}`,
+		},
+		{
+			name:          "First request is stored",
+			key:           "order-7",
+			handlerStatus: http.StatusOK,
+			mockSetup: func(guard *mocks.MockIdempotencyGuard) {
+				guard.EXPECT().Begin("order-7", gomock.Any()).Return(nil, nil).Times(1)
+				guard.EXPECT().Complete("order-7", models.StoredResponse{
+					Status:      http.StatusOK,
+					ContentType: "application/json; charset=utf-8",
+					Body:        []byte(`{"message":"handled"}`),
+				}).Return(nil).Times(1)
+			},
+			expectHandled:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"message":"handled"}`,
+		},
+		{
+			name: "Retry is replayed",
+			key:  "order-7",
+			mockSetup: func(guard *mocks.MockIdempotencyGuard) {
+				guard.EXPECT().Begin("order-7", gomock.Any()).Return(&models.StoredResponse{
+					Status:      http.StatusOK,
+					ContentType: "application/json; charset=utf-8",
+					Body:        []byte(`{"message":"Pack size successfully added"}`),
+				}, nil).Times(1)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"message":"Pack size successfully added"}`,
+			expectReplayed: true,
+		},
+		{
+			name:          "Server error releases the key",
+			key:           "order-7",
+			handlerStatus: http.StatusInternalServerError,
+			mockSetup: func(guard *mocks.MockIdempotencyGuard) {
+				guard.EXPECT().Begin("order-7", gomock.Any()).Return(nil, nil).Times(1)
+				guard.EXPECT().Abandon("order-7").Return(nil).Times(1)
+			},
+			expectHandled:  true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"handled"}`,
+		},
+		{
+			name: "Key reused for a different request",
+			key:  "order-7",
+			mockSetup: func(guard *mocks.MockIdempotencyGuard) {
+				guard.EXPECT().Begin("order-7", gomock.Any()).Return(nil, models.ErrIdempotencyKeyReused).Times(1)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"type":"/problems/idempotency-key-reused","title":"Idempotency key reused","status":422,"detail":"idempotency key already used with a different request","instance":"/packs"}`,
+		},
+		{
+			name: "First request still in progress",
+			key:  "order-7",
+			mockSetup: func(guard *mocks.MockIdempotencyGuard) {
+				guard.EXPECT().Begin("order-7", gomock.Any()).Return(nil, models.ErrIdempotencyKeyInProgress).Times(1)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"type":"/problems/idempotency-key-in-progress","title":"Request in progress","status":409,"detail":"a request with this idempotency key is still in progress","instance":"/packs"}`,
+		},
+		{
+			name: "Guard error",
+			key:  "order-7",
+			mockSetup: func(guard *mocks.MockIdempotencyGuard) {
+				guard.EXPECT().Begin("order-7", gomock.Any()).Return(nil, errors.New("some error")).Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"type":"/problems/internal-error","title":"Internal server error","status":500,"detail":"Could not check idempotency key","instance":"/packs"}`,
+		},
+		{
+			name:           "Key too long",
+			key:            strings.Repeat("k", 256),
+			mockSetup:      func(guard *mocks.MockIdempotencyGuard) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid Idempotency-Key parameter","instance":"/packs","errors":[{"field":"Idempotency-Key","message":"must be at most 255 characters"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockGuard := mocks.NewMockIdempotencyGuard(ctrl)
+			tt.mockSetup(mockGuard)
+
+			handled := false
+			router := gin.Default()
+			router.POST("/packs", handlers.Idempotency(mockGuard), func(c *gin.Context) {
+				handled = true
+				c.JSON(tt.handlerStatus, gin.H{"message": "handled"})
+			})
+
+			req, _ := http.NewRequest("POST", "/packs", bytes.NewBufferString(`{"size": 250}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.key != "" {
+				req.Header.Set("Idempotency-Key", tt.key)
+			}
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectHandled, handled)
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+			if tt.expectReplayed {
+				assert.Equal(t, "true", resp.Header().Get("Idempotent-Replayed"))
+			}
+		})
+	}
+}
+
+func TestIdempotencyRequestHash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockGuard := mocks.NewMockIdempotencyGuard(ctrl)
+
+	var hashes []string
+	mockGuard.EXPECT().Begin("order-7", gomock.Any()).DoAndReturn(func(key, requestHash string) (*models.StoredResponse, error) {
+		hashes = append(hashes, requestHash)
+		return nil, models.ErrIdempotencyKeyInProgress
+	}).Times(4)
+
+	router := gin.Default()
+	router.POST("/packs", handlers.Idempotency(mockGuard), func(c *gin.Context) {})
+	router.PUT("/packs", handlers.Idempotency(mockGuard), func(c *gin.Context) {})
+
+	for _, r := range []struct{ method, target, body string }{
+		{"POST", "/packs", `{"size": 250}`},
+		{"POST", "/packs", `{"size": 500}`},
+		{"PUT", "/packs", `{"size": 250}`},
+		{"POST", "/packs?dry_run=true", `{"size": 250}`},
+	} {
+		req, _ := http.NewRequest(r.method, r.target, bytes.NewBufferString(r.body))
+		req.Header.Set("Idempotency-Key", "order-7")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// The same key with a different body, method or query is a different request.
+	assert.Len(t, hashes, 4)
+	assert.NotEqual(t, hashes[0], hashes[1])
+	assert.NotEqual(t, hashes[0], hashes[2])
+	assert.NotEqual(t, hashes[0], hashes[3])
+}
+
+func TestIdempotencyReleasesKeyOnPanic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockGuard := mocks.NewMockIdempotencyGuard(ctrl)
+	mockGuard.EXPECT().Begin("order-7", gomock.Any()).Return(nil, nil).Times(1)
+	mockGuard.EXPECT().Abandon("order-7").Return(nil).Times(1)
+
+	router := gin.Default()
+	router.POST("/packs", handlers.Idempotency(mockGuard), func(c *gin.Context) {
+		panic("handler failed")
+	})
+
+	req, _ := http.NewRequest("POST", "/packs", bytes.NewBufferString(`{"size": 250}`))
+	req.Header.Set("Idempotency-Key", "order-7")
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
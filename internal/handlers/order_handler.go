@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/models"
+	"net/http"
+)
+
+type OrderHandler struct {
+	service services.OrderManager
+}
+
+// NewOrderHandler creates a new OrderHandler with the provided OrderService.
+func NewOrderHandler(orderService services.OrderManager) *OrderHandler {
+	return &OrderHandler{
+		service: orderService,
+	}
+}
+
+// CreateOrder handles quoting a new order: the packs for its quantity are calculated and allocated to it.
+func (h *OrderHandler) CreateOrder(c *gin.Context) {
+	var req *models.OrderRequest
+	if err := c.BindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	opts := services.CalculateOptions{
+		Strategy:     req.Strategy,
+		ShippingRate: req.ShippingRate,
+		Mode:         services.FillMode{Name: req.Mode, MaxOverfill: req.MaxOverfill, MaxOverfillPercent: req.MaxOverfillPercent},
+		Caller:       caller(c),
+	}
+	order, err := h.service.CreateOrder(req.Quantity, opts)
+	if err != nil {
+		respondError(c, err, "Could not create order")
+		return
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
+
+// ListOrders handles listing the orders, optionally only those in one status.
+func (h *OrderHandler) ListOrders(c *gin.Context) {
+	status := models.OrderStatus(c.Query("status"))
+	switch status {
+	case "", models.OrderQuoted, models.OrderConfirmed, models.OrderPacked, models.OrderShipped, models.OrderCancelled:
+	default:
+		respondInvalidParam(c, "status", "must be one of: quoted confirmed packed shipped cancelled")
+		return
+	}
+
+	orders, err := h.service.ListOrders(status)
+	if err != nil {
+		respondError(c, err, "Could not list orders")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"orders": orders})
+}
+
+// GetOrder handles reading a single order by ID.
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	id, ok := recordIDParam(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.service.GetOrder(id)
+	if err != nil {
+		respondError(c, err, "Could not get order")
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// ConfirmOrder handles the customer accepting a quoted order.
+func (h *OrderHandler) ConfirmOrder(c *gin.Context) {
+	h.transitionOrder(c, models.OrderConfirmed)
+}
+
+// PackOrder handles the warehouse packing a confirmed order.
+func (h *OrderHandler) PackOrder(c *gin.Context) {
+	h.transitionOrder(c, models.OrderPacked)
+}
+
+// ShipOrder handles the warehouse shipping a packed order.
+func (h *OrderHandler) ShipOrder(c *gin.Context) {
+	h.transitionOrder(c, models.OrderShipped)
+}
+
+// CancelOrder handles cancelling an order that has not shipped yet.
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	h.transitionOrder(c, models.OrderCancelled)
+}
+
+// transitionOrder moves the order named by the :id path parameter to status and responds with the order.
+func (h *OrderHandler) transitionOrder(c *gin.Context, status models.OrderStatus) {
+	id, ok := recordIDParam(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.service.TransitionOrder(id, status)
+	if err != nil {
+		respondError(c, err, "Could not update order")
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
@@ -0,0 +1,237 @@
+package handlers_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/handlers"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/internal/services/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateOrder(t *testing.T) {
+	order := &models.Order{
+		ID: 3, Status: models.OrderQuoted, Quantity: 501, CalculationID: 42, CatalogVersion: 5, Strategy: "optimal",
+		Packs: map[uint32]uint64{500: 1, 250: 1}, TotalCost: 6.5,
+	}
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockError      error
+		expectService  bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Valid request",
+			requestBody:    `{"quantity": 501, "strategy": "optimal", "shipping_rate": 0.5}`,
+			expectService:  true,
+			expectedStatus: http.StatusCreated,
+			expectedBody: `{"id":3,"status":"quoted","quantity":501,"calculation_id":42,"catalog_version":5,` +
+				`"strategy":"optimal","packs":{"250":1,"500":1},"total_cost":6.5,` +
+				`"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:           "Missing quantity",
+			requestBody:    `{"strategy": "optimal"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/validation-failed","title":"Validation failed","status":400,"detail":"One or more fields are invalid","instance":"/orders","errors":[{"field":"quantity","message":"is required"}]}`,
+		},
+		{
+			name:           "No pack sizes",
+			requestBody:    `{"quantity": 501, "strategy": "optimal", "shipping_rate": 0.5}`,
+			mockError:      models.ErrNoPackSizes,
+			expectService:  true,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"type":"/problems/no-pack-sizes","title":"No pack sizes configured","status":422,"detail":"no pack sizes configured","instance":"/orders"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockOrderManager(ctrl)
+
+			if tt.expectService {
+				opts := services.CalculateOptions{Strategy: "optimal", ShippingRate: 0.5, Caller: "warehouse"}
+				if tt.mockError != nil {
+					mockService.EXPECT().CreateOrder(uint64(501), opts).Return(nil, tt.mockError).Times(1)
+				} else {
+					mockService.EXPECT().CreateOrder(uint64(501), opts).Return(order, nil).Times(1)
+				}
+			}
+
+			router := gin.Default()
+			h := handlers.NewOrderHandler(mockService)
+			router.POST("/orders", h.CreateOrder)
+
+			req, _ := http.NewRequest("POST", "/orders", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Actor", "warehouse")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
+func TestListOrders(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectService  bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Orders in status",
+			query:          "?status=packed",
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"orders":[]}`,
+		},
+		{
+			name:           "Unknown status",
+			query:          "?status=lost",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid status parameter","instance":"/orders","errors":[{"field":"status","message":"must be one of: quoted confirmed packed shipped cancelled"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockOrderManager(ctrl)
+
+			if tt.expectService {
+				mockService.EXPECT().ListOrders(models.OrderPacked).Return([]models.Order{}, nil).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewOrderHandler(mockService)
+			router.GET("/orders", h.ListOrders)
+
+			req, _ := http.NewRequest("GET", "/orders"+tt.query, nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
+func TestTransitionOrder(t *testing.T) {
+	transitionErr := fmt.Errorf("%w: cannot move a shipped order to cancelled", models.ErrInvalidOrderTransition)
+
+	tests := []struct {
+		name           string
+		path           string
+		status         models.OrderStatus
+		mockError      error
+		expectService  bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Confirm order",
+			path:           "/orders/3/confirm",
+			status:         models.OrderConfirmed,
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":3,"status":"confirmed","quantity":0,"calculation_id":0,"catalog_version":0,"strategy":"","packs":null,"total_cost":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:           "Pack order",
+			path:           "/orders/3/pack",
+			status:         models.OrderPacked,
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":3,"status":"packed","quantity":0,"calculation_id":0,"catalog_version":0,"strategy":"","packs":null,"total_cost":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:           "Ship order",
+			path:           "/orders/3/ship",
+			status:         models.OrderShipped,
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":3,"status":"shipped","quantity":0,"calculation_id":0,"catalog_version":0,"strategy":"","packs":null,"total_cost":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:           "Cancel shipped order",
+			path:           "/orders/3/cancel",
+			status:         models.OrderCancelled,
+			mockError:      transitionErr,
+			expectService:  true,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"type":"/problems/invalid-order-transition","title":"Invalid order transition","status":409,"detail":"invalid order transition: cannot move a shipped order to cancelled","instance":"/orders/3/cancel"}`,
+		},
+		{
+			name:           "Not enough stock to ship",
+			path:           "/orders/3/ship",
+			status:         models.OrderShipped,
+			mockError:      fmt.Errorf("%w: 2 packs of 500 needed, 1 on hand", models.ErrNotEnoughStock),
+			expectService:  true,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"type":"/problems/not-enough-stock","title":"Not enough stock to ship","status":409,"detail":"not enough stock to ship order: 2 packs of 500 needed, 1 on hand","instance":"/orders/3/ship"}`,
+		},
+		{
+			name:           "Order not found",
+			path:           "/orders/3/confirm",
+			status:         models.OrderConfirmed,
+			mockError:      models.ErrOrderNotFound,
+			expectService:  true,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"type":"/problems/order-not-found","title":"Order not found","status":404,"detail":"order not found","instance":"/orders/3/confirm"}`,
+		},
+		{
+			name:           "Invalid id",
+			path:           "/orders/0/confirm",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid id parameter","instance":"/orders/0/confirm","errors":[{"field":"id","message":"must be a positive whole number"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockOrderManager(ctrl)
+
+			if tt.expectService {
+				if tt.mockError != nil {
+					mockService.EXPECT().TransitionOrder(uint64(3), tt.status).Return(nil, tt.mockError).Times(1)
+				} else {
+					mockService.EXPECT().TransitionOrder(uint64(3), tt.status).
+						Return(&models.Order{ID: 3, Status: tt.status}, nil).Times(1)
+				}
+			}
+
+			router := gin.Default()
+			h := handlers.NewOrderHandler(mockService)
+			router.POST("/orders/:id/confirm", h.ConfirmOrder)
+			router.POST("/orders/:id/pack", h.PackOrder)
+			router.POST("/orders/:id/ship", h.ShipOrder)
+			router.POST("/orders/:id/cancel", h.CancelOrder)
+
+			req, _ := http.NewRequest("POST", tt.path, nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/klemis/packs-calculator/internal/services"
+	"net/http"
+	"strconv"
+)
+
+// AnalysePackSizes handles describing how the configured pack sizes cover order quantities. The
+// unreachable_below, overfill_from and overfill_to query parameters bound the quantities looked at.
+func (h *Handler) AnalysePackSizes(c *gin.Context) {
+	opts := services.AnalysisOptions{
+		UnreachableBelow: services.DefaultUnreachableBelow,
+		OverfillFrom:     1,
+		OverfillTo:       services.DefaultOverfillTo,
+	}
+	params := []struct {
+		name  string
+		value *uint64
+	}{
+		{"unreachable_below", &opts.UnreachableBelow},
+		{"overfill_from", &opts.OverfillFrom},
+		{"overfill_to", &opts.OverfillTo},
+	}
+	for _, param := range params {
+		if value := c.Query(param.name); value != "" {
+			var err error
+			if *param.value, err = strconv.ParseUint(value, 10, 64); err != nil {
+				respondInvalidParam(c, param.name, "must be a whole number of at least 0")
+				return
+			}
+		}
+	}
+
+	analysis, err := h.service.AnalysePackSizes(opts)
+	if err != nil {
+		respondError(c, err, "Could not analyse pack sizes")
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
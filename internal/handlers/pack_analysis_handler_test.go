@@ -0,0 +1,90 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/handlers"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/internal/services/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalysePackSizes(t *testing.T) {
+	analysis := &models.PackSetAnalysis{
+		PackSizes:        []uint32{500, 250},
+		GCD:              250,
+		UnreachableBelow: 3,
+		Unreachable:      []uint64{1, 2},
+		Overfill:         models.OverfillAnalysis{From: 1, To: 500, Worst: 249, WorstQuantity: 1, Average: 124.5},
+		Redundant:        []models.RedundantSize{{Size: 500, Packs: map[uint32]uint64{250: 2}}},
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		opts           services.AnalysisOptions
+		mockResponse   *models.PackSetAnalysis
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Analysis",
+			query:          "?unreachable_below=3&overfill_to=500",
+			opts:           services.AnalysisOptions{UnreachableBelow: 3, OverfillFrom: 1, OverfillTo: 500},
+			mockResponse:   analysis,
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"pack_sizes":[500,250],"gcd":250,"frobenius_number":null,"unreachable_below":3,"unreachable":[1,2],` +
+				`"overfill":{"from":1,"to":500,"worst":249,"worst_quantity":1,"average":124.5},"redundant":[{"size":500,"packs":{"250":2}}]}`,
+		},
+		{
+			name:           "Default ranges",
+			opts:           services.AnalysisOptions{UnreachableBelow: services.DefaultUnreachableBelow, OverfillFrom: 1, OverfillTo: services.DefaultOverfillTo},
+			mockError:      models.ErrNoPackSizes,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"type":"/problems/no-pack-sizes","title":"No pack sizes configured","status":422,"detail":"no pack sizes configured","instance":"/packs/analysis"}`,
+		},
+		{
+			name:           "Range out of order",
+			query:          "?overfill_from=20&overfill_to=10",
+			opts:           services.AnalysisOptions{UnreachableBelow: services.DefaultUnreachableBelow, OverfillFrom: 20, OverfillTo: 10},
+			mockError:      fmt.Errorf("%w: the overfill range ends before it starts", services.ErrInvalidAnalysisRange),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-analysis-range","title":"Invalid analysis range","status":400,"detail":"invalid analysis range: the overfill range ends before it starts","instance":"/packs/analysis"}`,
+		},
+		{
+			name:           "Invalid parameter",
+			query:          "?overfill_from=-1",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid overfill_from parameter","instance":"/packs/analysis","errors":[{"field":"overfill_from","message":"must be a whole number of at least 0"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockPacksCalculator(ctrl)
+			if tt.mockResponse != nil || tt.mockError != nil {
+				mockService.EXPECT().AnalysePackSizes(tt.opts).Return(tt.mockResponse, tt.mockError).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewHandler(mockService)
+			router.GET("/packs/analysis", h.AnalysePackSizes)
+
+			req, _ := http.NewRequest("GET", "/packs/analysis"+tt.query, nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Equal(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
@@ -20,60 +20,399 @@ func NewHandler(packsCalculatorService services.PacksCalculator) *Handler {
 }
 
 // AddPackSize handles adding a new pack size.
+// With dry_run set, the impact of the change is reported instead of making it.
 func (h *Handler) AddPackSize(c *gin.Context) {
+	dryRun, ok := dryRunParam(c)
+	if !ok {
+		return
+	}
+
 	var req *models.PackSizeRequest
 	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
-	if err := h.service.AddPackSize(req.Size); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not add pack size"})
+	pack := models.PackSize{
+		Size:         req.Size,
+		Price:        req.Price,
+		Weight:       req.Weight,
+		HandlingCost: req.HandlingCost,
+	}
+	if dryRun {
+		h.respondImpact(c, services.CatalogProposal{Kind: services.ChangeAdd, Packs: []models.PackSize{pack}})
+		return
+	}
+	if err := h.service.AddPackSize(pack, actor(c)); err != nil {
+		respondError(c, err, "Could not add pack size")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Pack size successfully added"})
 }
 
+// ReplacePackSizes handles replacing the whole pack catalog with a new set of pack sizes.
+// With dry_run set, the impact of the change is reported instead of making it.
+func (h *Handler) ReplacePackSizes(c *gin.Context) {
+	dryRun, ok := dryRunParam(c)
+	if !ok {
+		return
+	}
+
+	var req *models.PackSizesReplaceRequest
+	if err := c.BindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if dryRun {
+		h.respondImpact(c, services.CatalogProposal{Kind: services.ChangeReplace, Packs: packSizes(req.Packs)})
+		return
+	}
+
+	change, err := h.service.ReplacePackSizes(packSizes(req.Packs), actor(c))
+	if err != nil {
+		respondError(c, err, "Could not replace pack sizes")
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// packSizes converts requested pack sizes into pack sizes.
+func packSizes(reqs []models.PackSizeRequest) []models.PackSize {
+	packs := make([]models.PackSize, 0, len(reqs))
+	for _, pack := range reqs {
+		packs = append(packs, models.PackSize{
+			Size:         pack.Size,
+			Price:        pack.Price,
+			Weight:       pack.Weight,
+			HandlingCost: pack.HandlingCost,
+		})
+	}
+
+	return packs
+}
+
 // DeletePackSize handles deleting an existing pack size.
+// With dry_run set, the impact of the change is reported instead of making it.
 func (h *Handler) DeletePackSize(c *gin.Context) {
+	dryRun, ok := dryRunParam(c)
+	if !ok {
+		return
+	}
+
 	var req *models.PackSizeRequest
 	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		respondBindError(c, err)
+		return
+	}
+
+	if dryRun {
+		h.respondImpact(c, services.CatalogProposal{Kind: services.ChangeRemove, Size: req.Size})
 		return
 	}
 
-	err := h.service.DeletePackSize(req.Size)
+	err := h.service.DeletePackSize(req.Size, actor(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete pack size"})
+		respondError(c, err, "Could not delete pack size")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Pack size successfully deleted"})
 }
 
-// CalculatePacks handles calculating the minimum packs needed for a given item quantity.
+// ListPackSizes handles listing every configured pack size.
+func (h *Handler) ListPackSizes(c *gin.Context) {
+	packSizes, err := h.service.ListPackSizes()
+	if err != nil {
+		respondError(c, err, "Could not list pack sizes")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"packs": packSizes})
+}
+
+// GetPackSize handles reading a single pack size by ID.
+func (h *Handler) GetPackSize(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	pack, err := h.service.GetPackSize(id)
+	if err != nil {
+		respondError(c, err, "Could not get pack size")
+		return
+	}
+
+	c.JSON(http.StatusOK, pack)
+}
+
+// UpdatePackSize handles changing the size or pricing of an existing pack size in place.
+// With dry_run set, the impact of the change is reported instead of making it.
+func (h *Handler) UpdatePackSize(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	dryRun, ok := dryRunParam(c)
+	if !ok {
+		return
+	}
+
+	var req *models.PackSizeUpdateRequest
+	if err := c.BindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if dryRun {
+		h.respondImpact(c, services.CatalogProposal{Kind: services.ChangeUpdate, ID: id, Update: *req})
+		return
+	}
+
+	pack, err := h.service.UpdatePackSize(id, *req, actor(c))
+	if err != nil {
+		respondError(c, err, "Could not update pack size")
+		return
+	}
+
+	c.JSON(http.StatusOK, pack)
+}
+
+// CalculatePacks handles calculating the minimum packs needed for a given item quantity. The quantity is
+// echoed back as it was sent, as a string; CalculatePacksV2 reports it as a number. With alternatives
+// set, the next best pack combinations are listed too.
 func (h *Handler) CalculatePacks(c *gin.Context) {
-	quantity := c.Query("quantity")
-	if quantity == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No quantity parameter"})
+	q, ok := quantityParam(c)
+	if !ok {
+		return
+	}
+
+	opts, ok := calculateOptions(c)
+	if !ok {
+		return
+	}
+	if opts.Alternatives, ok = alternativesParam(c); !ok {
+		return
+	}
+
+	result, err := h.service.CalculatePacks(q, opts)
+	if err != nil {
+		respondError(c, err, "Could not calculate packs")
+		return
+	}
+
+	response := gin.H{
+		"calculation_id":  result.ID,
+		"quantity":        c.Query("quantity"),
+		"strategy":        result.Strategy,
+		"catalog_version": result.CatalogVersion,
+		"packs":           result.Packs,
+		"cost":            result.Cost,
+	}
+	if result.Alternatives != nil {
+		response["alternatives"] = result.Alternatives
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// whatIfSide is one side of a what-if calculation: the packs it needs, or the problem that kept the
+// order from being calculated.
+type whatIfSide struct {
+	CatalogVersion uint32                `json:"catalog_version,omitempty"`
+	Packs          map[uint32]uint64     `json:"packs,omitempty"`
+	Items          uint64                `json:"items"`
+	Overfill       uint64                `json:"overfill"`
+	Cost           *models.CostBreakdown `json:"cost,omitempty"`
+	Error          *problem              `json:"error,omitempty"`
+}
+
+// whatIfDifference is how the what-if side of a comparison differs from the live side.
+type whatIfDifference struct {
+	Items     models.Delta `json:"items"`
+	Packs     models.Delta `json:"packs"`
+	TotalCost float64      `json:"total_cost"`
+}
+
+// newWhatIfSide describes a calculation, or the error that kept it from being made.
+func newWhatIfSide(calculation *models.Calculation, err error) *whatIfSide {
+	if err != nil {
+		sideProblem := problemFor(err, "Could not calculate packs")
+		sideProblem.Type = problemTypeBase + sideProblem.Type
+		return &whatIfSide{Error: &sideProblem}
+	}
+
+	side := &whatIfSide{CatalogVersion: calculation.CatalogVersion, Packs: calculation.Packs, Cost: &calculation.Cost}
+	for size, count := range calculation.Packs {
+		side.Items += uint64(size) * count
+	}
+	side.Overfill = side.Items - min(side.Items, calculation.Quantity)
+
+	return side
+}
+
+// CalculateWhatIf handles calculating the packs for an order quantity against a caller-supplied set of
+// pack sizes, leaving the catalog and the calculation history untouched. With compare set, the order is
+// also calculated against the live catalog and stock and the two results are shown side by side.
+func (h *Handler) CalculateWhatIf(c *gin.Context) {
+	var req *models.WhatIfCalculationRequest
+	if err := c.BindJSON(&req); err != nil {
+		respondBindError(c, err)
 		return
 	}
 
-	q, err := strconv.ParseUint(quantity, 10, 32)
+	opts := services.CalculateOptions{
+		Strategy:     req.Strategy,
+		ShippingRate: req.ShippingRate,
+		Mode:         services.FillMode{Name: req.Mode, MaxOverfill: req.MaxOverfill, MaxOverfillPercent: req.MaxOverfillPercent},
+		Caller:       caller(c),
+		PackSizes:    packSizes(req.PackSizes),
+	}
+
+	var comparison *services.CalculationComparison
+	var err error
+	if req.Compare {
+		comparison, err = h.service.CompareCalculation(*req.Quantity, opts)
+	} else {
+		comparison = &services.CalculationComparison{}
+		comparison.WhatIf, err = h.service.CalculatePacks(*req.Quantity, opts)
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quantity parameter"})
+		respondError(c, err, "Could not calculate packs")
+		return
+	}
+
+	whatIf := newWhatIfSide(comparison.WhatIf, nil)
+	response := gin.H{"quantity": *req.Quantity, "strategy": comparison.WhatIf.Strategy, "what_if": whatIf}
+	if req.Compare {
+		live := newWhatIfSide(comparison.Live, comparison.LiveErr)
+		response["live"] = live
+		if live.Error == nil {
+			response["difference"] = whatIfDifference{
+				Items:     models.NewDelta(live.Items, whatIf.Items),
+				Packs:     models.NewDelta(services.PackCount(live.Packs), services.PackCount(whatIf.Packs)),
+				TotalCost: whatIf.Cost.Total - live.Cost.Total,
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// calculateOptions parses the strategy, shipping_rate, mode, max_overfill, max_overfill_percent and
+// catalog_version query parameters of a calculation, responding with 400 when one is invalid.
+func calculateOptions(c *gin.Context) (services.CalculateOptions, bool) {
+	opts := services.CalculateOptions{
+		Strategy: c.Query("strategy"),
+		Mode:     services.FillMode{Name: c.Query("mode")},
+		Caller:   caller(c),
+	}
+	if shippingRate := c.Query("shipping_rate"); shippingRate != "" {
+		var err error
+		opts.ShippingRate, err = strconv.ParseFloat(shippingRate, 64)
+		if err != nil || opts.ShippingRate < 0 {
+			respondInvalidParam(c, "shipping_rate", "must be a number of at least 0")
+			return opts, false
+		}
+	}
+
+	if maxOverfill := c.Query("max_overfill"); maxOverfill != "" {
+		items, err := strconv.ParseUint(maxOverfill, 10, 64)
+		if err != nil {
+			respondInvalidParam(c, "max_overfill", "must be a whole number of at least 0")
+			return opts, false
+		}
+		opts.Mode.MaxOverfill = &items
+	}
+
+	if maxOverfillPercent := c.Query("max_overfill_percent"); maxOverfillPercent != "" {
+		percent, err := strconv.ParseFloat(maxOverfillPercent, 64)
+		if err != nil || percent < 0 {
+			respondInvalidParam(c, "max_overfill_percent", "must be a number of at least 0")
+			return opts, false
+		}
+		opts.Mode.MaxOverfillPercent = &percent
+	}
+
+	if catalogVersion := c.Query("catalog_version"); catalogVersion != "" {
+		version, err := strconv.ParseUint(catalogVersion, 10, 32)
+		if err != nil || version == 0 {
+			respondInvalidParam(c, "catalog_version", "must be a positive whole number")
+			return opts, false
+		}
+		opts.CatalogVersion = uint32(version)
+	}
+
+	return opts, true
+}
+
+// batchItemResponse is the result of one item of a batch calculation: its packs, or the problem that
+// kept it from being calculated.
+type batchItemResponse struct {
+	Line          int                   `json:"line,omitempty"`
+	ID            string                `json:"id,omitempty"`
+	Quantity      uint64                `json:"quantity"`
+	CalculationID uint64                `json:"calculation_id,omitempty"`
+	Packs         map[uint32]uint64     `json:"packs,omitempty"`
+	Cost          *models.CostBreakdown `json:"cost,omitempty"`
+	Error         *problem              `json:"error,omitempty"`
+}
+
+// newBatchItemResponse describes the result of one batch item.
+func newBatchItemResponse(result services.BatchResult) batchItemResponse {
+	response := batchItemResponse{Line: result.Item.Line, ID: result.Item.ID, Quantity: result.Item.Quantity}
+	if result.Err != nil {
+		itemProblem := problemFor(result.Err, "Could not calculate packs")
+		itemProblem.Type = problemTypeBase + itemProblem.Type
+		response.Error = &itemProblem
+		return response
+	}
+
+	response.CalculationID = result.Calculation.ID
+	response.Packs = result.Calculation.Packs
+	response.Cost = &result.Calculation.Cost
+
+	return response
+}
+
+// CalculateBatch handles calculating the packs for many order quantities in one request. Items that
+// cannot be calculated report a problem in their result without failing the others.
+func (h *Handler) CalculateBatch(c *gin.Context) {
+	var req *models.BatchCalculationRequest
+	if err := c.BindJSON(&req); err != nil {
+		respondBindError(c, err)
 		return
 	}
 
-	result, err := h.service.CalculatePacks(uint32(q))
+	items := make([]services.BatchItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = services.BatchItem{ID: item.ID, Quantity: *item.Quantity}
+	}
+
+	opts := services.CalculateOptions{
+		Strategy:       req.Strategy,
+		ShippingRate:   req.ShippingRate,
+		Mode:           services.FillMode{Name: req.Mode, MaxOverfill: req.MaxOverfill, MaxOverfillPercent: req.MaxOverfillPercent},
+		CatalogVersion: req.CatalogVersion,
+		Caller:         caller(c),
+	}
+	batch, err := h.service.CalculateBatch(items, opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not calculate packs"})
+		respondError(c, err, "Could not calculate packs")
 		return
 	}
 
+	results := make([]batchItemResponse, len(batch.Results))
+	for i, result := range batch.Results {
+		results[i] = newBatchItemResponse(result)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"quantity": quantity,
-		"packs":    result,
+		"strategy":        batch.Strategy,
+		"catalog_version": batch.CatalogVersion,
+		"results":         results,
 	})
 }
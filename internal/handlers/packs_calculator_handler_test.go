@@ -3,14 +3,18 @@ package handlers_test
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"github.com/golang/mock/gomock"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/klemis/packs-calculator/internal/handlers"
+	"github.com/klemis/packs-calculator/internal/services"
 	"github.com/klemis/packs-calculator/internal/services/mocks"
+	"github.com/klemis/packs-calculator/models"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -24,7 +28,7 @@ func TestAddPackSize(t *testing.T) {
 	}{
 		{
 			name:           "Valid request",
-			payload:        `{"size": 1000}`,
+			payload:        `{"size": 1000, "price": 2.5}`,
 			mockResponse:   nil,
 			expectedStatus: http.StatusOK,
 			expectedBody:   `{"message":"Pack size successfully added"}`,
@@ -34,14 +38,28 @@ func TestAddPackSize(t *testing.T) {
 			payload:        `invalid json`,
 			mockResponse:   nil,
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"Invalid request: invalid character 'i' looking for beginning of value"}`,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid request body: invalid character 'i' looking for beginning of value","instance":"/packs"}`,
+		},
+		{
+			name:           "Negative price",
+			payload:        `{"size": 1000, "price": -1}`,
+			mockResponse:   nil,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/validation-failed","title":"Validation failed","status":400,"detail":"One or more fields are invalid","instance":"/packs","errors":[{"field":"price","message":"must be at least 0"}]}`,
+		},
+		{
+			name:           "Pack size already exists",
+			payload:        `{"size": 1000, "price": 2.5}`,
+			mockResponse:   models.ErrPackSizeExists,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"type":"/problems/pack-size-exists","title":"Pack size already exists","status":409,"detail":"pack size already exists","instance":"/packs"}`,
 		},
 		{
 			name:           "Service error",
-			payload:        `{"size": 1000}`,
+			payload:        `{"size": 1000, "price": 2.5}`,
 			mockResponse:   errors.New("some error"),
 			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   `{"error":"Could not add pack size"}`,
+			expectedBody:   `{"type":"/problems/internal-error","title":"Internal server error","status":500,"detail":"Could not add pack size","instance":"/packs"}`,
 		},
 	}
 
@@ -52,7 +70,7 @@ func TestAddPackSize(t *testing.T) {
 			mockService := mocks.NewMockPacksCalculator(ctrl)
 
 			if tt.expectedStatus != http.StatusBadRequest {
-				mockService.EXPECT().AddPackSize(uint32(1000)).Return(tt.mockResponse).Times(1)
+				mockService.EXPECT().AddPackSize(models.PackSize{Size: 1000, Price: 2.5}, "alice").Return(tt.mockResponse).Times(1)
 			}
 
 			// Create a new gin context
@@ -63,6 +81,7 @@ func TestAddPackSize(t *testing.T) {
 			// Create a request to the handler.
 			req, _ := http.NewRequest("POST", "/packs", bytes.NewBuffer([]byte(tt.payload)))
 			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Actor", "alice")
 			resp := httptest.NewRecorder()
 
 			// Perform the request.
@@ -75,11 +94,69 @@ func TestAddPackSize(t *testing.T) {
 	}
 }
 
+func TestDeletePackSize(t *testing.T) {
+	tests := []struct {
+		name           string
+		payload        string
+		mockResponse   error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Valid request",
+			payload:        `{"size": 1000}`,
+			mockResponse:   nil,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"message":"Pack size successfully deleted"}`,
+		},
+		{
+			name:           "Pack size not found",
+			payload:        `{"size": 1000}`,
+			mockResponse:   models.ErrPackSizeNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"type":"/problems/pack-size-not-found","title":"Pack size not found","status":404,"detail":"pack size not found","instance":"/packs"}`,
+		},
+		{
+			name:           "Service error",
+			payload:        `{"size": 1000}`,
+			mockResponse:   errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"type":"/problems/internal-error","title":"Internal server error","status":500,"detail":"Could not delete pack size","instance":"/packs"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockPacksCalculator(ctrl)
+			mockService.EXPECT().DeletePackSize(uint32(1000), "anonymous").Return(tt.mockResponse).Times(1)
+
+			router := gin.Default()
+			h := handlers.NewHandler(mockService)
+			router.DELETE("/packs", h.DeletePackSize)
+
+			req, _ := http.NewRequest("DELETE", "/packs", bytes.NewBuffer([]byte(tt.payload)))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
 func TestCalculatePacks(t *testing.T) {
 	tests := []struct {
 		name           string
 		queryParam     string
-		mockResponse   map[uint32]uint32
+		strategy       string
+		shippingRate   float64
+		catalogVersion uint32
+		mode           services.FillMode
+		alternatives   int
+		mockResponse   *models.Calculation
 		mockError      error
 		expectedStatus int
 		expectedBody   string
@@ -87,10 +164,163 @@ func TestCalculatePacks(t *testing.T) {
 		{
 			name:           "Valid quantity",
 			queryParam:     "quantity=5000",
-			mockResponse:   map[uint32]uint32{5000: 1},
+			mockResponse:   &models.Calculation{ID: 11, Quantity: 5000, Strategy: "optimal", CatalogVersion: 4, Packs: map[uint32]uint64{5000: 1}},
 			mockError:      nil,
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"quantity":"5000","packs":{"5000":1}}`,
+			expectedBody:   `{"calculation_id":11,"quantity":"5000","strategy":"optimal","catalog_version":4,"packs":{"5000":1},"cost":{"lines":null,"price":0,"handling_cost":0,"weight":0,"shipping_cost":0,"total":0}}`,
+		},
+		{
+			name:           "Pinned catalog version",
+			queryParam:     "quantity=5000&catalog_version=2",
+			catalogVersion: 2,
+			mockResponse:   &models.Calculation{ID: 11, Quantity: 5000, Strategy: "optimal", CatalogVersion: 2, Packs: map[uint32]uint64{5000: 1}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"calculation_id":11,"quantity":"5000","strategy":"optimal","catalog_version":2,"packs":{"5000":1},"cost":{"lines":null,"price":0,"handling_cost":0,"weight":0,"shipping_cost":0,"total":0}}`,
+		},
+		{
+			name:           "Invalid catalog version",
+			queryParam:     "quantity=5000&catalog_version=0",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid catalog_version parameter","instance":"/calculate","errors":[{"field":"catalog_version","message":"must be a positive whole number"}]}`,
+		},
+		{
+			name:           "Catalog version not found",
+			queryParam:     "quantity=5000&catalog_version=9",
+			catalogVersion: 9,
+			mockError:      models.ErrCatalogVersionNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"type":"/problems/catalog-version-not-found","title":"Catalog version not found","status":404,"detail":"catalog version not found","instance":"/calculate"}`,
+		},
+		{
+			name:         "Valid quantity with strategy and shipping rate",
+			queryParam:   "quantity=5000&strategy=min-cost&shipping_rate=0.5",
+			strategy:     "min-cost",
+			shippingRate: 0.5,
+			mockResponse: &models.Calculation{
+				ID:             11,
+				Quantity:       5000,
+				Strategy:       "min-cost",
+				CatalogVersion: 4,
+				Packs:          map[uint32]uint64{5000: 1},
+				Cost: models.CostBreakdown{
+					Lines:        []models.CostLine{{Size: 5000, Count: 1, Price: 10, Weight: 2, ShippingCost: 1, Total: 11}},
+					Price:        10,
+					Weight:       2,
+					ShippingCost: 1,
+					Total:        11,
+				},
+			},
+			mockError:      nil,
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"calculation_id":11,"quantity":"5000","strategy":"min-cost","catalog_version":4,"packs":{"5000":1},"cost":{` +
+				`"lines":[{"size":5000,"count":1,"price":10,"handling_cost":0,"weight":2,"shipping_cost":1,"total":11}],` +
+				`"price":10,"handling_cost":0,"weight":2,"shipping_cost":1,"total":11}}`,
+		},
+		{
+			name:           "Tolerance mode",
+			queryParam:     "quantity=5000&mode=tolerance&max_overfill=100&max_overfill_percent=1.5",
+			mode:           services.FillMode{Name: services.FillTolerance, MaxOverfill: ptr(uint64(100)), MaxOverfillPercent: ptr(1.5)},
+			mockResponse:   &models.Calculation{ID: 11, Quantity: 5000, Strategy: "optimal", CatalogVersion: 4, Packs: map[uint32]uint64{5000: 1}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"calculation_id":11,"quantity":"5000","strategy":"optimal","catalog_version":4,"packs":{"5000":1},"cost":{"lines":null,"price":0,"handling_cost":0,"weight":0,"shipping_cost":0,"total":0}}`,
+		},
+		{
+			name:           "No exact fit",
+			queryParam:     "quantity=5000&mode=exact",
+			mode:           services.FillMode{Name: services.FillExact},
+			mockError:      services.ErrNoExactFit,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"type":"/problems/no-exact-fit","title":"No exact fit","status":422,"detail":"no pack combination matches the quantity exactly","instance":"/calculate"}`,
+		},
+		{
+			name:           "Overfill tolerance exceeded",
+			queryParam:     "quantity=5000&mode=tolerance&max_overfill=0",
+			mode:           services.FillMode{Name: services.FillTolerance, MaxOverfill: ptr(uint64(0))},
+			mockError:      services.ErrOverfillExceeded,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"type":"/problems/overfill-tolerance-exceeded","title":"Overfill tolerance exceeded","status":422,"detail":"no pack combination covers the quantity within the overfill tolerance","instance":"/calculate"}`,
+		},
+		{
+			name:           "Tolerance without a maximum overfill",
+			queryParam:     "quantity=5000&mode=tolerance",
+			mode:           services.FillMode{Name: services.FillTolerance},
+			mockError:      fmt.Errorf("%w: a tolerance needs a maximum overfill", services.ErrInvalidFillMode),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-fill-mode","title":"Invalid fill mode","status":400,"detail":"invalid fill mode: a tolerance needs a maximum overfill","instance":"/calculate"}`,
+		},
+		{
+			name:           "Invalid maximum overfill",
+			queryParam:     "quantity=5000&mode=tolerance&max_overfill=-5",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid max_overfill parameter","instance":"/calculate","errors":[{"field":"max_overfill","message":"must be a whole number of at least 0"}]}`,
+		},
+		{
+			name:         "Alternatives",
+			queryParam:   "quantity=5000&alternatives=2",
+			alternatives: 2,
+			mockResponse: &models.Calculation{
+				ID: 11, Quantity: 5000, Strategy: "optimal", CatalogVersion: 4, Packs: map[uint32]uint64{5000: 1},
+				Alternatives: []models.PackAlternative{
+					{Packs: map[uint32]uint64{5000: 1}, Items: 5000, PackCount: 1, TotalCost: 10},
+					{Packs: map[uint32]uint64{2000: 2, 1000: 1}, Items: 5000, PackCount: 3, TotalCost: 12},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"alternatives":[{"packs":{"5000":1},"items":5000,"overfill":0,"pack_count":1,"total_cost":10},` +
+				`{"packs":{"1000":1,"2000":2},"items":5000,"overfill":0,"pack_count":3,"total_cost":12}],` +
+				`"calculation_id":11,"quantity":"5000","strategy":"optimal","catalog_version":4,"packs":{"5000":1},"cost":{"lines":null,"price":0,"handling_cost":0,"weight":0,"shipping_cost":0,"total":0}}`,
+		},
+		{
+			name:           "Invalid alternatives",
+			queryParam:     "quantity=5000&alternatives=11",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid alternatives parameter","instance":"/calculate","errors":[{"field":"alternatives","message":"must be a whole number between 1 and 10"}]}`,
+		},
+		{
+			name:           "Alternatives unsupported",
+			queryParam:     "quantity=5000&strategy=greedy&alternatives=3",
+			strategy:       "greedy",
+			alternatives:   3,
+			mockError:      services.ErrAlternativesUnsupported,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/alternatives-unsupported","title":"Alternatives not supported","status":400,"detail":"packing strategy does not rank alternatives","instance":"/calculate"}`,
+		},
+		{
+			name:           "Invalid shipping rate",
+			queryParam:     "quantity=5000&shipping_rate=-1",
+			mockResponse:   nil,
+			mockError:      nil,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid shipping_rate parameter","instance":"/calculate","errors":[{"field":"shipping_rate","message":"must be a number of at least 0"}]}`,
+		},
+		{
+			name:           "No pack sizes configured",
+			queryParam:     "quantity=5000",
+			mockResponse:   nil,
+			mockError:      models.ErrNoPackSizes,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"type":"/problems/no-pack-sizes","title":"No pack sizes configured","status":422,"detail":"no pack sizes configured","instance":"/calculate"}`,
+		},
+		{
+			name:         "Insufficient stock",
+			queryParam:   "quantity=5000",
+			mockResponse: nil,
+			mockError: &services.InsufficientStockError{
+				Quantity:  5000,
+				Available: 4500,
+				Stock:     map[uint32]uint32{2000: 2, 500: 1},
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"type":"/problems/insufficient-stock","title":"Insufficient stock","status":409,"detail":"insufficient stock: 5000 items ordered, 4500 available, short by 500","instance":"/calculate","available":4500,"quantity":5000,"shortfall":500,"stock":{"2000":2,"500":1}}`,
+		},
+		{
+			name:           "Unknown strategy",
+			queryParam:     "quantity=5000&strategy=unknown",
+			strategy:       "unknown",
+			mockResponse:   nil,
+			mockError:      services.ErrUnknownStrategy,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/unknown-strategy","title":"Unknown strategy","status":400,"detail":"unknown packing strategy","instance":"/calculate"}`,
 		},
 		{
 			name:           "Invalid quantity parameter",
@@ -98,7 +328,7 @@ func TestCalculatePacks(t *testing.T) {
 			mockResponse:   nil,
 			mockError:      nil,
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"Invalid quantity parameter"}`,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid quantity parameter","instance":"/calculate","errors":[{"field":"quantity","message":"must be a whole number between 0 and 18446744073709551615"}]}`,
 		},
 		{
 			name:           "No quantity parameter",
@@ -106,7 +336,7 @@ func TestCalculatePacks(t *testing.T) {
 			mockResponse:   nil,
 			mockError:      nil,
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":"No quantity parameter"}`,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid quantity parameter","instance":"/calculate","errors":[{"field":"quantity","message":"is required"}]}`,
 		},
 		{
 			name:           "Service error",
@@ -114,7 +344,7 @@ func TestCalculatePacks(t *testing.T) {
 			mockResponse:   nil,
 			mockError:      errors.New("some error"),
 			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   `{"error":"Could not calculate packs"}`,
+			expectedBody:   `{"type":"/problems/internal-error","title":"Internal server error","status":500,"detail":"Could not calculate packs","instance":"/calculate"}`,
 		},
 	}
 
@@ -124,8 +354,16 @@ func TestCalculatePacks(t *testing.T) {
 			// Create a mock service using generated MockPackCalculator
 			mockService := mocks.NewMockPacksCalculator(ctrl)
 
-			if tt.expectedStatus != http.StatusBadRequest {
-				mockService.EXPECT().CalculatePacks(uint32(5000)).Return(tt.mockResponse, tt.mockError).Times(1)
+			if tt.mockResponse != nil || tt.mockError != nil {
+				opts := services.CalculateOptions{
+					Strategy:       tt.strategy,
+					ShippingRate:   tt.shippingRate,
+					Mode:           tt.mode,
+					CatalogVersion: tt.catalogVersion,
+					Alternatives:   tt.alternatives,
+					Caller:         "warehouse",
+				}
+				mockService.EXPECT().CalculatePacks(uint64(5000), opts).Return(tt.mockResponse, tt.mockError).Times(1)
 			}
 
 			// Create a new gin context
@@ -135,6 +373,7 @@ func TestCalculatePacks(t *testing.T) {
 
 			// Create a request to the handler
 			req, _ := http.NewRequest("GET", "/calculate?"+tt.queryParam, nil)
+			req.Header.Set("X-Actor", "warehouse")
 			resp := httptest.NewRecorder()
 
 			// Perform the request
@@ -146,3 +385,517 @@ func TestCalculatePacks(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculatePacksLargeQuantity(t *testing.T) {
+	tests := []struct {
+		name           string
+		quantity       uint64
+		mockResponse   *models.Calculation
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Billions of items",
+			quantity:       10000000001,
+			mockResponse:   &models.Calculation{ID: 12, Quantity: 10000000001, Strategy: "optimal", CatalogVersion: 4, Packs: map[uint32]uint64{5000: 2000000, 250: 1}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"calculation_id":12,"quantity":"10000000001","strategy":"optimal","catalog_version":4,"packs":{"250":1,"5000":2000000},"cost":{"lines":null,"price":0,"handling_cost":0,"weight":0,"shipping_cost":0,"total":0}}`,
+		},
+		{
+			name:           "Beyond the largest total",
+			quantity:       math.MaxUint64,
+			mockError:      services.ErrQuantityTooLarge,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"type":"/problems/quantity-too-large","title":"Order quantity too large","status":422,"detail":"order quantity too large for the pack sizes","instance":"/calculate"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockPacksCalculator(ctrl)
+			mockService.EXPECT().CalculatePacks(tt.quantity, services.CalculateOptions{}).Return(tt.mockResponse, tt.mockError).Times(1)
+
+			router := gin.Default()
+			h := handlers.NewHandler(mockService)
+			router.GET("/calculate", h.CalculatePacks)
+
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/calculate?quantity=%d", tt.quantity), nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
+func TestListPackSizes(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockResponse   []models.PackSize
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Valid request",
+			mockResponse:   []models.PackSize{{ID: 1, Size: 500, Price: 4}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"packs":[{"id":1,"size":500,"price":4,"weight":0,"handling_cost":0}]}`,
+		},
+		{
+			name:           "Service error",
+			mockError:      errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"type":"/problems/internal-error","title":"Internal server error","status":500,"detail":"Could not list pack sizes","instance":"/packs"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockPacksCalculator(ctrl)
+			mockService.EXPECT().ListPackSizes().Return(tt.mockResponse, tt.mockError).Times(1)
+
+			router := gin.Default()
+			h := handlers.NewHandler(mockService)
+			router.GET("/packs", h.ListPackSizes)
+
+			req, _ := http.NewRequest("GET", "/packs", nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
+func TestGetPackSize(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		mockResponse   *models.PackSize
+		mockError      error
+		expectService  bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Valid request",
+			path:           "/packs/1",
+			mockResponse:   &models.PackSize{ID: 1, Size: 500},
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"size":500,"price":0,"weight":0,"handling_cost":0}`,
+		},
+		{
+			name:           "Pack size not found",
+			path:           "/packs/1",
+			mockError:      models.ErrPackSizeNotFound,
+			expectService:  true,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"type":"/problems/pack-size-not-found","title":"Pack size not found","status":404,"detail":"pack size not found","instance":"/packs/1"}`,
+		},
+		{
+			name:           "Invalid id",
+			path:           "/packs/abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid id parameter","instance":"/packs/abc","errors":[{"field":"id","message":"must be a positive whole number"}]}`,
+		},
+		{
+			name:           "Service error",
+			path:           "/packs/1",
+			mockError:      errors.New("some error"),
+			expectService:  true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"type":"/problems/internal-error","title":"Internal server error","status":500,"detail":"Could not get pack size","instance":"/packs/1"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockPacksCalculator(ctrl)
+
+			if tt.expectService {
+				mockService.EXPECT().GetPackSize(uint32(1)).Return(tt.mockResponse, tt.mockError).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewHandler(mockService)
+			router.GET("/packs/:id", h.GetPackSize)
+
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
+func TestUpdatePackSize(t *testing.T) {
+	size := uint32(750)
+
+	tests := []struct {
+		name           string
+		payload        string
+		mockResponse   *models.PackSize
+		mockError      error
+		expectService  bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Valid request",
+			payload:        `{"size": 750}`,
+			mockResponse:   &models.PackSize{ID: 1, Size: 750},
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"size":750,"price":0,"weight":0,"handling_cost":0}`,
+		},
+		{
+			name:           "Zero size",
+			payload:        `{"size": 0}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/validation-failed","title":"Validation failed","status":400,"detail":"One or more fields are invalid","instance":"/packs/1","errors":[{"field":"size","message":"must be greater than 0"}]}`,
+		},
+		{
+			name:           "Pack size not found",
+			payload:        `{"size": 750}`,
+			mockError:      models.ErrPackSizeNotFound,
+			expectService:  true,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"type":"/problems/pack-size-not-found","title":"Pack size not found","status":404,"detail":"pack size not found","instance":"/packs/1"}`,
+		},
+		{
+			name:           "Size already taken",
+			payload:        `{"size": 750}`,
+			mockError:      models.ErrPackSizeExists,
+			expectService:  true,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"type":"/problems/pack-size-exists","title":"Pack size already exists","status":409,"detail":"pack size already exists","instance":"/packs/1"}`,
+		},
+		{
+			name:           "Service error",
+			payload:        `{"size": 750}`,
+			mockError:      errors.New("some error"),
+			expectService:  true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"type":"/problems/internal-error","title":"Internal server error","status":500,"detail":"Could not update pack size","instance":"/packs/1"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockPacksCalculator(ctrl)
+
+			if tt.expectService {
+				update := models.PackSizeUpdateRequest{Size: &size}
+				mockService.EXPECT().UpdatePackSize(uint32(1), update, "anonymous").Return(tt.mockResponse, tt.mockError).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewHandler(mockService)
+			router.PATCH("/packs/:id", h.UpdatePackSize)
+
+			req, _ := http.NewRequest("PATCH", "/packs/1", bytes.NewBuffer([]byte(tt.payload)))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
+func TestReplacePackSizes(t *testing.T) {
+	packs := []models.PackSize{{Size: 1000, Price: 7}, {Size: 300}}
+
+	tests := []struct {
+		name           string
+		payload        string
+		mockResponse   *models.CatalogChange
+		mockError      error
+		expectService  bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Valid request",
+			payload:        `{"packs": [{"size": 1000, "price": 7}, {"size": 300}]}`,
+			mockResponse:   &models.CatalogChange{Added: []uint32{300}, Removed: []uint32{500, 250}},
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"added":[300],"removed":[500,250]}`,
+		},
+		{
+			name:           "Empty pack set",
+			payload:        `{"packs": []}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/validation-failed","title":"Validation failed","status":400,"detail":"One or more fields are invalid","instance":"/packs","errors":[{"field":"packs","message":"must be at least 1"}]}`,
+		},
+		{
+			name:           "Invalid pack in set",
+			payload:        `{"packs": [{"size": 1000}, {"price": -1}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/validation-failed","title":"Validation failed","status":400,"detail":"One or more fields are invalid","instance":"/packs","errors":[{"field":"packs[1].size","message":"is required"},{"field":"packs[1].price","message":"must be at least 0"}]}`,
+		},
+		{
+			name:           "Duplicate size",
+			payload:        `{"packs": [{"size": 1000, "price": 7}, {"size": 300}]}`,
+			mockError:      models.ErrDuplicateSize,
+			expectService:  true,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/duplicate-size","title":"Duplicate pack size","status":400,"detail":"duplicate pack size","instance":"/packs"}`,
+		},
+		{
+			name:           "Service error",
+			payload:        `{"packs": [{"size": 1000, "price": 7}, {"size": 300}]}`,
+			mockError:      errors.New("some error"),
+			expectService:  true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"type":"/problems/internal-error","title":"Internal server error","status":500,"detail":"Could not replace pack sizes","instance":"/packs"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockPacksCalculator(ctrl)
+
+			if tt.expectService {
+				mockService.EXPECT().ReplacePackSizes(packs, "anonymous").Return(tt.mockResponse, tt.mockError).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewHandler(mockService)
+			router.PUT("/packs", h.ReplacePackSizes)
+
+			req, _ := http.NewRequest("PUT", "/packs", bytes.NewBuffer([]byte(tt.payload)))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
+func TestCalculateBatch(t *testing.T) {
+	stockErr := &services.InsufficientStockError{Quantity: 5000, Available: 1250, Stock: map[uint32]uint32{500: 2, 250: 1}}
+	batch := &services.BatchCalculation{
+		Strategy:       "optimal",
+		CatalogVersion: 5,
+		Results: []services.BatchResult{
+			{
+				Item: services.BatchItem{ID: "line-1", Quantity: 251},
+				Calculation: &models.Calculation{
+					ID: 100, Quantity: 251, Strategy: "optimal", CatalogVersion: 5, Packs: map[uint32]uint64{500: 1},
+					Cost: models.CostBreakdown{Lines: []models.CostLine{{Size: 500, Count: 1, Price: 4, Total: 4}}, Price: 4, Total: 4},
+				},
+			},
+			{Item: services.BatchItem{ID: "line-2", Quantity: 5000}, Err: stockErr},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		payload        string
+		mockResponse   *services.BatchCalculation
+		mockError      error
+		expectService  bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Valid request",
+			payload:        `{"items": [{"id": "line-1", "quantity": 251}, {"id": "line-2", "quantity": 5000}], "shipping_rate": 0.5}`,
+			mockResponse:   batch,
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"strategy":"optimal","catalog_version":5,"results":[` +
+				`{"id":"line-1","quantity":251,"calculation_id":100,"packs":{"500":1},"cost":{"lines":[{"size":500,"count":1,"price":4,"handling_cost":0,"weight":0,"shipping_cost":0,"total":4}],"price":4,"handling_cost":0,"weight":0,"shipping_cost":0,"total":4}},` +
+				`{"id":"line-2","quantity":5000,"error":{"type":"/problems/insufficient-stock","title":"Insufficient stock","status":409,` +
+				`"detail":"insufficient stock: 5000 items ordered, 1250 available, short by 3750","quantity":5000,"available":1250,"shortfall":3750,"stock":{"250":1,"500":2}}}]}`,
+		},
+		{
+			name:           "Missing quantity",
+			payload:        `{"items": [{"id": "line-1", "quantity": 251}, {"id": "line-2"}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/validation-failed","title":"Validation failed","status":400,"detail":"One or more fields are invalid","instance":"/calculate/batch","errors":[{"field":"items[1].quantity","message":"is required"}]}`,
+		},
+		{
+			name:           "No items",
+			payload:        `{"items": []}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/validation-failed","title":"Validation failed","status":400,"detail":"One or more fields are invalid","instance":"/calculate/batch","errors":[{"field":"items","message":"must be at least 1"}]}`,
+		},
+		{
+			name:           "No pack sizes",
+			payload:        `{"items": [{"id": "line-1", "quantity": 251}, {"id": "line-2", "quantity": 5000}], "shipping_rate": 0.5}`,
+			mockError:      models.ErrNoPackSizes,
+			expectService:  true,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"type":"/problems/no-pack-sizes","title":"No pack sizes configured","status":422,"detail":"no pack sizes configured","instance":"/calculate/batch"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockPacksCalculator(ctrl)
+
+			if tt.expectService {
+				items := []services.BatchItem{{ID: "line-1", Quantity: 251}, {ID: "line-2", Quantity: 5000}}
+				opts := services.CalculateOptions{ShippingRate: 0.5, Caller: "warehouse"}
+				mockService.EXPECT().CalculateBatch(items, opts).Return(tt.mockResponse, tt.mockError).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewHandler(mockService)
+			router.POST("/calculate/batch", h.CalculateBatch)
+
+			req, _ := http.NewRequest("POST", "/calculate/batch", bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Actor", "warehouse")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
+func TestCalculateWhatIf(t *testing.T) {
+	whatIf := &models.Calculation{
+		Quantity: 263, Strategy: "optimal", Packs: map[uint32]uint64{53: 4, 31: 1, 23: 1},
+		Cost: models.CostBreakdown{Lines: []models.CostLine{}, Total: 6},
+	}
+	live := &models.Calculation{
+		Quantity: 263, Strategy: "optimal", CatalogVersion: 5, Packs: map[uint32]uint64{250: 2},
+		Cost: models.CostBreakdown{Lines: []models.CostLine{}, Total: 5},
+	}
+	hugeLive := &models.Calculation{
+		Quantity: 263, Strategy: "optimal", CatalogVersion: 5, Packs: map[uint32]uint64{4294967295: 4294967297},
+		Cost: models.CostBreakdown{Lines: []models.CostLine{}, Total: 5},
+	}
+	whatIfBody := `"what_if":{"packs":{"23":1,"31":1,"53":4},"items":266,"overfill":3,"cost":{"lines":[],"price":0,"handling_cost":0,"weight":0,"shipping_cost":0,"total":6}}`
+
+	tests := []struct {
+		name           string
+		payload        string
+		expectCalc     bool
+		expectCompare  bool
+		mockComparison *services.CalculationComparison
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "What-if only",
+			payload:        `{"quantity": 263, "pack_sizes": [{"size": 23}, {"size": 31}, {"size": 53}]}`,
+			expectCalc:     true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"quantity":263,"strategy":"optimal",` + whatIfBody + `}`,
+		},
+		{
+			name:           "Compared with the live catalog",
+			payload:        `{"quantity": 263, "pack_sizes": [{"size": 23}, {"size": 31}, {"size": 53}], "compare": true}`,
+			expectCompare:  true,
+			mockComparison: &services.CalculationComparison{WhatIf: whatIf, Live: live},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"quantity":263,"strategy":"optimal",` + whatIfBody + `,` +
+				`"live":{"catalog_version":5,"packs":{"250":2},"items":500,"overfill":237,"cost":{"lines":[],"price":0,"handling_cost":0,"weight":0,"shipping_cost":0,"total":5}},` +
+				`"difference":{"items":-234,"packs":4,"total_cost":1}}`,
+		},
+		{
+			name:           "Difference beyond the int64 range",
+			payload:        `{"quantity": 263, "pack_sizes": [{"size": 23}, {"size": 31}, {"size": 53}], "compare": true}`,
+			expectCompare:  true,
+			mockComparison: &services.CalculationComparison{WhatIf: whatIf, Live: hugeLive},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"quantity":263,"strategy":"optimal",` + whatIfBody + `,` +
+				`"live":{"catalog_version":5,"packs":{"4294967295":4294967297},"items":18446744073709551615,"overfill":18446744073709551352,"cost":{"lines":[],"price":0,"handling_cost":0,"weight":0,"shipping_cost":0,"total":5}},` +
+				`"difference":{"items":-18446744073709551349,"packs":-4294967291,"total_cost":1}}`,
+		},
+		{
+			name:           "Live catalog cannot calculate the order",
+			payload:        `{"quantity": 263, "pack_sizes": [{"size": 23}, {"size": 31}, {"size": 53}], "compare": true}`,
+			expectCompare:  true,
+			mockComparison: &services.CalculationComparison{WhatIf: whatIf, LiveErr: models.ErrNoPackSizes},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"quantity":263,"strategy":"optimal",` + whatIfBody + `,` +
+				`"live":{"items":0,"overfill":0,"error":{"type":"/problems/no-pack-sizes","title":"No pack sizes configured","status":422,"detail":"no pack sizes configured"}}}`,
+		},
+		{
+			name:           "Duplicate sizes",
+			payload:        `{"quantity": 263, "pack_sizes": [{"size": 23}, {"size": 31}, {"size": 53}]}`,
+			expectCalc:     true,
+			mockError:      models.ErrDuplicateSize,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/duplicate-size","title":"Duplicate pack size","status":400,"detail":"duplicate pack size","instance":"/calculate"}`,
+		},
+		{
+			name:           "No pack sizes",
+			payload:        `{"quantity": 263, "pack_sizes": []}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/validation-failed","title":"Validation failed","status":400,"detail":"One or more fields are invalid","instance":"/calculate","errors":[{"field":"pack_sizes","message":"must be at least 1"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockPacksCalculator(ctrl)
+
+			opts := services.CalculateOptions{
+				Caller:    "analyst",
+				PackSizes: []models.PackSize{{Size: 23}, {Size: 31}, {Size: 53}},
+			}
+			if tt.expectCalc {
+				calculation := whatIf
+				if tt.mockError != nil {
+					calculation = nil
+				}
+				mockService.EXPECT().CalculatePacks(uint64(263), opts).Return(calculation, tt.mockError).Times(1)
+			}
+			if tt.expectCompare {
+				mockService.EXPECT().CompareCalculation(uint64(263), opts).Return(tt.mockComparison, tt.mockError).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewHandler(mockService)
+			router.POST("/calculate", h.CalculateWhatIf)
+
+			req, _ := http.NewRequest("POST", "/calculate", bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Actor", "analyst")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
+// ptr returns a pointer to v.
+func ptr[T any](v T) *T {
+	return &v
+}
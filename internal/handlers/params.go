@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/klemis/packs-calculator/internal/services"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// actorHeader names the caller a change is made on behalf of. It is taken at its word: the service
+// does not authenticate callers, so the actor and caller recorded from it are advisory and can be set
+// to anything by a client that reaches the service directly.
+const actorHeader = "X-Actor"
+
+// anonymousActor is recorded for changes made without an actorHeader.
+const anonymousActor = "anonymous"
+
+const (
+	// defaultPageLimit is the page size used when a request does not ask for one.
+	defaultPageLimit = 50
+	// maxPageLimit caps the page size a request may ask for.
+	maxPageLimit = 500
+)
+
+// sizeParam parses the :size path parameter, responding with 400 when it is not a valid pack size.
+func sizeParam(c *gin.Context) (uint32, bool) {
+	size, err := strconv.ParseUint(c.Param("size"), 10, 32)
+	if err != nil || size == 0 {
+		respondInvalidParam(c, "size", "must be a positive whole number")
+		return 0, false
+	}
+
+	return uint32(size), true
+}
+
+// idParam parses the :id path parameter, responding with 400 when it is not a valid ID.
+func idParam(c *gin.Context) (uint32, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		respondInvalidParam(c, "id", "must be a positive whole number")
+		return 0, false
+	}
+
+	return uint32(id), true
+}
+
+// recordIDParam parses the 64-bit :id path parameter of a history record, responding with 400 when
+// it is not a valid ID.
+func recordIDParam(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 63)
+	if err != nil || id == 0 {
+		respondInvalidParam(c, "id", "must be a positive whole number")
+		return 0, false
+	}
+
+	return id, true
+}
+
+// quantityParam parses the required quantity query parameter of a calculation, responding with 400 when
+// it is missing or not a valid order quantity.
+func quantityParam(c *gin.Context) (uint64, bool) {
+	quantity := c.Query("quantity")
+	if quantity == "" {
+		respondInvalidParam(c, "quantity", "is required")
+		return 0, false
+	}
+
+	q, err := strconv.ParseUint(quantity, 10, 64)
+	if err != nil {
+		respondInvalidParam(c, "quantity", "must be a whole number between 0 and 18446744073709551615")
+		return 0, false
+	}
+
+	return q, true
+}
+
+// alternativesParam parses the optional alternatives query parameter of a calculation, responding with
+// 400 when it is not a count of alternatives that can be ranked. A missing parameter yields 0.
+func alternativesParam(c *gin.Context) (int, bool) {
+	value := c.Query("alternatives")
+	if value == "" {
+		return 0, true
+	}
+
+	k, err := strconv.Atoi(value)
+	if err != nil || k < 1 || k > services.MaxAlternatives {
+		respondInvalidParam(c, "alternatives", fmt.Sprintf("must be a whole number between 1 and %d", services.MaxAlternatives))
+		return 0, false
+	}
+
+	return k, true
+}
+
+// dryRunParam parses the optional dry_run query parameter, responding with 400 when it is not a boolean.
+// A missing parameter yields false.
+func dryRunParam(c *gin.Context) (bool, bool) {
+	value := c.Query("dry_run")
+	if value == "" {
+		return false, true
+	}
+
+	dryRun, err := strconv.ParseBool(value)
+	if err != nil {
+		respondInvalidParam(c, "dry_run", "must be true or false")
+		return false, false
+	}
+
+	return dryRun, true
+}
+
+// pageParams parses the optional limit and offset query parameters of a listing, responding with 400 when
+// one is invalid. A missing limit yields defaultPageLimit and a missing offset 0.
+func pageParams(c *gin.Context) (uint32, uint64, bool) {
+	limit, offset := uint64(defaultPageLimit), uint64(0)
+	if value := c.Query("limit"); value != "" {
+		var err error
+		limit, err = strconv.ParseUint(value, 10, 32)
+		if err != nil || limit == 0 || limit > maxPageLimit {
+			respondInvalidParam(c, "limit", fmt.Sprintf("must be a whole number between 1 and %d", maxPageLimit))
+			return 0, 0, false
+		}
+	}
+	if value := c.Query("offset"); value != "" {
+		var err error
+		offset, err = strconv.ParseUint(value, 10, 63)
+		if err != nil {
+			respondInvalidParam(c, "offset", "must be a whole number of at least 0")
+			return 0, 0, false
+		}
+	}
+
+	return uint32(limit), offset, true
+}
+
+// timeParam parses an optional RFC 3339 query parameter, responding with 400 when it is malformed.
+// A missing parameter yields the zero time.
+func timeParam(c *gin.Context, name string) (time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, true
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		respondInvalidParam(c, name, "must be an RFC 3339 timestamp")
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// caller returns who made the request: the actor named by the actorHeader, or else the client IP.
+func caller(c *gin.Context) string {
+	if name := strings.TrimSpace(c.GetHeader(actorHeader)); name != "" {
+		return name
+	}
+
+	return c.ClientIP()
+}
+
+// actor returns who the request acts on behalf of, as named by the actorHeader.
+func actor(c *gin.Context) string {
+	if name := strings.TrimSpace(c.GetHeader(actorHeader)); name != "" {
+		return name
+	}
+
+	return anonymousActor
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// problemContentType is the media type of RFC 7807 problem details.
+const problemContentType = "application/problem+json"
+
+// problemTypeBase prefixes the type URI of every problem reported by the API.
+const problemTypeBase = "/problems/"
+
+// problem is an RFC 7807 problem details object.
+type problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []fieldError `json:"errors,omitempty"`
+	// Extensions are extra members serialised alongside the standard ones.
+	Extensions map[string]any `json:"-"`
+}
+
+// fieldError describes why a single request field was rejected.
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// MarshalJSON flattens the extension members into the problem object.
+func (p problem) MarshalJSON() ([]byte, error) {
+	type standard problem
+	body, err := json.Marshal(standard(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return body, err
+	}
+
+	members := make(map[string]any, len(p.Extensions))
+	for name, value := range p.Extensions {
+		members[name] = value
+	}
+	if err := json.Unmarshal(body, &members); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(members)
+}
+
+func init() {
+	// Report validation errors with the JSON names clients send rather than Go field names.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// respondProblem aborts the request with a problem of the given type slug, status and title.
+func respondProblem(c *gin.Context, p problem) {
+	p.Type = problemTypeBase + p.Type
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// respondInvalidParam reports a missing or malformed query or path parameter.
+func respondInvalidParam(c *gin.Context, name, message string) {
+	respondProblem(c, problem{
+		Type:   "invalid-request",
+		Title:  "Invalid request",
+		Status: http.StatusBadRequest,
+		Detail: fmt.Sprintf("Invalid %s parameter", name),
+		Errors: []fieldError{{Field: name, Message: message}},
+	})
+}
+
+// respondBindError reports a request body that could not be decoded or failed validation,
+// listing every rejected field.
+func respondBindError(c *gin.Context, err error) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		respondProblem(c, problem{
+			Type:   "invalid-request",
+			Title:  "Invalid request",
+			Status: http.StatusBadRequest,
+			Detail: "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	fields := make([]fieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, fieldError{Field: fieldPath(fe), Message: validationMessage(fe)})
+	}
+
+	respondProblem(c, problem{
+		Type:   "validation-failed",
+		Title:  "Validation failed",
+		Status: http.StatusBadRequest,
+		Detail: "One or more fields are invalid",
+		Errors: fields,
+	})
+}
+
+// fieldPath returns the JSON path of the rejected field, such as "packs[1].size".
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.IndexByte(namespace, '.'); i >= 0 {
+		return namespace[i+1:]
+	}
+
+	return fe.Field()
+}
+
+// validationMessage describes a failed validation rule in plain words.
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + fe.Param()
+	case "max":
+		return "must be at most " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	default:
+		return fmt.Sprintf("failed the %q rule", fe.Tag())
+	}
+}
+
+// NotFound reports requests to routes the API does not serve.
+func NotFound(c *gin.Context) {
+	respondProblem(c, problem{
+		Type:   "route-not-found",
+		Title:  "Route not found",
+		Status: http.StatusNotFound,
+		Detail: fmt.Sprintf("No route for %s %s", c.Request.Method, c.Request.URL.Path),
+	})
+}
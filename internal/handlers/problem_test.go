@@ -0,0 +1,67 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/handlers"
+	"github.com/klemis/packs-calculator/internal/services/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblemResponses(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		payload        string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Every invalid field is listed",
+			method:         "POST",
+			path:           "/packs",
+			payload:        `{"price": -1, "weight": -2}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: `{"type":"/problems/validation-failed","title":"Validation failed","status":400,` +
+				`"detail":"One or more fields are invalid","instance":"/packs","errors":[` +
+				`{"field":"size","message":"is required"},` +
+				`{"field":"price","message":"must be at least 0"},` +
+				`{"field":"weight","message":"must be at least 0"}]}`,
+		},
+		{
+			name:           "Unknown route",
+			method:         "GET",
+			path:           "/unknown",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"type":"/problems/route-not-found","title":"Route not found","status":404,"detail":"No route for GET /unknown","instance":"/unknown"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockPacksCalculator(ctrl)
+
+			router := gin.Default()
+			h := handlers.NewHandler(mockService)
+			router.POST("/packs", h.AddPackSize)
+			router.NoRoute(handlers.NotFound)
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBuffer([]byte(tt.payload)))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Equal(t, "application/problem+json", resp.Header().Get("Content-Type"))
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/models"
+	"math"
+	"net/http"
+)
+
+type RecommendationHandler struct {
+	service services.PackRecommender
+}
+
+// NewRecommendationHandler creates a new RecommendationHandler with the provided RecommendationService.
+func NewRecommendationHandler(recommendationService services.PackRecommender) *RecommendationHandler {
+	return &RecommendationHandler{
+		service: recommendationService,
+	}
+}
+
+// RecommendPackSizes handles ranking candidate pack catalogs for a distribution of order quantities,
+// uploaded in the body or else drawn from the calculation history between from and to.
+func (h *RecommendationHandler) RecommendPackSizes(c *gin.Context) {
+	var req *models.PackRecommendationRequest
+	if err := c.BindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	opts := services.RecommendationOptions{
+		History:    models.CalculationFilter{MinQuantity: 1, MaxQuantity: math.MaxUint64},
+		Sizes:      req.Sizes,
+		MinSize:    req.MinSize,
+		MaxSize:    req.MaxSize,
+		MultipleOf: req.MultipleOf,
+		PackWeight: req.PackWeight,
+		Limit:      req.Limit,
+	}
+	if req.Quantities != nil {
+		opts.Quantities = make([]models.QuantityCount, len(req.Quantities))
+		for i, quantity := range req.Quantities {
+			opts.Quantities[i] = models.QuantityCount{Quantity: quantity.Quantity, Count: max(quantity.Count, 1)}
+		}
+	}
+	if req.From != nil {
+		opts.History.From = *req.From
+	}
+	if req.To != nil {
+		opts.History.To = *req.To
+	}
+	if !opts.History.From.IsZero() && !opts.History.To.IsZero() && opts.History.To.Before(opts.History.From) {
+		respondInvalidParam(c, "to", "must not be before from")
+		return
+	}
+
+	recommendation, err := h.service.RecommendPackSizes(opts)
+	if err != nil {
+		respondError(c, err, "Could not recommend pack sizes")
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendation)
+}
@@ -0,0 +1,108 @@
+package handlers_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/handlers"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/internal/services/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecommendPackSizes(t *testing.T) {
+	history := models.CalculationFilter{MinQuantity: 1, MaxQuantity: math.MaxUint64}
+	from := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	recommendation := &models.PackRecommendation{
+		Source:     services.RecommendationSourceUpload,
+		Orders:     4,
+		Quantities: 2,
+		Candidates: []models.CatalogCandidate{{Rank: 1, Sizes: []uint32{500, 250}, Score: 62.5, ExpectedOverfill: 62.5, OverfillPercent: 20, ExpectedPackCount: 1.25, WorstOverfill: 250, ExactFitRate: 0.75}},
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		opts           *services.RecommendationOptions
+		mockResponse   *models.PackRecommendation
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Uploaded quantities",
+			body: `{"quantities":[{"quantity":250,"count":3},{"quantity":1000}],"sizes":2,"multiple_of":250,"pack_weight":1.5,"limit":1}`,
+			opts: &services.RecommendationOptions{
+				Quantities: []models.QuantityCount{{Quantity: 250, Count: 3}, {Quantity: 1000, Count: 1}},
+				History:    history,
+				Sizes:      2,
+				MultipleOf: 250,
+				PackWeight: 1.5,
+				Limit:      1,
+			},
+			mockResponse:   recommendation,
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"source":"upload","orders":4,"quantities":2,"candidates":[{"rank":1,"sizes":[500,250],"score":62.5,` +
+				`"expected_overfill":62.5,"overfill_percent":20,"expected_pack_count":1.25,"worst_overfill":250,"exact_fit_rate":0.75}]}`,
+		},
+		{
+			name:           "Empty history",
+			body:           `{"from":"2024-09-01T00:00:00Z","sizes":3,"min_size":100,"max_size":5000}`,
+			opts:           &services.RecommendationOptions{History: models.CalculationFilter{From: from, MinQuantity: 1, MaxQuantity: math.MaxUint64}, Sizes: 3, MinSize: 100, MaxSize: 5000},
+			mockError:      services.ErrNoOrderQuantities,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"type":"/problems/no-order-quantities","title":"No order quantities","status":422,"detail":"no order quantities to recommend pack sizes for","instance":"/packs/recommendations"}`,
+		},
+		{
+			name:           "Constraints not met",
+			body:           `{"sizes":7}`,
+			opts:           &services.RecommendationOptions{History: history, Sizes: 7},
+			mockError:      fmt.Errorf("%w: a catalog holds between 1 and 6 sizes", services.ErrInvalidRecommendation),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-recommendation","title":"Invalid recommendation constraints","status":400,"detail":"invalid recommendation constraints: a catalog holds between 1 and 6 sizes","instance":"/packs/recommendations"}`,
+		},
+		{
+			name:           "Window out of order",
+			body:           `{"from":"2024-09-01T00:00:00Z","to":"2024-08-01T00:00:00Z","sizes":3}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid to parameter","instance":"/packs/recommendations","errors":[{"field":"to","message":"must not be before from"}]}`,
+		},
+		{
+			name:           "Missing sizes",
+			body:           `{"quantities":[{"quantity":250}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/validation-failed","title":"Validation failed","status":400,"detail":"One or more fields are invalid","instance":"/packs/recommendations","errors":[{"field":"sizes","message":"is required"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockPackRecommender(ctrl)
+			if tt.opts != nil {
+				mockService.EXPECT().RecommendPackSizes(*tt.opts).Return(tt.mockResponse, tt.mockError).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewRecommendationHandler(mockService)
+			router.POST("/packs/recommendations", h.RecommendPackSizes)
+
+			req, _ := http.NewRequest("POST", "/packs/recommendations", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Equal(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
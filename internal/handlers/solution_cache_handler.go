@@ -0,0 +1,12 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// GetSolutionCacheStats handles describing the solution cache of the calculate endpoint: the quantities
+// and pack sizes it holds, its memory footprint and its hit rate.
+func (h *Handler) GetSolutionCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.SolutionCacheStats())
+}
@@ -0,0 +1,44 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/handlers"
+	"github.com/klemis/packs-calculator/internal/services/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSolutionCacheStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockPacksCalculator(ctrl)
+	mockService.EXPECT().SolutionCacheStats().Return(models.SolutionCacheStats{
+		Limit:       100000,
+		MaxSizes:    16,
+		Sizes:       []uint32{500, 250},
+		Totals:      100250,
+		MemoryBytes: 1204008,
+		Hits:        3,
+		Misses:      1,
+		HitRate:     0.75,
+		Builds:      1,
+		Updates:     2,
+	}).Times(1)
+
+	router := gin.Default()
+	h := handlers.NewHandler(mockService)
+	router.GET("/calculate/cache", h.GetSolutionCacheStats)
+
+	req, _ := http.NewRequest("GET", "/calculate/cache", nil)
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"limit":100000,"max_sizes":16,"sizes":[500,250],"totals":100250,"memory_bytes":1204008,"hits":3,"misses":1,`+
+		`"hit_rate":0.75,"builds":1,"updates":2}`, resp.Body.String())
+}
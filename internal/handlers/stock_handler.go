@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/klemis/packs-calculator/internal/services"
+	"github.com/klemis/packs-calculator/models"
+	"net/http"
+)
+
+type StockHandler struct {
+	service services.StockManager
+}
+
+// NewStockHandler creates a new StockHandler with the provided StockService.
+func NewStockHandler(stockService services.StockManager) *StockHandler {
+	return &StockHandler{
+		service: stockService,
+	}
+}
+
+// GetStock handles listing the packs on hand for every pack size with tracked stock.
+func (h *StockHandler) GetStock(c *gin.Context) {
+	stock, err := h.service.GetStock()
+	if err != nil {
+		respondError(c, err, "Could not get stock")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stock": stock})
+}
+
+// SetStock handles setting the packs on hand for a pack size.
+func (h *StockHandler) SetStock(c *gin.Context) {
+	size, ok := sizeParam(c)
+	if !ok {
+		return
+	}
+
+	var req *models.StockRequest
+	if err := c.BindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if err := h.service.SetStock(size, *req.Quantity); err != nil {
+		respondError(c, err, "Could not set stock")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Stock successfully set"})
+}
+
+// AdjustStock handles adding or removing packs on hand for a pack size.
+func (h *StockHandler) AdjustStock(c *gin.Context) {
+	size, ok := sizeParam(c)
+	if !ok {
+		return
+	}
+
+	var req *models.StockAdjustmentRequest
+	if err := c.BindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	quantity, err := h.service.AdjustStock(size, req.Delta)
+	if err != nil {
+		respondError(c, err, "Could not adjust stock")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"size": size, "quantity": quantity})
+}
+
+// ClearStock handles untracking the stock of a pack size, making its supply unlimited.
+func (h *StockHandler) ClearStock(c *gin.Context) {
+	size, ok := sizeParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ClearStock(size); err != nil {
+		respondError(c, err, "Could not clear stock")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Stock successfully cleared"})
+}
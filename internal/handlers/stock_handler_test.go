@@ -0,0 +1,237 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/handlers"
+	"github.com/klemis/packs-calculator/internal/services/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetStock(t *testing.T) {
+	updatedAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		mockResponse   []models.PackStock
+		mockError      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Valid request",
+			mockResponse:   []models.PackStock{{Size: 500, Quantity: 10, UpdatedAt: updatedAt}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"stock":[{"size":500,"quantity":10,"updated_at":"2024-10-01T12:00:00Z"}]}`,
+		},
+		{
+			name:           "Service error",
+			mockError:      errors.New("some error"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"type":"/problems/internal-error","title":"Internal server error","status":500,"detail":"Could not get stock","instance":"/stock"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockStockManager(ctrl)
+			mockService.EXPECT().GetStock().Return(tt.mockResponse, tt.mockError).Times(1)
+
+			router := gin.Default()
+			h := handlers.NewStockHandler(mockService)
+			router.GET("/stock", h.GetStock)
+
+			req, _ := http.NewRequest("GET", "/stock", nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
+func TestSetStock(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		payload        string
+		mockResponse   error
+		expectService  bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Valid request",
+			path:           "/stock/500",
+			payload:        `{"quantity": 0}`,
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"message":"Stock successfully set"}`,
+		},
+		{
+			name:           "Invalid size",
+			path:           "/stock/abc",
+			payload:        `{"quantity": 0}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid size parameter","instance":"/stock/abc","errors":[{"field":"size","message":"must be a positive whole number"}]}`,
+		},
+		{
+			name:           "Missing quantity",
+			path:           "/stock/500",
+			payload:        `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/validation-failed","title":"Validation failed","status":400,"detail":"One or more fields are invalid","instance":"/stock/500","errors":[{"field":"quantity","message":"is required"}]}`,
+		},
+		{
+			name:           "Quantity beyond the stock column",
+			path:           "/stock/500",
+			payload:        `{"quantity": 2147483648}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/validation-failed","title":"Validation failed","status":400,"detail":"One or more fields are invalid","instance":"/stock/500","errors":[{"field":"quantity","message":"must be at most 2147483647"}]}`,
+		},
+		{
+			name:           "Pack size not found",
+			path:           "/stock/500",
+			payload:        `{"quantity": 0}`,
+			mockResponse:   models.ErrPackSizeNotFound,
+			expectService:  true,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"type":"/problems/pack-size-not-found","title":"Pack size not found","status":404,"detail":"pack size not found","instance":"/stock/500"}`,
+		},
+		{
+			name:           "Service error",
+			path:           "/stock/500",
+			payload:        `{"quantity": 0}`,
+			mockResponse:   errors.New("some error"),
+			expectService:  true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"type":"/problems/internal-error","title":"Internal server error","status":500,"detail":"Could not set stock","instance":"/stock/500"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockStockManager(ctrl)
+
+			if tt.expectService {
+				mockService.EXPECT().SetStock(uint32(500), uint32(0)).Return(tt.mockResponse).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewStockHandler(mockService)
+			router.PUT("/stock/:size", h.SetStock)
+
+			req, _ := http.NewRequest("PUT", tt.path, bytes.NewBuffer([]byte(tt.payload)))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
+func TestAdjustStock(t *testing.T) {
+	tests := []struct {
+		name           string
+		payload        string
+		mockQuantity   uint32
+		mockError      error
+		expectService  bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Valid request",
+			payload:        `{"delta": -3}`,
+			mockQuantity:   7,
+			expectService:  true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"size":500,"quantity":7}`,
+		},
+		{
+			name:           "Invalid JSON request",
+			payload:        `invalid json`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/invalid-request","title":"Invalid request","status":400,"detail":"Invalid request body: invalid character 'i' looking for beginning of value","instance":"/stock/500/adjust"}`,
+		},
+		{
+			name:           "Delta beyond the stock column",
+			payload:        `{"delta": -2147483648}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"/problems/validation-failed","title":"Validation failed","status":400,"detail":"One or more fields are invalid","instance":"/stock/500/adjust","errors":[{"field":"delta","message":"must be at least -2147483647"}]}`,
+		},
+		{
+			name:           "Stock would leave its range",
+			payload:        `{"delta": -3}`,
+			mockError:      models.ErrInvalidStockAdjustment,
+			expectService:  true,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"type":"/problems/invalid-stock-adjustment","title":"Stock adjustment not possible","status":409,"detail":"stock not tracked for pack size or adjustment would take it below 0 or above 2147483647","instance":"/stock/500/adjust"}`,
+		},
+		{
+			name:           "Service error",
+			payload:        `{"delta": -3}`,
+			mockError:      errors.New("some error"),
+			expectService:  true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"type":"/problems/internal-error","title":"Internal server error","status":500,"detail":"Could not adjust stock","instance":"/stock/500/adjust"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := mocks.NewMockStockManager(ctrl)
+
+			if tt.expectService {
+				mockService.EXPECT().AdjustStock(uint32(500), int64(-3)).Return(tt.mockQuantity, tt.mockError).Times(1)
+			}
+
+			router := gin.Default()
+			h := handlers.NewStockHandler(mockService)
+			router.POST("/stock/:size/adjust", h.AdjustStock)
+
+			req, _ := http.NewRequest("POST", "/stock/500/adjust", bytes.NewBuffer([]byte(tt.payload)))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
+func TestClearStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := mocks.NewMockStockManager(ctrl)
+	mockService.EXPECT().ClearStock(uint32(500)).Return(nil).Times(1)
+
+	router := gin.Default()
+	h := handlers.NewStockHandler(mockService)
+	router.DELETE("/stock/:size", h.ClearStock)
+
+	req, _ := http.NewRequest("DELETE", "/stock/500", nil)
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"message":"Stock successfully cleared"}`, resp.Body.String())
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/klemis/packs-calculator/models"
+	"github.com/lib/pq"
+)
+
+// AuditRepository defines the interface for reading the audit log of catalog changes.
+type AuditRepository interface {
+	GetAuditEntries(filter models.AuditFilter) ([]models.AuditEntry, uint64, error)
+}
+
+// SQLAuditRepository is the struct that implements AuditRepository interface for SQL database.
+type SQLAuditRepository struct {
+	db *sql.DB
+}
+
+// NewSQLAuditRepository initializes a new SQL-based audit repository.
+func NewSQLAuditRepository(db *sql.DB) AuditRepository {
+	return &SQLAuditRepository{db: db}
+}
+
+// auditFilterWhere selects the audit entries matching an AuditFilter, taking its from, to and actor as
+// the first three arguments.
+const auditFilterWhere = `WHERE ($1::timestamptz IS NULL OR created_at >= $1) AND ($2::timestamptz IS NULL OR created_at <= $2)
+		AND ($3 = '' OR actor = $3)`
+
+// GetAuditEntries retrieves a page of the audit entries matching filter, newest first, with the catalog
+// before and after each change and the number of matching entries across all pages.
+func (r *SQLAuditRepository) GetAuditEntries(filter models.AuditFilter) ([]models.AuditEntry, uint64, error) {
+	from := sql.NullTime{Time: filter.From, Valid: !filter.From.IsZero()}
+	to := sql.NullTime{Time: filter.To, Valid: !filter.To.IsZero()}
+
+	var total uint64
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM audit_log `+auditFilterWhere, from, to, filter.Actor).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Query(`SELECT id, actor, action, before_version, after_version, created_at FROM audit_log `+
+		auditFilterWhere+` ORDER BY id DESC LIMIT $4 OFFSET $5`,
+		from, to, filter.Actor, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	var beforeVersions, afterVersions []sql.NullInt64
+	for rows.Next() {
+		var entry models.AuditEntry
+		var before, after sql.NullInt64
+		if err := rows.Scan(&entry.ID, &entry.Actor, &entry.Action, &before, &after, &entry.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+		beforeVersions = append(beforeVersions, before)
+		afterVersions = append(afterVersions, after)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	versions, err := r.getCatalogVersions(append(beforeVersions, afterVersions...))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range entries {
+		entries[i].Before = versions[beforeVersions[i].Int64]
+		entries[i].After = versions[afterVersions[i].Int64]
+	}
+
+	return entries, total, nil
+}
+
+// getCatalogVersions retrieves the given catalog versions with their pack sizes, keyed by ID.
+func (r *SQLAuditRepository) getCatalogVersions(ids []sql.NullInt64) (map[int64]*models.CatalogVersion, error) {
+	versions := make(map[int64]*models.CatalogVersion)
+
+	seen := make(map[int64]bool)
+	var wanted []int64
+	for _, id := range ids {
+		if id.Valid && !seen[id.Int64] {
+			seen[id.Int64] = true
+			wanted = append(wanted, id.Int64)
+		}
+	}
+	if len(wanted) == 0 {
+		return versions, nil
+	}
+
+	rows, err := r.db.Query(`SELECT id, created_at FROM catalog_versions WHERE id = ANY($1)`, pq.Array(wanted))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		version := &models.CatalogVersion{PackSizes: []models.PackSize{}}
+		if err := rows.Scan(&version.ID, &version.CreatedAt); err != nil {
+			return nil, err
+		}
+		versions[int64(version.ID)] = version
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	packRows, err := r.db.Query(`SELECT version_id, pack_size_id, size, price, weight, handling_cost
+		FROM catalog_version_packs WHERE version_id = ANY($1) ORDER BY version_id, size DESC`, pq.Array(wanted))
+	if err != nil {
+		return nil, err
+	}
+	defer packRows.Close()
+
+	for packRows.Next() {
+		var versionID int64
+		var pack models.PackSize
+		if err := packRows.Scan(&versionID, &pack.ID, &pack.Size, &pack.Price, &pack.Weight, &pack.HandlingCost); err != nil {
+			return nil, err
+		}
+		if version, ok := versions[versionID]; ok {
+			version.PackSizes = append(version.PackSizes, pack)
+		}
+	}
+
+	return versions, packRows.Err()
+}
@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAuditEntries(t *testing.T) {
+	createdAt := time.Date(2024, 8, 14, 9, 30, 0, 0, time.UTC)
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	where := `WHERE ($1::timestamptz IS NULL OR created_at >= $1) AND ($2::timestamptz IS NULL OR created_at <= $2)
+		AND ($3 = '' OR actor = $3)`
+	countQuery := `SELECT COUNT(*) FROM audit_log ` + where
+	entriesQuery := `SELECT id, actor, action, before_version, after_version, created_at FROM audit_log ` + where +
+		` ORDER BY id DESC LIMIT $4 OFFSET $5`
+	versionsQuery := `SELECT id, created_at FROM catalog_versions WHERE id = ANY($1)`
+	packsQuery := `SELECT version_id, pack_size_id, size, price, weight, handling_cost
+		FROM catalog_version_packs WHERE version_id = ANY($1) ORDER BY version_id, size DESC`
+
+	tests := []struct {
+		name          string
+		filter        models.AuditFilter
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expected      []models.AuditEntry
+		expectedTotal uint64
+		expectedError string
+	}{
+		{
+			name:   "entries with catalogs",
+			filter: models.AuditFilter{From: from, Actor: "alice", Limit: 2, Offset: 1},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(countQuery)).
+					WithArgs(from, nil, "alice").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectQuery(regexp.QuoteMeta(entriesQuery)).
+					WithArgs(from, nil, "alice", 2, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "actor", "action", "before_version", "after_version", "created_at"}).
+						AddRow(2, "alice", "delete", 3, 4, createdAt).
+						AddRow(1, "alice", "create", nil, 3, createdAt))
+				mock.ExpectQuery(regexp.QuoteMeta(versionsQuery)).
+					WithArgs(pq.Array([]int64{3, 4})).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).
+						AddRow(3, createdAt).
+						AddRow(4, createdAt))
+				mock.ExpectQuery(regexp.QuoteMeta(packsQuery)).
+					WithArgs(pq.Array([]int64{3, 4})).
+					WillReturnRows(sqlmock.NewRows([]string{"version_id", "pack_size_id", "size", "price", "weight", "handling_cost"}).
+						AddRow(3, 4, 2000, 0.0, 0.0, 0.0).
+						AddRow(3, 1, 250, 0.0, 0.0, 0.0).
+						AddRow(4, 1, 250, 0.0, 0.0, 0.0))
+			},
+			expected: []models.AuditEntry{
+				{
+					ID: 2, Actor: "alice", Action: "delete", CreatedAt: createdAt,
+					Before: &models.CatalogVersion{ID: 3, CreatedAt: createdAt, PackSizes: []models.PackSize{{ID: 4, Size: 2000}, {ID: 1, Size: 250}}},
+					After:  &models.CatalogVersion{ID: 4, CreatedAt: createdAt, PackSizes: []models.PackSize{{ID: 1, Size: 250}}},
+				},
+				{
+					ID: 1, Actor: "alice", Action: "create", CreatedAt: createdAt,
+					After: &models.CatalogVersion{ID: 3, CreatedAt: createdAt, PackSizes: []models.PackSize{{ID: 4, Size: 2000}, {ID: 1, Size: 250}}},
+				},
+			},
+			expectedTotal: 3,
+		},
+		{
+			name:   "no entries",
+			filter: models.AuditFilter{Limit: 50},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(countQuery)).
+					WithArgs(nil, nil, "").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta(entriesQuery)).
+					WithArgs(nil, nil, "", 50, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "actor", "action", "before_version", "after_version", "created_at"}))
+			},
+		},
+		{
+			name: "count error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(countQuery)).
+					WithArgs(nil, nil, "").
+					WillReturnError(errors.New("count error"))
+			},
+			expectedError: "count error",
+		},
+		{
+			name:   "query error",
+			filter: models.AuditFilter{Limit: 50},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(countQuery)).
+					WithArgs(nil, nil, "").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(entriesQuery)).
+					WithArgs(nil, nil, "", 50, 0).
+					WillReturnError(errors.New("query error"))
+			},
+			expectedError: "query error",
+		},
+		{
+			name:   "catalog query error",
+			filter: models.AuditFilter{Limit: 50},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(countQuery)).
+					WithArgs(nil, nil, "").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(entriesQuery)).
+					WithArgs(nil, nil, "", 50, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "actor", "action", "before_version", "after_version", "created_at"}).
+						AddRow(1, "alice", "create", sql.NullInt64{}, 3, createdAt))
+				mock.ExpectQuery(regexp.QuoteMeta(versionsQuery)).
+					WithArgs(pq.Array([]int64{3})).
+					WillReturnError(errors.New("catalog error"))
+			},
+			expectedError: "catalog error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewSQLAuditRepository(db)
+			tt.mockSetup(mock)
+
+			entries, total, err := repo.GetAuditEntries(tt.filter)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, entries)
+				assert.Equal(t, tt.expectedTotal, total)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
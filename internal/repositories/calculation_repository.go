@@ -0,0 +1,209 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/klemis/packs-calculator/models"
+)
+
+// CalculationRepository defines the interface for keeping the history of calculations.
+type CalculationRepository interface {
+	SaveCalculation(record *models.CalculationRecord) error
+	SaveCalculations(records []*models.CalculationRecord) error
+	GetCalculations(filter models.CalculationFilter) ([]models.CalculationRecord, uint64, error)
+	GetCalculation(id uint64) (*models.CalculationRecord, error)
+	GetQuantityDistribution(filter models.CalculationFilter) ([]models.QuantityCount, error)
+}
+
+// SQLCalculationRepository is the struct that implements CalculationRepository interface for SQL database.
+type SQLCalculationRepository struct {
+	db *sql.DB
+}
+
+// NewSQLCalculationRepository initializes a new SQL-based calculation repository.
+func NewSQLCalculationRepository(db *sql.DB) CalculationRepository {
+	return &SQLCalculationRepository{db: db}
+}
+
+// calculationColumns lists the columns a calculation record is scanned from, in scan order.
+const calculationColumns = `id, quantity, strategy, catalog_version, shipping_rate, packs, items, overfill,
+	total_cost, latency_us, caller, created_at`
+
+// calculationFilterWhere selects the calculations matching a CalculationFilter, taking its from, to,
+// minimum and maximum quantity as the first four arguments.
+const calculationFilterWhere = `WHERE ($1::timestamptz IS NULL OR created_at >= $1) AND ($2::timestamptz IS NULL OR created_at <= $2)
+		AND quantity BETWEEN $3 AND $4`
+
+// SaveCalculation stores a calculation, setting the ID and creation time of the record.
+func (r *SQLCalculationRepository) SaveCalculation(record *models.CalculationRecord) error {
+	query := `INSERT INTO calculations (quantity, strategy, catalog_version, shipping_rate, packs, items, overfill,
+		total_cost, latency_us, caller) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at`
+
+	packs, err := json.Marshal(record.Packs)
+	if err != nil {
+		return err
+	}
+
+	return r.db.QueryRow(query, numeric(record.Quantity), record.Strategy, record.CatalogVersion, record.ShippingRate, packs,
+		numeric(record.Items), numeric(record.Overfill), record.TotalCost, record.LatencyMicros, record.Caller).
+		Scan(&record.ID, &record.CreatedAt)
+}
+
+// saveBatchSize is the number of calculation records inserted per statement by SaveCalculations,
+// keeping each statement well below the Postgres limit of 65535 parameters.
+const saveBatchSize = 1000
+
+// SaveCalculations stores many calculations in one transaction, setting the ID and creation time of
+// every record. Records are inserted in batches to keep the round-trips few.
+func (r *SQLCalculationRepository) SaveCalculations(records []*models.CalculationRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(records); start += saveBatchSize {
+		batch := records[start:min(start+saveBatchSize, len(records))]
+
+		values := make([]string, len(batch))
+		args := make([]any, 0, len(batch)*10)
+		for i, record := range batch {
+			packs, err := json.Marshal(record.Packs)
+			if err != nil {
+				return err
+			}
+
+			n := i * 10
+			values[i] = fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+				n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10)
+			args = append(args, numeric(record.Quantity), record.Strategy, record.CatalogVersion, record.ShippingRate, packs,
+				numeric(record.Items), numeric(record.Overfill), record.TotalCost, record.LatencyMicros, record.Caller)
+		}
+
+		query := `INSERT INTO calculations (quantity, strategy, catalog_version, shipping_rate, packs, items, overfill,
+		total_cost, latency_us, caller) VALUES ` + strings.Join(values, ", ") + ` RETURNING id, created_at`
+
+		rows, err := tx.Query(query, args...)
+		if err != nil {
+			return err
+		}
+
+		// Postgres returns the inserted rows in the order of the VALUES list.
+		i := 0
+		for rows.Next() {
+			if i == len(batch) {
+				rows.Close()
+				return fmt.Errorf("insert returned more than %d rows", len(batch))
+			}
+			if err := rows.Scan(&batch[i].ID, &batch[i].CreatedAt); err != nil {
+				rows.Close()
+				return err
+			}
+			i++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetCalculations retrieves a page of the calculations matching filter, newest first, with the
+// number of matching calculations across all pages.
+func (r *SQLCalculationRepository) GetCalculations(filter models.CalculationFilter) ([]models.CalculationRecord, uint64, error) {
+	from := sql.NullTime{Time: filter.From, Valid: !filter.From.IsZero()}
+	to := sql.NullTime{Time: filter.To, Valid: !filter.To.IsZero()}
+
+	var total uint64
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM calculations `+calculationFilterWhere, from, to, numeric(filter.MinQuantity), numeric(filter.MaxQuantity)).
+		Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Query(`SELECT `+calculationColumns+` FROM calculations `+calculationFilterWhere+
+		` ORDER BY id DESC LIMIT $5 OFFSET $6`,
+		from, to, numeric(filter.MinQuantity), numeric(filter.MaxQuantity), filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var records []models.CalculationRecord
+	for rows.Next() {
+		record, err := scanCalculation(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		records = append(records, *record)
+	}
+
+	return records, total, rows.Err()
+}
+
+// GetCalculation retrieves a calculation by its ID.
+func (r *SQLCalculationRepository) GetCalculation(id uint64) (*models.CalculationRecord, error) {
+	row := r.db.QueryRow(`SELECT `+calculationColumns+` FROM calculations WHERE id = $1`, id)
+
+	record, err := scanCalculation(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrCalculationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// GetQuantityDistribution counts the calculations matching filter per quantity, smallest quantity first.
+// The limit and offset of filter are ignored.
+func (r *SQLCalculationRepository) GetQuantityDistribution(filter models.CalculationFilter) ([]models.QuantityCount, error) {
+	from := sql.NullTime{Time: filter.From, Valid: !filter.From.IsZero()}
+	to := sql.NullTime{Time: filter.To, Valid: !filter.To.IsZero()}
+
+	rows, err := r.db.Query(`SELECT quantity, COUNT(*) FROM calculations `+calculationFilterWhere+
+		` GROUP BY quantity ORDER BY quantity`, from, to, numeric(filter.MinQuantity), numeric(filter.MaxQuantity))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var distribution []models.QuantityCount
+	for rows.Next() {
+		var count models.QuantityCount
+		if err := rows.Scan(&count.Quantity, &count.Count); err != nil {
+			return nil, err
+		}
+		distribution = append(distribution, count)
+	}
+
+	return distribution, rows.Err()
+}
+
+// scanCalculation reads a calculation record selected with calculationColumns.
+func scanCalculation(row interface{ Scan(dest ...any) error }) (*models.CalculationRecord, error) {
+	var record models.CalculationRecord
+	var packs []byte
+	err := row.Scan(&record.ID, &record.Quantity, &record.Strategy, &record.CatalogVersion, &record.ShippingRate,
+		&packs, &record.Items, &record.Overfill, &record.TotalCost, &record.LatencyMicros, &record.Caller, &record.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(packs, &record.Packs); err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
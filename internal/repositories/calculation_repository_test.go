@@ -0,0 +1,285 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveCalculation(t *testing.T) {
+	createdAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	query := `INSERT INTO calculations (quantity, strategy, catalog_version, shipping_rate, packs, items, overfill,
+		total_cost, latency_us, caller) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at`
+
+	tests := []struct {
+		name          string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedID    uint64
+		expectedError string
+	}{
+		{
+			name: "successful insert",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs("501", "optimal", 5, 0.5, []byte(`{"250":1,"500":1}`), "750", "249", 6.5, 120, "alice").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(42, createdAt))
+			},
+			expectedID: 42,
+		},
+		{
+			name: "insert failure",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(errors.New("insert failed"))
+			},
+			expectedError: "insert failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewSQLCalculationRepository(db)
+			tt.mockSetup(mock)
+
+			record := &models.CalculationRecord{
+				Quantity:       501,
+				Strategy:       "optimal",
+				CatalogVersion: 5,
+				ShippingRate:   0.5,
+				Packs:          map[uint32]uint64{500: 1, 250: 1},
+				Items:          750,
+				Overfill:       249,
+				TotalCost:      6.5,
+				LatencyMicros:  120,
+				Caller:         "alice",
+			}
+			err = repo.SaveCalculation(record)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedID, record.ID)
+				assert.Equal(t, createdAt, record.CreatedAt)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSaveCalculations(t *testing.T) {
+	createdAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	query := `INSERT INTO calculations (quantity, strategy, catalog_version, shipping_rate, packs, items, overfill,
+		total_cost, latency_us, caller) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10),
+		($11, $12, $13, $14, $15, $16, $17, $18, $19, $20) RETURNING id, created_at`
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(query)).
+		WithArgs("501", "optimal", 5, 0.0, []byte(`{"500":1}`), "500", "249", 4.0, 80, "alice",
+			"250", "optimal", 5, 0.0, []byte(`{"250":1}`), "250", "0", 2.5, 60, "alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(42, createdAt).AddRow(43, createdAt))
+	mock.ExpectCommit()
+
+	records := []*models.CalculationRecord{
+		{Quantity: 501, Strategy: "optimal", CatalogVersion: 5, Packs: map[uint32]uint64{500: 1}, Items: 500,
+			Overfill: 249, TotalCost: 4, LatencyMicros: 80, Caller: "alice"},
+		{Quantity: 250, Strategy: "optimal", CatalogVersion: 5, Packs: map[uint32]uint64{250: 1}, Items: 250,
+			TotalCost: 2.5, LatencyMicros: 60, Caller: "alice"},
+	}
+	err = NewSQLCalculationRepository(db).SaveCalculations(records)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), records[0].ID)
+	assert.Equal(t, uint64(43), records[1].ID)
+	assert.Equal(t, createdAt, records[1].CreatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCalculations(t *testing.T) {
+	createdAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	from := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	where := `WHERE ($1::timestamptz IS NULL OR created_at >= $1) AND ($2::timestamptz IS NULL OR created_at <= $2)
+		AND quantity BETWEEN $3 AND $4`
+	countQuery := `SELECT COUNT(*) FROM calculations ` + where
+	pageQuery := `SELECT id, quantity, strategy, catalog_version, shipping_rate, packs, items, overfill,
+	total_cost, latency_us, caller, created_at FROM calculations ` + where + ` ORDER BY id DESC LIMIT $5 OFFSET $6`
+	columns := []string{"id", "quantity", "strategy", "catalog_version", "shipping_rate", "packs", "items", "overfill",
+		"total_cost", "latency_us", "caller", "created_at"}
+	filter := models.CalculationFilter{From: from, MinQuantity: 100, MaxQuantity: math.MaxUint64, Limit: 2, Offset: 4}
+
+	tests := []struct {
+		name          string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expected      []models.CalculationRecord
+		expectedTotal uint64
+		expectedError string
+	}{
+		{
+			name: "page of calculations",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(countQuery)).
+					WithArgs(from, nil, "100", "18446744073709551615").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+				mock.ExpectQuery(regexp.QuoteMeta(pageQuery)).
+					WithArgs(from, nil, "100", "18446744073709551615", 2, 4).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(9, 251, "optimal", 5, 0.0, []byte(`{"500":1}`), 500, 249, 4.0, 80, "alice", createdAt))
+			},
+			expected: []models.CalculationRecord{{
+				ID: 9, Quantity: 251, Strategy: "optimal", CatalogVersion: 5, Packs: map[uint32]uint64{500: 1},
+				Items: 500, Overfill: 249, TotalCost: 4, LatencyMicros: 80, Caller: "alice", CreatedAt: createdAt,
+			}},
+			expectedTotal: 5,
+		},
+		{
+			name: "count failure",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(countQuery)).WillReturnError(errors.New("count failed"))
+			},
+			expectedError: "count failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewSQLCalculationRepository(db)
+			tt.mockSetup(mock)
+
+			records, total, err := repo.GetCalculations(filter)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, records)
+				assert.Equal(t, tt.expectedTotal, total)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetQuantityDistribution(t *testing.T) {
+	to := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	query := `SELECT quantity, COUNT(*) FROM calculations WHERE ($1::timestamptz IS NULL OR created_at >= $1) AND ($2::timestamptz IS NULL OR created_at <= $2)
+		AND quantity BETWEEN $3 AND $4 GROUP BY quantity ORDER BY quantity`
+	filter := models.CalculationFilter{To: to, MinQuantity: 1, MaxQuantity: math.MaxUint64}
+
+	tests := []struct {
+		name          string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expected      []models.QuantityCount
+		expectedError string
+	}{
+		{
+			name: "quantities counted",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(nil, to, "1", "18446744073709551615").
+					WillReturnRows(sqlmock.NewRows([]string{"quantity", "count"}).AddRow(250, 3).AddRow(1200, 1))
+			},
+			expected: []models.QuantityCount{{Quantity: 250, Count: 3}, {Quantity: 1200, Count: 1}},
+		},
+		{
+			name: "query failure",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(errors.New("query failed"))
+			},
+			expectedError: "query failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewSQLCalculationRepository(db)
+			tt.mockSetup(mock)
+
+			distribution, err := repo.GetQuantityDistribution(filter)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, distribution)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetCalculation(t *testing.T) {
+	createdAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	query := `SELECT id, quantity, strategy, catalog_version, shipping_rate, packs, items, overfill,
+	total_cost, latency_us, caller, created_at FROM calculations WHERE id = $1`
+
+	tests := []struct {
+		name        string
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expected    *models.CalculationRecord
+		expectedErr error
+	}{
+		{
+			name: "calculation found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(9).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "quantity", "strategy", "catalog_version", "shipping_rate",
+						"packs", "items", "overfill", "total_cost", "latency_us", "caller", "created_at"}).
+						AddRow(9, 251, "optimal", 5, 0.0, []byte(`{"500":1}`), 500, 249, 4.0, 80, "alice", createdAt))
+			},
+			expected: &models.CalculationRecord{
+				ID: 9, Quantity: 251, Strategy: "optimal", CatalogVersion: 5, Packs: map[uint32]uint64{500: 1},
+				Items: 500, Overfill: 249, TotalCost: 4, LatencyMicros: 80, Caller: "alice", CreatedAt: createdAt,
+			},
+		},
+		{
+			name: "calculation not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(9).WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: models.ErrCalculationNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewSQLCalculationRepository(db)
+			tt.mockSetup(mock)
+
+			record, err := repo.GetCalculation(9)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, record)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 
 	"database/sql"
 	_ "github.com/lib/pq"
@@ -32,3 +33,9 @@ func InitAndCloseDB() (*sql.DB, func(), error) {
 
 	return db, cleanup, nil
 }
+
+// numeric passes an unsigned 64-bit value to a NUMERIC column as decimal text, since database/sql
+// rejects uint64 values above math.MaxInt64.
+func numeric(n uint64) string {
+	return strconv.FormatUint(n, 10)
+}
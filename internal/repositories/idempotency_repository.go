@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/klemis/packs-calculator/models"
+)
+
+// IdempotencyRepository defines the interface for remembering the requests made with idempotency keys.
+type IdempotencyRepository interface {
+	ReserveKey(key, requestHash string) (*models.IdempotencyRecord, error)
+	SaveResponse(key string, response models.StoredResponse) error
+	ReleaseKey(key string) error
+}
+
+// SQLIdempotencyRepository is the struct that implements IdempotencyRepository interface for SQL database.
+type SQLIdempotencyRepository struct {
+	db *sql.DB
+}
+
+// NewSQLIdempotencyRepository initializes a new SQL-based idempotency key repository.
+func NewSQLIdempotencyRepository(db *sql.DB) IdempotencyRepository {
+	return &SQLIdempotencyRepository{db: db}
+}
+
+// ReserveKey claims an idempotency key for a request. It returns nil once the key is claimed, or the
+// record of the earlier request when the key is already in use. Keys expire after a day, after which
+// they can be claimed again.
+func (r *SQLIdempotencyRepository) ReserveKey(key, requestHash string) (*models.IdempotencyRecord, error) {
+	reserveQuery := `INSERT INTO idempotency_keys (key, request_hash) VALUES ($1, $2)
+		ON CONFLICT (key) DO UPDATE SET request_hash = EXCLUDED.request_hash, status_code = NULL,
+		content_type = NULL, body = NULL, created_at = NOW()
+		WHERE idempotency_keys.created_at < NOW() - INTERVAL '24 hours'`
+
+	result, err := r.db.Exec(reserveQuery, key, requestHash)
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check affected rows: %s", err)
+	}
+
+	if rowsAffected == 1 {
+		return nil, nil
+	}
+
+	query := `SELECT request_hash, status_code, content_type, body FROM idempotency_keys WHERE key = $1`
+
+	record := &models.IdempotencyRecord{Key: key}
+	var status sql.NullInt64
+	var contentType sql.NullString
+	var body []byte
+	if err := r.db.QueryRow(query, key).Scan(&record.RequestHash, &status, &contentType, &body); err != nil {
+		return nil, err
+	}
+
+	if status.Valid {
+		record.Response = &models.StoredResponse{
+			Status:      int(status.Int64),
+			ContentType: contentType.String,
+			Body:        body,
+		}
+	}
+
+	return record, nil
+}
+
+// SaveResponse stores the response to the request that claimed an idempotency key.
+func (r *SQLIdempotencyRepository) SaveResponse(key string, response models.StoredResponse) error {
+	query := `UPDATE idempotency_keys SET status_code = $2, content_type = $3, body = $4 WHERE key = $1`
+
+	_, err := r.db.Exec(query, key, response.Status, response.ContentType, response.Body)
+
+	return err
+}
+
+// ReleaseKey frees an idempotency key whose request failed, so that it can be retried.
+func (r *SQLIdempotencyRepository) ReleaseKey(key string) error {
+	query := `DELETE FROM idempotency_keys WHERE key = $1`
+
+	_, err := r.db.Exec(query, key)
+
+	return err
+}
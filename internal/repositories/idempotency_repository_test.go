@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReserveKey(t *testing.T) {
+	reserveQuery := `INSERT INTO idempotency_keys (key, request_hash) VALUES ($1, $2)
+		ON CONFLICT (key) DO UPDATE SET request_hash = EXCLUDED.request_hash, status_code = NULL,
+		content_type = NULL, body = NULL, created_at = NOW()
+		WHERE idempotency_keys.created_at < NOW() - INTERVAL '24 hours'`
+	selectQuery := `SELECT request_hash, status_code, content_type, body FROM idempotency_keys WHERE key = $1`
+
+	tests := []struct {
+		name      string
+		mockSetup func(mock sqlmock.Sqlmock)
+		expected  *models.IdempotencyRecord
+	}{
+		{
+			name: "key claimed",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(reserveQuery)).
+					WithArgs("order-7", "abc").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "key in progress",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(reserveQuery)).
+					WithArgs("order-7", "abc").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta(selectQuery)).
+					WithArgs("order-7").
+					WillReturnRows(sqlmock.NewRows([]string{"request_hash", "status_code", "content_type", "body"}).
+						AddRow("abc", nil, nil, nil))
+			},
+			expected: &models.IdempotencyRecord{Key: "order-7", RequestHash: "abc"},
+		},
+		{
+			name: "key already handled",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(reserveQuery)).
+					WithArgs("order-7", "abc").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta(selectQuery)).
+					WithArgs("order-7").
+					WillReturnRows(sqlmock.NewRows([]string{"request_hash", "status_code", "content_type", "body"}).
+						AddRow("abc", 201, "application/json", []byte(`{"id":3}`)))
+			},
+			expected: &models.IdempotencyRecord{
+				Key:         "order-7",
+				RequestHash: "abc",
+				Response:    &models.StoredResponse{Status: 201, ContentType: "application/json", Body: []byte(`{"id":3}`)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewSQLIdempotencyRepository(db)
+			tt.mockSetup(mock)
+
+			record, err := repo.ReserveKey("order-7", "abc")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, record)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSaveResponse(t *testing.T) {
+	query := `UPDATE idempotency_keys SET status_code = $2, content_type = $3, body = $4 WHERE key = $1`
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(query)).
+		WithArgs("order-7", 201, "application/json", []byte(`{"id":3}`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = NewSQLIdempotencyRepository(db).SaveResponse("order-7",
+		models.StoredResponse{Status: 201, ContentType: "application/json", Body: []byte(`{"id":3}`)})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,4 @@
+ALTER TABLE pack_sizes
+    DROP COLUMN IF EXISTS handling_cost,
+    DROP COLUMN IF EXISTS weight,
+    DROP COLUMN IF EXISTS price;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE pack_sizes
+    ADD COLUMN IF NOT EXISTS price NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS weight NUMERIC(12, 3) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS handling_cost NUMERIC(12, 2) NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS pack_stock;
\ No newline at end of file
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS pack_stock (
+    pack_size_id INTEGER PRIMARY KEY REFERENCES pack_sizes (id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS catalog_version_packs;
+DROP TABLE IF EXISTS catalog_versions;
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS catalog_versions (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS catalog_version_packs (
+    version_id INTEGER NOT NULL REFERENCES catalog_versions (id) ON DELETE CASCADE,
+    pack_size_id INTEGER NOT NULL,
+    size INTEGER NOT NULL,
+    price NUMERIC(12, 2) NOT NULL,
+    weight NUMERIC(12, 3) NOT NULL,
+    handling_cost NUMERIC(12, 2) NOT NULL,
+    PRIMARY KEY (version_id, size)
+);
+
+-- The catalog as it stands becomes the first version.
+WITH version AS (INSERT INTO catalog_versions DEFAULT VALUES RETURNING id)
+INSERT INTO catalog_version_packs (version_id, pack_size_id, size, price, weight, handling_cost)
+SELECT version.id, p.id, p.size, p.price, p.weight, p.handling_cost FROM version, pack_sizes p;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS calculations;
\ No newline at end of file
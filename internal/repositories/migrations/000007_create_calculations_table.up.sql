@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS calculations (
+    id BIGSERIAL PRIMARY KEY,
+    quantity BIGINT NOT NULL,
+    strategy TEXT NOT NULL,
+    catalog_version INTEGER NOT NULL REFERENCES catalog_versions (id),
+    shipping_rate DOUBLE PRECISION NOT NULL,
+    packs JSONB NOT NULL,
+    items BIGINT NOT NULL,
+    overfill BIGINT NOT NULL,
+    total_cost DOUBLE PRECISION NOT NULL,
+    latency_us BIGINT NOT NULL,
+    caller TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS calculations_created_at_idx ON calculations (created_at);
+CREATE INDEX IF NOT EXISTS calculations_quantity_idx ON calculations (quantity);
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS orders (
+    id BIGSERIAL PRIMARY KEY,
+    status TEXT NOT NULL CHECK (status IN ('quoted', 'confirmed', 'packed', 'shipped', 'cancelled')),
+    quantity BIGINT NOT NULL,
+    calculation_id BIGINT NOT NULL REFERENCES calculations (id),
+    catalog_version INTEGER NOT NULL REFERENCES catalog_versions (id),
+    strategy TEXT NOT NULL,
+    packs JSONB NOT NULL,
+    total_cost DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS orders_status_idx ON orders (status);
\ No newline at end of file
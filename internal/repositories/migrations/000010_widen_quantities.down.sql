@@ -0,0 +1,6 @@
+ALTER TABLE orders ALTER COLUMN quantity TYPE BIGINT;
+
+ALTER TABLE calculations
+    ALTER COLUMN quantity TYPE BIGINT,
+    ALTER COLUMN items TYPE BIGINT,
+    ALTER COLUMN overfill TYPE BIGINT;
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE calculations
+    ALTER COLUMN quantity TYPE NUMERIC(20, 0),
+    ALTER COLUMN items TYPE NUMERIC(20, 0),
+    ALTER COLUMN overfill TYPE NUMERIC(20, 0);
+
+ALTER TABLE orders ALTER COLUMN quantity TYPE NUMERIC(20, 0);
\ No newline at end of file
@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repositories/calculation_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/klemis/packs-calculator/models"
+)
+
+// MockCalculationRepository is a mock of CalculationRepository interface.
+type MockCalculationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCalculationRepositoryMockRecorder
+}
+
+// MockCalculationRepositoryMockRecorder is the mock recorder for MockCalculationRepository.
+type MockCalculationRepositoryMockRecorder struct {
+	mock *MockCalculationRepository
+}
+
+// NewMockCalculationRepository creates a new mock instance.
+func NewMockCalculationRepository(ctrl *gomock.Controller) *MockCalculationRepository {
+	mock := &MockCalculationRepository{ctrl: ctrl}
+	mock.recorder = &MockCalculationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCalculationRepository) EXPECT() *MockCalculationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetCalculation mocks base method.
+func (m *MockCalculationRepository) GetCalculation(id uint64) (*models.CalculationRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCalculation", id)
+	ret0, _ := ret[0].(*models.CalculationRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCalculation indicates an expected call of GetCalculation.
+func (mr *MockCalculationRepositoryMockRecorder) GetCalculation(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCalculation", reflect.TypeOf((*MockCalculationRepository)(nil).GetCalculation), id)
+}
+
+// GetCalculations mocks base method.
+func (m *MockCalculationRepository) GetCalculations(filter models.CalculationFilter) ([]models.CalculationRecord, uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCalculations", filter)
+	ret0, _ := ret[0].([]models.CalculationRecord)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetCalculations indicates an expected call of GetCalculations.
+func (mr *MockCalculationRepositoryMockRecorder) GetCalculations(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCalculations", reflect.TypeOf((*MockCalculationRepository)(nil).GetCalculations), filter)
+}
+
+// GetQuantityDistribution mocks base method.
+func (m *MockCalculationRepository) GetQuantityDistribution(filter models.CalculationFilter) ([]models.QuantityCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuantityDistribution", filter)
+	ret0, _ := ret[0].([]models.QuantityCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuantityDistribution indicates an expected call of GetQuantityDistribution.
+func (mr *MockCalculationRepositoryMockRecorder) GetQuantityDistribution(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuantityDistribution", reflect.TypeOf((*MockCalculationRepository)(nil).GetQuantityDistribution), filter)
+}
+
+// SaveCalculation mocks base method.
+func (m *MockCalculationRepository) SaveCalculation(record *models.CalculationRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCalculation", record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCalculation indicates an expected call of SaveCalculation.
+func (mr *MockCalculationRepositoryMockRecorder) SaveCalculation(record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCalculation", reflect.TypeOf((*MockCalculationRepository)(nil).SaveCalculation), record)
+}
+
+// SaveCalculations mocks base method.
+func (m *MockCalculationRepository) SaveCalculations(records []*models.CalculationRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCalculations", records)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCalculations indicates an expected call of SaveCalculations.
+func (mr *MockCalculationRepositoryMockRecorder) SaveCalculations(records interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCalculations", reflect.TypeOf((*MockCalculationRepository)(nil).SaveCalculations), records)
+}
@@ -35,31 +35,61 @@ func (m *MockPackSizeRepository) EXPECT() *MockPackSizeRepositoryMockRecorder {
 }
 
 // CreatePackSize mocks base method.
-func (m *MockPackSizeRepository) CreatePackSize(size uint32) error {
+func (m *MockPackSizeRepository) CreatePackSize(pack models.PackSize, actor string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreatePackSize", size)
+	ret := m.ctrl.Call(m, "CreatePackSize", pack, actor)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreatePackSize indicates an expected call of CreatePackSize.
-func (mr *MockPackSizeRepositoryMockRecorder) CreatePackSize(size interface{}) *gomock.Call {
+func (mr *MockPackSizeRepositoryMockRecorder) CreatePackSize(pack, actor interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePackSize", reflect.TypeOf((*MockPackSizeRepository)(nil).CreatePackSize), size)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePackSize", reflect.TypeOf((*MockPackSizeRepository)(nil).CreatePackSize), pack, actor)
 }
 
 // DeletePackSize mocks base method.
-func (m *MockPackSizeRepository) DeletePackSize(size uint32) error {
+func (m *MockPackSizeRepository) DeletePackSize(size uint32, actor string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeletePackSize", size)
+	ret := m.ctrl.Call(m, "DeletePackSize", size, actor)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeletePackSize indicates an expected call of DeletePackSize.
-func (mr *MockPackSizeRepositoryMockRecorder) DeletePackSize(size interface{}) *gomock.Call {
+func (mr *MockPackSizeRepositoryMockRecorder) DeletePackSize(size, actor interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePackSize", reflect.TypeOf((*MockPackSizeRepository)(nil).DeletePackSize), size)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePackSize", reflect.TypeOf((*MockPackSizeRepository)(nil).DeletePackSize), size, actor)
+}
+
+// GetCatalogVersion mocks base method.
+func (m *MockPackSizeRepository) GetCatalogVersion(id uint32) (*models.CatalogVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCatalogVersion", id)
+	ret0, _ := ret[0].(*models.CatalogVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCatalogVersion indicates an expected call of GetCatalogVersion.
+func (mr *MockPackSizeRepositoryMockRecorder) GetCatalogVersion(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCatalogVersion", reflect.TypeOf((*MockPackSizeRepository)(nil).GetCatalogVersion), id)
+}
+
+// GetPackSize mocks base method.
+func (m *MockPackSizeRepository) GetPackSize(id uint32) (*models.PackSize, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPackSize", id)
+	ret0, _ := ret[0].(*models.PackSize)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPackSize indicates an expected call of GetPackSize.
+func (mr *MockPackSizeRepositoryMockRecorder) GetPackSize(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPackSize", reflect.TypeOf((*MockPackSizeRepository)(nil).GetPackSize), id)
 }
 
 // GetPackSizes mocks base method.
@@ -76,3 +106,46 @@ func (mr *MockPackSizeRepositoryMockRecorder) GetPackSizes() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPackSizes", reflect.TypeOf((*MockPackSizeRepository)(nil).GetPackSizes))
 }
+
+// MergePackSizes mocks base method.
+func (m *MockPackSizeRepository) MergePackSizes(packs []models.PackSize, actor string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergePackSizes", packs, actor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergePackSizes indicates an expected call of MergePackSizes.
+func (mr *MockPackSizeRepositoryMockRecorder) MergePackSizes(packs, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergePackSizes", reflect.TypeOf((*MockPackSizeRepository)(nil).MergePackSizes), packs, actor)
+}
+
+// ReplacePackSizes mocks base method.
+func (m *MockPackSizeRepository) ReplacePackSizes(packs []models.PackSize, actor string) (*models.CatalogChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplacePackSizes", packs, actor)
+	ret0, _ := ret[0].(*models.CatalogChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplacePackSizes indicates an expected call of ReplacePackSizes.
+func (mr *MockPackSizeRepositoryMockRecorder) ReplacePackSizes(packs, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplacePackSizes", reflect.TypeOf((*MockPackSizeRepository)(nil).ReplacePackSizes), packs, actor)
+}
+
+// UpdatePackSize mocks base method.
+func (m *MockPackSizeRepository) UpdatePackSize(pack models.PackSize, actor string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePackSize", pack, actor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePackSize indicates an expected call of UpdatePackSize.
+func (mr *MockPackSizeRepositoryMockRecorder) UpdatePackSize(pack, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePackSize", reflect.TypeOf((*MockPackSizeRepository)(nil).UpdatePackSize), pack, actor)
+}
@@ -0,0 +1,93 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repositories/stock_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/klemis/packs-calculator/models"
+)
+
+// MockStockRepository is a mock of StockRepository interface.
+type MockStockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockStockRepositoryMockRecorder
+}
+
+// MockStockRepositoryMockRecorder is the mock recorder for MockStockRepository.
+type MockStockRepositoryMockRecorder struct {
+	mock *MockStockRepository
+}
+
+// NewMockStockRepository creates a new mock instance.
+func NewMockStockRepository(ctrl *gomock.Controller) *MockStockRepository {
+	mock := &MockStockRepository{ctrl: ctrl}
+	mock.recorder = &MockStockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStockRepository) EXPECT() *MockStockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AdjustStock mocks base method.
+func (m *MockStockRepository) AdjustStock(size uint32, delta int64) (uint32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustStock", size, delta)
+	ret0, _ := ret[0].(uint32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustStock indicates an expected call of AdjustStock.
+func (mr *MockStockRepositoryMockRecorder) AdjustStock(size, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustStock", reflect.TypeOf((*MockStockRepository)(nil).AdjustStock), size, delta)
+}
+
+// ClearStock mocks base method.
+func (m *MockStockRepository) ClearStock(size uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearStock", size)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearStock indicates an expected call of ClearStock.
+func (mr *MockStockRepositoryMockRecorder) ClearStock(size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearStock", reflect.TypeOf((*MockStockRepository)(nil).ClearStock), size)
+}
+
+// GetStock mocks base method.
+func (m *MockStockRepository) GetStock() ([]models.PackStock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStock")
+	ret0, _ := ret[0].([]models.PackStock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStock indicates an expected call of GetStock.
+func (mr *MockStockRepositoryMockRecorder) GetStock() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStock", reflect.TypeOf((*MockStockRepository)(nil).GetStock))
+}
+
+// SetStock mocks base method.
+func (m *MockStockRepository) SetStock(size, quantity uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStock", size, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetStock indicates an expected call of SetStock.
+func (mr *MockStockRepositoryMockRecorder) SetStock(size, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStock", reflect.TypeOf((*MockStockRepository)(nil).SetStock), size, quantity)
+}
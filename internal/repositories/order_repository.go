@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/klemis/packs-calculator/models"
+)
+
+// OrderRepository defines the interface for storing orders and their lifecycle status.
+type OrderRepository interface {
+	CreateOrder(order *models.Order) error
+	GetOrders(status models.OrderStatus) ([]models.Order, error)
+	GetOrder(id uint64) (*models.Order, error)
+	UpdateOrderStatus(id uint64, from, to models.OrderStatus) (*models.Order, error)
+}
+
+// SQLOrderRepository is the struct that implements OrderRepository interface for SQL database.
+type SQLOrderRepository struct {
+	db *sql.DB
+}
+
+// NewSQLOrderRepository initializes a new SQL-based order repository.
+func NewSQLOrderRepository(db *sql.DB) OrderRepository {
+	return &SQLOrderRepository{db: db}
+}
+
+// orderColumns lists the columns an order is scanned from, in scan order.
+const orderColumns = `id, status, quantity, calculation_id, catalog_version, strategy, packs, total_cost,
+	created_at, updated_at`
+
+// CreateOrder stores a new order, setting its ID and timestamps.
+func (r *SQLOrderRepository) CreateOrder(order *models.Order) error {
+	query := `INSERT INTO orders (status, quantity, calculation_id, catalog_version, strategy, packs, total_cost)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at, updated_at`
+
+	packs, err := json.Marshal(order.Packs)
+	if err != nil {
+		return err
+	}
+
+	return r.db.QueryRow(query, order.Status, numeric(order.Quantity), order.CalculationID, order.CatalogVersion,
+		order.Strategy, packs, order.TotalCost).
+		Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt)
+}
+
+// GetOrders retrieves the orders in the given status, newest first. An empty status matches every order.
+func (r *SQLOrderRepository) GetOrders(status models.OrderStatus) ([]models.Order, error) {
+	rows, err := r.db.Query(`SELECT `+orderColumns+` FROM orders WHERE ($1 = '' OR status = $1) ORDER BY id DESC`,
+		status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []models.Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *order)
+	}
+
+	return orders, rows.Err()
+}
+
+// GetOrder retrieves an order by its ID.
+func (r *SQLOrderRepository) GetOrder(id uint64) (*models.Order, error) {
+	row := r.db.QueryRow(`SELECT `+orderColumns+` FROM orders WHERE id = $1`, id)
+
+	order, err := scanOrder(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// UpdateOrderStatus moves an order from one status to another and returns the updated order.
+// The update only applies while the order is still in the from status, so concurrent transitions
+// of the same order cannot both succeed. Shipping an order takes its packs off the tracked stock in
+// the same transaction, failing when a size does not have enough on hand.
+func (r *SQLOrderRepository) UpdateOrderStatus(id uint64, from, to models.OrderStatus) (*models.Order, error) {
+	query := `UPDATE orders SET status = $3, updated_at = NOW() WHERE id = $1 AND status = $2
+		RETURNING ` + orderColumns
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	order, err := scanOrder(tx.QueryRow(query, id, from, to))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrInvalidOrderTransition
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if to == models.OrderShipped {
+		if err := takeStock(tx, order.Packs); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// takeStock removes the packs of a shipped order from the tracked stock, smallest size first. Sizes
+// whose stock is not tracked have an unlimited supply and are left alone.
+func takeStock(tx *sql.Tx, packs map[uint32]uint64) error {
+	take := `UPDATE pack_stock s SET quantity = s.quantity - $2::numeric, updated_at = NOW()
+		FROM pack_sizes p WHERE p.id = s.pack_size_id AND p.size = $1 AND s.quantity >= $2::numeric`
+
+	for _, size := range slices.Sorted(maps.Keys(packs)) {
+		count := packs[size]
+		result, err := tx.Exec(take, size, numeric(count))
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected > 0 {
+			continue
+		}
+
+		var onHand uint32
+		err = tx.QueryRow(`SELECT s.quantity FROM pack_stock s JOIN pack_sizes p ON p.id = s.pack_size_id
+			WHERE p.size = $1`, size).Scan(&onHand)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		return fmt.Errorf("%w: %d packs of %d needed, %d on hand", models.ErrNotEnoughStock, count, size, onHand)
+	}
+
+	return nil
+}
+
+// scanOrder reads an order selected with orderColumns.
+func scanOrder(row interface{ Scan(dest ...any) error }) (*models.Order, error) {
+	var order models.Order
+	var packs []byte
+	err := row.Scan(&order.ID, &order.Status, &order.Quantity, &order.CalculationID, &order.CatalogVersion,
+		&order.Strategy, &packs, &order.TotalCost, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(packs, &order.Packs); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
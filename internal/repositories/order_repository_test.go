@@ -0,0 +1,224 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var orderColumnNames = []string{"id", "status", "quantity", "calculation_id", "catalog_version", "strategy", "packs",
+	"total_cost", "created_at", "updated_at"}
+
+func TestCreateOrder(t *testing.T) {
+	createdAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	query := `INSERT INTO orders (status, quantity, calculation_id, catalog_version, strategy, packs, total_cost)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at, updated_at`
+
+	tests := []struct {
+		name          string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name: "successful insert",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs("quoted", "501", 9, 5, "optimal", []byte(`{"250":1,"500":1}`), 6.5).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).
+						AddRow(3, createdAt, createdAt))
+			},
+		},
+		{
+			name: "insert failure",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(errors.New("insert failed"))
+			},
+			expectedError: "insert failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewSQLOrderRepository(db)
+			tt.mockSetup(mock)
+
+			order := &models.Order{
+				Status:         models.OrderQuoted,
+				Quantity:       501,
+				CalculationID:  9,
+				CatalogVersion: 5,
+				Strategy:       "optimal",
+				Packs:          map[uint32]uint64{500: 1, 250: 1},
+				TotalCost:      6.5,
+			}
+			err = repo.CreateOrder(order)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint64(3), order.ID)
+				assert.Equal(t, createdAt, order.CreatedAt)
+				assert.Equal(t, createdAt, order.UpdatedAt)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetOrders(t *testing.T) {
+	createdAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	query := `SELECT id, status, quantity, calculation_id, catalog_version, strategy, packs, total_cost,
+	created_at, updated_at FROM orders WHERE ($1 = '' OR status = $1) ORDER BY id DESC`
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(query)).
+		WithArgs("confirmed").
+		WillReturnRows(sqlmock.NewRows(orderColumnNames).
+			AddRow(3, "confirmed", 501, 9, 5, "optimal", []byte(`{"250":1,"500":1}`), 6.5, createdAt, createdAt))
+
+	orders, err := NewSQLOrderRepository(db).GetOrders(models.OrderConfirmed)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Order{{
+		ID: 3, Status: models.OrderConfirmed, Quantity: 501, CalculationID: 9, CatalogVersion: 5, Strategy: "optimal",
+		Packs: map[uint32]uint64{500: 1, 250: 1}, TotalCost: 6.5, CreatedAt: createdAt, UpdatedAt: createdAt,
+	}}, orders)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOrder(t *testing.T) {
+	query := `SELECT id, status, quantity, calculation_id, catalog_version, strategy, packs, total_cost,
+	created_at, updated_at FROM orders WHERE id = $1`
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(3).WillReturnError(sql.ErrNoRows)
+
+	_, err = NewSQLOrderRepository(db).GetOrder(3)
+	assert.ErrorIs(t, err, models.ErrOrderNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateOrderStatus(t *testing.T) {
+	createdAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	updatedAt := time.Date(2024, 10, 2, 8, 0, 0, 0, time.UTC)
+	query := `UPDATE orders SET status = $3, updated_at = NOW() WHERE id = $1 AND status = $2
+		RETURNING id, status, quantity, calculation_id, catalog_version, strategy, packs, total_cost,
+	created_at, updated_at`
+	takeQuery := `UPDATE pack_stock s SET quantity = s.quantity - $2::numeric, updated_at = NOW()
+		FROM pack_sizes p WHERE p.id = s.pack_size_id AND p.size = $1 AND s.quantity >= $2::numeric`
+	onHandQuery := `SELECT s.quantity FROM pack_stock s JOIN pack_sizes p ON p.id = s.pack_size_id
+			WHERE p.size = $1`
+
+	tests := []struct {
+		name        string
+		from, to    models.OrderStatus
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expected    *models.Order
+		expectedErr error
+	}{
+		{
+			name: "order moved",
+			from: models.OrderQuoted,
+			to:   models.OrderConfirmed,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(3, "quoted", "confirmed").
+					WillReturnRows(sqlmock.NewRows(orderColumnNames).
+						AddRow(3, "confirmed", 501, 9, 5, "optimal", []byte(`{"500":1}`), 4.0, createdAt, updatedAt))
+				mock.ExpectCommit()
+			},
+			expected: &models.Order{
+				ID: 3, Status: models.OrderConfirmed, Quantity: 501, CalculationID: 9, CatalogVersion: 5,
+				Strategy: "optimal", Packs: map[uint32]uint64{500: 1}, TotalCost: 4, CreatedAt: createdAt, UpdatedAt: updatedAt,
+			},
+		},
+		{
+			name: "order shipped from tracked and untracked stock",
+			from: models.OrderPacked,
+			to:   models.OrderShipped,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(3, "packed", "shipped").
+					WillReturnRows(sqlmock.NewRows(orderColumnNames).
+						AddRow(3, "shipped", 751, 9, 5, "optimal", []byte(`{"250":1,"500":1}`), 6.5, createdAt, updatedAt))
+				mock.ExpectExec(regexp.QuoteMeta(takeQuery)).WithArgs(250, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(takeQuery)).WithArgs(500, "1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta(onHandQuery)).WithArgs(500).WillReturnError(sql.ErrNoRows)
+				mock.ExpectCommit()
+			},
+			expected: &models.Order{
+				ID: 3, Status: models.OrderShipped, Quantity: 751, CalculationID: 9, CatalogVersion: 5,
+				Strategy: "optimal", Packs: map[uint32]uint64{250: 1, 500: 1}, TotalCost: 6.5, CreatedAt: createdAt, UpdatedAt: updatedAt,
+			},
+		},
+		{
+			name: "not enough stock to ship",
+			from: models.OrderPacked,
+			to:   models.OrderShipped,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(3, "packed", "shipped").
+					WillReturnRows(sqlmock.NewRows(orderColumnNames).
+						AddRow(3, "shipped", 1000, 9, 5, "optimal", []byte(`{"500":2}`), 8.0, createdAt, updatedAt))
+				mock.ExpectExec(regexp.QuoteMeta(takeQuery)).WithArgs(500, "2").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta(onHandQuery)).WithArgs(500).
+					WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			expectedErr: models.ErrNotEnoughStock,
+		},
+		{
+			name: "order no longer in the from status",
+			from: models.OrderQuoted,
+			to:   models.OrderConfirmed,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(3, "quoted", "confirmed").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedErr: models.ErrInvalidOrderTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewSQLOrderRepository(db)
+			tt.mockSetup(mock)
+
+			order, err := repo.UpdateOrderStatus(3, tt.from, tt.to)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, order)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
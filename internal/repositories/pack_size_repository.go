@@ -2,16 +2,32 @@ package repositories
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/klemis/packs-calculator/models"
+	"github.com/lib/pq"
 )
 
-// PackSizeRepository defines the interface for creating and deleting pack sizes.
+// uniqueViolation is the Postgres error code for a unique constraint violation.
+const uniqueViolation = "23505"
+
+// upsertPackSizeQuery adds a pack size, or overwrites the pricing metadata of an existing one.
+const upsertPackSizeQuery = `INSERT INTO pack_sizes (size, price, weight, handling_cost) VALUES ($1, $2, $3, $4)
+	ON CONFLICT (size) DO UPDATE SET price = EXCLUDED.price, weight = EXCLUDED.weight, handling_cost = EXCLUDED.handling_cost`
+
+// PackSizeRepository defines the interface for creating, reading, updating and deleting pack sizes.
+// Every change is made on behalf of an actor and recorded in the audit log.
 type PackSizeRepository interface {
-	CreatePackSize(size uint32) error
-	DeletePackSize(size uint32) error
+	CreatePackSize(pack models.PackSize, actor string) error
+	DeletePackSize(size uint32, actor string) error
 	GetPackSizes() ([]models.PackSize, error)
+	GetPackSize(id uint32) (*models.PackSize, error)
+	UpdatePackSize(pack models.PackSize, actor string) error
+	ReplacePackSizes(packs []models.PackSize, actor string) (*models.CatalogChange, error)
+	MergePackSizes(packs []models.PackSize, actor string) error
+	GetCatalogVersion(id uint32) (*models.CatalogVersion, error)
 }
 
 // SQLPackSizeRepository is the struct that implements PackSizeRepository interface for SQL database.
@@ -26,7 +42,7 @@ func NewSQLPackSizeRepository(db *sql.DB) PackSizeRepository {
 
 // GetPackSizes retrieves all pack sizes from the database.
 func (r *SQLPackSizeRepository) GetPackSizes() ([]models.PackSize, error) {
-	rows, err := r.db.Query(`SELECT id, size FROM pack_sizes ORDER BY size DESC`)
+	rows, err := r.db.Query(`SELECT id, size, price, weight, handling_cost FROM pack_sizes ORDER BY size DESC`)
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +51,7 @@ func (r *SQLPackSizeRepository) GetPackSizes() ([]models.PackSize, error) {
 	var packSizes []models.PackSize
 	for rows.Next() {
 		var pack models.PackSize
-		if err := rows.Scan(&pack.ID, &pack.Size); err != nil {
+		if err := rows.Scan(&pack.ID, &pack.Size, &pack.Price, &pack.Weight, &pack.HandlingCost); err != nil {
 			return nil, err
 		}
 		packSizes = append(packSizes, pack)
@@ -44,46 +60,249 @@ func (r *SQLPackSizeRepository) GetPackSizes() ([]models.PackSize, error) {
 	return packSizes, nil
 }
 
-// CreatePackSize inserts a new pack size into the database.
-func (r *SQLPackSizeRepository) CreatePackSize(size uint32) error {
-	query := `INSERT INTO pack_sizes (size) VALUES ($1) ON CONFLICT DO NOTHING`
+// GetPackSize retrieves a pack size by its ID.
+func (r *SQLPackSizeRepository) GetPackSize(id uint32) (*models.PackSize, error) {
+	query := `SELECT id, size, price, weight, handling_cost FROM pack_sizes WHERE id = $1`
 
-	result, err := r.db.Exec(query, size)
-	if err != nil {
-		return err
+	var pack models.PackSize
+	err := r.db.QueryRow(query, id).Scan(&pack.ID, &pack.Size, &pack.Price, &pack.Weight, &pack.HandlingCost)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrPackSizeNotFound
 	}
-
-	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to check affected rows: %s", err)
+		return nil, err
 	}
 
-	// Determine if the size was added or already exists.
-	if rowsAffected == 0 {
-		return fmt.Errorf("no rows were affected")
-	}
+	return &pack, nil
+}
+
+// UpdatePackSize overwrites the size and pricing metadata of an existing pack size by its ID.
+func (r *SQLPackSizeRepository) UpdatePackSize(pack models.PackSize, actor string) error {
+	query := `UPDATE pack_sizes SET size = $2, price = $3, weight = $4, handling_cost = $5 WHERE id = $1`
+
+	return r.withCatalogChange(models.AuditActionUpdate, actor, func(tx *sql.Tx) error {
+		result, err := tx.Exec(query, pack.ID, pack.Size, pack.Price, pack.Weight, pack.HandlingCost)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return models.ErrPackSizeExists
+		}
+		if err != nil {
+			return err
+		}
 
-	return nil
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return models.ErrPackSizeNotFound
+		}
+
+		return nil
+	})
+}
+
+// CreatePackSize inserts a new pack size with its pricing metadata into the database.
+func (r *SQLPackSizeRepository) CreatePackSize(pack models.PackSize, actor string) error {
+	query := `INSERT INTO pack_sizes (size, price, weight, handling_cost) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`
+
+	return r.withCatalogChange(models.AuditActionCreate, actor, func(tx *sql.Tx) error {
+		result, err := tx.Exec(query, pack.Size, pack.Price, pack.Weight, pack.HandlingCost)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check affected rows: %s", err)
+		}
+
+		// Determine if the size was added or already exists.
+		if rowsAffected == 0 {
+			return models.ErrPackSizeExists
+		}
+
+		return nil
+	})
 }
 
 // DeletePackSize deletes an existing pack size from the database.
-func (r *SQLPackSizeRepository) DeletePackSize(size uint32) error {
+func (r *SQLPackSizeRepository) DeletePackSize(size uint32, actor string) error {
 	query := `DELETE FROM pack_sizes WHERE size = $1`
 
-	result, err := r.db.Exec(query, size)
+	return r.withCatalogChange(models.AuditActionDelete, actor, func(tx *sql.Tx) error {
+		result, err := tx.Exec(query, size)
+		if err != nil {
+			return err
+		}
+
+		// Check if a row was deleted.
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return models.ErrPackSizeNotFound
+		}
+
+		return nil
+	})
+}
+
+// ReplacePackSizes replaces the whole catalog with packs in one transaction, so calculations never
+// see a half-replaced catalog. Sizes kept in the catalog keep their ID and get the new pricing metadata.
+func (r *SQLPackSizeRepository) ReplacePackSizes(packs []models.PackSize, actor string) (*models.CatalogChange, error) {
+	change := &models.CatalogChange{Added: []uint32{}, Removed: []uint32{}}
+
+	err := r.withCatalogChange(models.AuditActionReplace, actor, func(tx *sql.Tx) error {
+		rows, err := tx.Query(`SELECT size FROM pack_sizes ORDER BY size DESC`)
+		if err != nil {
+			return err
+		}
+
+		existing := make(map[uint32]bool)
+		var existingSizes []uint32
+		for rows.Next() {
+			var size uint32
+			if err := rows.Scan(&size); err != nil {
+				rows.Close()
+				return err
+			}
+			existing[size] = true
+			existingSizes = append(existingSizes, size)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		wanted := make(map[uint32]bool, len(packs))
+		for _, pack := range packs {
+			wanted[pack.Size] = true
+		}
+
+		for _, size := range existingSizes {
+			if wanted[size] {
+				continue
+			}
+			if _, err := tx.Exec(`DELETE FROM pack_sizes WHERE size = $1`, size); err != nil {
+				return err
+			}
+			change.Removed = append(change.Removed, size)
+		}
+
+		for _, pack := range packs {
+			if _, err := tx.Exec(upsertPackSizeQuery, pack.Size, pack.Price, pack.Weight, pack.HandlingCost); err != nil {
+				return err
+			}
+			if !existing[pack.Size] {
+				change.Added = append(change.Added, pack.Size)
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	sort.Slice(change.Added, func(i, j int) bool { return change.Added[i] > change.Added[j] })
+
+	return change, nil
+}
+
+// MergePackSizes adds packs to the catalog in one transaction, overwriting the pricing metadata of sizes
+// already in it. Sizes not in packs are left untouched.
+func (r *SQLPackSizeRepository) MergePackSizes(packs []models.PackSize, actor string) error {
+	return r.withCatalogChange(models.AuditActionImport, actor, func(tx *sql.Tx) error {
+		for _, pack := range packs {
+			if _, err := tx.Exec(upsertPackSizeQuery, pack.Size, pack.Price, pack.Weight, pack.HandlingCost); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetCatalogVersion retrieves a catalog version with its pack sizes, largest first.
+// ID 0 selects the latest version.
+func (r *SQLPackSizeRepository) GetCatalogVersion(id uint32) (*models.CatalogVersion, error) {
+	query := `SELECT id, created_at FROM catalog_versions WHERE id = $1`
+	args := []any{id}
+	if id == 0 {
+		query = `SELECT id, created_at FROM catalog_versions ORDER BY id DESC LIMIT 1`
+		args = nil
 	}
 
-	// Check if a row was deleted.
-	rowsAffected, err := result.RowsAffected()
+	var version models.CatalogVersion
+	err := r.db.QueryRow(query, args...).Scan(&version.ID, &version.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrCatalogVersionNotFound
+	}
 	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`SELECT pack_size_id, size, price, weight, handling_cost FROM catalog_version_packs
+		WHERE version_id = $1 ORDER BY size DESC`, version.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	version.PackSizes = []models.PackSize{}
+	for rows.Next() {
+		var pack models.PackSize
+		if err := rows.Scan(&pack.ID, &pack.Size, &pack.Price, &pack.Weight, &pack.HandlingCost); err != nil {
+			return nil, err
+		}
+		version.PackSizes = append(version.PackSizes, pack)
+	}
+
+	return &version, rows.Err()
+}
+
+// withCatalogChange runs change in a transaction, records the resulting catalog as a new version and
+// audits the change on behalf of actor. Catalog changes are serialised, so every version reflects
+// exactly one change.
+func (r *SQLPackSizeRepository) withCatalogChange(action, actor string, change func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`LOCK TABLE catalog_versions IN EXCLUSIVE MODE`); err != nil {
+		return err
+	}
+
+	var before sql.NullInt64
+	if err := tx.QueryRow(`SELECT MAX(id) FROM catalog_versions`).Scan(&before); err != nil {
+		return err
+	}
+
+	if err := change(tx); err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("no rows affected, pack size not found")
+	var after uint32
+	if err := tx.QueryRow(`INSERT INTO catalog_versions DEFAULT VALUES RETURNING id`).Scan(&after); err != nil {
+		return err
+	}
+
+	snapshot := `INSERT INTO catalog_version_packs (version_id, pack_size_id, size, price, weight, handling_cost)
+		SELECT $1, id, size, price, weight, handling_cost FROM pack_sizes`
+	if _, err := tx.Exec(snapshot, after); err != nil {
+		return err
+	}
+
+	audit := `INSERT INTO audit_log (actor, action, before_version, after_version) VALUES ($1, $2, $3, $4)`
+	if _, err := tx.Exec(audit, actor, action, before, after); err != nil {
+		return err
 	}
 
-	return nil
+	return tx.Commit()
 }
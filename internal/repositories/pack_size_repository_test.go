@@ -1,52 +1,53 @@
 package repositories
 
 import (
-	_ "database/sql"
+	"database/sql"
 	"errors"
-	"fmt"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/klemis/packs-calculator/models"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestCreatePackSize(t *testing.T) {
 	tests := []struct {
 		name        string
-		size        uint32
+		pack        models.PackSize
 		mockSetup   func(mock sqlmock.Sqlmock)
 		expectedErr error
 	}{
 		{
 			name: "successful insert",
-			size: 100,
+			pack: models.PackSize{Size: 100, Price: 9.99, Weight: 1.5, HandlingCost: 0.5},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO pack_sizes (size) VALUES ($1) ON CONFLICT DO NOTHING`)).
-					WithArgs(100).
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO pack_sizes (size, price, weight, handling_cost) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`)).
+					WithArgs(100, 9.99, 1.5, 0.5).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			expectedErr: nil,
 		},
 		{
 			name: "insert conflict (no rows affected)",
-			size: 100,
+			pack: models.PackSize{Size: 100, Price: 9.99, Weight: 1.5, HandlingCost: 0.5},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Expect conflict handling, no rows affected.
-				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO pack_sizes (size) VALUES ($1) ON CONFLICT DO NOTHING`)).
-					WithArgs(100).
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO pack_sizes (size, price, weight, handling_cost) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`)).
+					WithArgs(100, 9.99, 1.5, 0.5).
 					WillReturnResult(sqlmock.NewResult(1, 0))
 			},
-			expectedErr: fmt.Errorf("no rows were affected"),
+			expectedErr: models.ErrPackSizeExists,
 		},
 		{
 			name: "insert failure",
-			size: 100,
+			pack: models.PackSize{Size: 100, Price: 9.99, Weight: 1.5, HandlingCost: 0.5},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Simulate insert error.
-				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO pack_sizes (size) VALUES ($1) ON CONFLICT DO NOTHING`)).
-					WithArgs(100).
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO pack_sizes (size, price, weight, handling_cost) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`)).
+					WithArgs(100, 9.99, 1.5, 0.5).
 					WillReturnError(errors.New("insert failed"))
 			},
 			expectedErr: errors.New("insert failed"),
@@ -60,9 +61,9 @@ func TestCreatePackSize(t *testing.T) {
 			defer db.Close()
 
 			repo := NewSQLPackSizeRepository(db)
-			tt.mockSetup(mock)
+			expectCatalogChange(mock, models.AuditActionCreate, tt.mockSetup, tt.expectedErr == nil)
 
-			err = repo.CreatePackSize(tt.size)
+			err = repo.CreatePackSize(tt.pack, "alice")
 			if tt.expectedErr != nil {
 				assert.EqualError(t, err, tt.expectedErr.Error())
 			} else {
@@ -100,7 +101,7 @@ func TestDeletePackSize(t *testing.T) {
 					WithArgs(100).
 					WillReturnResult(sqlmock.NewResult(1, 0))
 			},
-			expectedErr: fmt.Errorf("no rows affected, pack size not found"),
+			expectedErr: models.ErrPackSizeNotFound,
 		},
 		{
 			name: "delete failure",
@@ -122,9 +123,9 @@ func TestDeletePackSize(t *testing.T) {
 			defer db.Close()
 
 			repo := NewSQLPackSizeRepository(db)
-			tt.mockSetup(mock)
+			expectCatalogChange(mock, models.AuditActionDelete, tt.mockSetup, tt.expectedErr == nil)
 
-			err = repo.DeletePackSize(tt.size)
+			err = repo.DeletePackSize(tt.size, "alice")
 			if tt.expectedErr != nil {
 				assert.EqualError(t, err, tt.expectedErr.Error())
 			} else {
@@ -147,16 +148,16 @@ func TestGetPackSizes(t *testing.T) {
 			name: "successful retrieval",
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Expect query to return pack sizes.
-				rows := sqlmock.NewRows([]string{"id", "size"}).
-					AddRow(1, 250).
-					AddRow(2, 500)
+				rows := sqlmock.NewRows([]string{"id", "size", "price", "weight", "handling_cost"}).
+					AddRow(1, 250, 2.5, 0.25, 0.1).
+					AddRow(2, 500, 4, 0.5, 0.1)
 
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, size FROM pack_sizes ORDER BY size DESC`)).
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, size, price, weight, handling_cost FROM pack_sizes ORDER BY size DESC`)).
 					WillReturnRows(rows)
 			},
 			expected: []models.PackSize{
-				{ID: 1, Size: 250},
-				{ID: 2, Size: 500},
+				{ID: 1, Size: 250, Price: 2.5, Weight: 0.25, HandlingCost: 0.1},
+				{ID: 2, Size: 500, Price: 4, Weight: 0.5, HandlingCost: 0.1},
 			},
 			expectedError: "",
 		},
@@ -164,7 +165,7 @@ func TestGetPackSizes(t *testing.T) {
 			name: "query error",
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Simulate query failure.
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, size FROM pack_sizes ORDER BY size DESC`)).
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, size, price, weight, handling_cost FROM pack_sizes ORDER BY size DESC`)).
 					WillReturnError(errors.New("query error"))
 			},
 			expected:      nil,
@@ -174,10 +175,10 @@ func TestGetPackSizes(t *testing.T) {
 			name: "scan error",
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Simulate scan failure with an invalid data type for size.
-				rows := sqlmock.NewRows([]string{"id", "size"}).
-					AddRow(1, "invalid_data") // Simulate scan error due to type mismatch.
+				rows := sqlmock.NewRows([]string{"id", "size", "price", "weight", "handling_cost"}).
+					AddRow(1, "invalid_data", 0, 0, 0) // Simulate scan error due to type mismatch.
 
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, size FROM pack_sizes ORDER BY size DESC`)).
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, size, price, weight, handling_cost FROM pack_sizes ORDER BY size DESC`)).
 					WillReturnRows(rows)
 			},
 			expected:      nil,
@@ -207,3 +208,329 @@ func TestGetPackSizes(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPackSize(t *testing.T) {
+	query := `SELECT id, size, price, weight, handling_cost FROM pack_sizes WHERE id = $1`
+
+	tests := []struct {
+		name          string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expected      *models.PackSize
+		expectedError string
+	}{
+		{
+			name: "successful retrieval",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "size", "price", "weight", "handling_cost"}).
+					AddRow(2, 500, 4, 0.5, 0.1)
+
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2).WillReturnRows(rows)
+			},
+			expected: &models.PackSize{ID: 2, Size: 500, Price: 4, Weight: 0.5, HandlingCost: 0.1},
+		},
+		{
+			name: "pack size not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2).WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: models.ErrPackSizeNotFound.Error(),
+		},
+		{
+			name: "query error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(2).WillReturnError(errors.New("query error"))
+			},
+			expectedError: "query error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewSQLPackSizeRepository(db)
+			tt.mockSetup(mock)
+
+			pack, err := repo.GetPackSize(2)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, pack)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdatePackSize(t *testing.T) {
+	query := `UPDATE pack_sizes SET size = $2, price = $3, weight = $4, handling_cost = $5 WHERE id = $1`
+	pack := models.PackSize{ID: 2, Size: 750, Price: 5, Weight: 0.75, HandlingCost: 0.1}
+
+	tests := []struct {
+		name        string
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expectedErr error
+	}{
+		{
+			name: "successful update",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(2, 750, 5.0, 0.75, 0.1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "pack size not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(2, 750, 5.0, 0.75, 0.1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedErr: models.ErrPackSizeNotFound,
+		},
+		{
+			name: "size already taken",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(2, 750, 5.0, 0.75, 0.1).
+					WillReturnError(&pq.Error{Code: "23505"})
+			},
+			expectedErr: models.ErrPackSizeExists,
+		},
+		{
+			name: "update failure",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(2, 750, 5.0, 0.75, 0.1).
+					WillReturnError(errors.New("update failed"))
+			},
+			expectedErr: errors.New("update failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewSQLPackSizeRepository(db)
+			expectCatalogChange(mock, models.AuditActionUpdate, tt.mockSetup, tt.expectedErr == nil)
+
+			err = repo.UpdatePackSize(pack, "alice")
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReplacePackSizes(t *testing.T) {
+	selectQuery := `SELECT size FROM pack_sizes ORDER BY size DESC`
+	deleteQuery := `DELETE FROM pack_sizes WHERE size = $1`
+	upsertQuery := `INSERT INTO pack_sizes (size, price, weight, handling_cost) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (size) DO UPDATE SET price = EXCLUDED.price, weight = EXCLUDED.weight, handling_cost = EXCLUDED.handling_cost`
+	packs := []models.PackSize{{Size: 1000, Price: 7}, {Size: 300, Price: 3}, {Size: 750, Price: 6}}
+
+	tests := []struct {
+		name          string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expected      *models.CatalogChange
+		expectedError string
+	}{
+		{
+			name: "successful replace",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(selectQuery)).
+					WillReturnRows(sqlmock.NewRows([]string{"size"}).AddRow(1000).AddRow(500).AddRow(250))
+				mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).WithArgs(500).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).WithArgs(250).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(upsertQuery)).WithArgs(1000, 7.0, 0.0, 0.0).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(upsertQuery)).WithArgs(300, 3.0, 0.0, 0.0).WillReturnResult(sqlmock.NewResult(4, 1))
+				mock.ExpectExec(regexp.QuoteMeta(upsertQuery)).WithArgs(750, 6.0, 0.0, 0.0).WillReturnResult(sqlmock.NewResult(5, 1))
+			},
+			expected: &models.CatalogChange{Added: []uint32{750, 300}, Removed: []uint32{500, 250}},
+		},
+		{
+			name: "upsert failure rolls back",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(selectQuery)).
+					WillReturnRows(sqlmock.NewRows([]string{"size"}).AddRow(1000))
+				mock.ExpectExec(regexp.QuoteMeta(upsertQuery)).WithArgs(1000, 7.0, 0.0, 0.0).WillReturnError(errors.New("upsert failed"))
+			},
+			expectedError: "upsert failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewSQLPackSizeRepository(db)
+			expectCatalogChange(mock, models.AuditActionReplace, tt.mockSetup, tt.expectedError == "")
+
+			change, err := repo.ReplacePackSizes(packs, "alice")
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, change)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMergePackSizes(t *testing.T) {
+	upsertQuery := `INSERT INTO pack_sizes (size, price, weight, handling_cost) VALUES ($1, $2, $3, $4)
+	ON CONFLICT (size) DO UPDATE SET price = EXCLUDED.price, weight = EXCLUDED.weight, handling_cost = EXCLUDED.handling_cost`
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewSQLPackSizeRepository(db)
+	expectCatalogChange(mock, models.AuditActionImport, func(mock sqlmock.Sqlmock) {
+		mock.ExpectExec(regexp.QuoteMeta(upsertQuery)).WithArgs(1000, 7.0, 1.5, 0.0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(upsertQuery)).WithArgs(300, 3.0, 0.0, 0.25).WillReturnResult(sqlmock.NewResult(4, 1))
+	}, true)
+
+	err = repo.MergePackSizes([]models.PackSize{{Size: 1000, Price: 7, Weight: 1.5}, {Size: 300, Price: 3, HandlingCost: 0.25}}, "alice")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCatalogChangeSnapshotFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewSQLPackSizeRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`LOCK TABLE catalog_versions IN EXCLUSIVE MODE`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT MAX(id) FROM catalog_versions`)).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(3))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM pack_sizes WHERE size = $1`)).WithArgs(100).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO catalog_versions DEFAULT VALUES RETURNING id`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectExec(regexp.QuoteMeta(catalogSnapshotQuery)).WithArgs(4).WillReturnError(errors.New("snapshot failed"))
+	mock.ExpectRollback()
+
+	// The change is rolled back when its catalog version cannot be recorded.
+	err = repo.DeletePackSize(100, "alice")
+	assert.EqualError(t, err, "snapshot failed")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCatalogVersion(t *testing.T) {
+	createdAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	packsQuery := `SELECT pack_size_id, size, price, weight, handling_cost FROM catalog_version_packs
+		WHERE version_id = $1 ORDER BY size DESC`
+
+	tests := []struct {
+		name        string
+		id          uint32
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expected    *models.CatalogVersion
+		expectedErr error
+	}{
+		{
+			name: "latest version",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, created_at FROM catalog_versions ORDER BY id DESC LIMIT 1`)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(4, createdAt))
+				mock.ExpectQuery(regexp.QuoteMeta(packsQuery)).
+					WithArgs(4).
+					WillReturnRows(sqlmock.NewRows([]string{"pack_size_id", "size", "price", "weight", "handling_cost"}).
+						AddRow(2, 500, 4.0, 0.5, 0.0).
+						AddRow(1, 250, 2.5, 0.25, 0.0))
+			},
+			expected: &models.CatalogVersion{ID: 4, CreatedAt: createdAt, PackSizes: []models.PackSize{
+				{ID: 2, Size: 500, Price: 4, Weight: 0.5},
+				{ID: 1, Size: 250, Price: 2.5, Weight: 0.25},
+			}},
+		},
+		{
+			name: "historical version",
+			id:   2,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, created_at FROM catalog_versions WHERE id = $1`)).
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(2, createdAt))
+				mock.ExpectQuery(regexp.QuoteMeta(packsQuery)).
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"pack_size_id", "size", "price", "weight", "handling_cost"}))
+			},
+			expected: &models.CatalogVersion{ID: 2, CreatedAt: createdAt, PackSizes: []models.PackSize{}},
+		},
+		{
+			name: "version not found",
+			id:   9,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, created_at FROM catalog_versions WHERE id = $1`)).
+					WithArgs(9).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: models.ErrCatalogVersionNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewSQLPackSizeRepository(db)
+			tt.mockSetup(mock)
+
+			version, err := repo.GetCatalogVersion(tt.id)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, version)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// catalogSnapshotQuery copies the catalog into a new version after every catalog change.
+const catalogSnapshotQuery = `INSERT INTO catalog_version_packs (version_id, pack_size_id, size, price, weight, handling_cost)
+		SELECT $1, id, size, price, weight, handling_cost FROM pack_sizes`
+
+// expectCatalogChange expects change to run as action in a catalog transaction by alice, which records a new
+// catalog version and an audit entry and commits when the change succeeds, and rolls back otherwise.
+func expectCatalogChange(mock sqlmock.Sqlmock, action string, change func(mock sqlmock.Sqlmock), committed bool) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`LOCK TABLE catalog_versions IN EXCLUSIVE MODE`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT MAX(id) FROM catalog_versions`)).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(3))
+	change(mock)
+	if !committed {
+		mock.ExpectRollback()
+		return
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO catalog_versions DEFAULT VALUES RETURNING id`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectExec(regexp.QuoteMeta(catalogSnapshotQuery)).WithArgs(4).WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO audit_log (actor, action, before_version, after_version) VALUES ($1, $2, $3, $4)`)).
+		WithArgs("alice", action, 3, 4).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+}
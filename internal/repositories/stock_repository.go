@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/klemis/packs-calculator/models"
+)
+
+// StockRepository defines the interface for tracking the packs on hand per pack size.
+type StockRepository interface {
+	GetStock() ([]models.PackStock, error)
+	SetStock(size uint32, quantity uint32) error
+	AdjustStock(size uint32, delta int64) (uint32, error)
+	ClearStock(size uint32) error
+}
+
+// SQLStockRepository is the struct that implements StockRepository interface for SQL database.
+type SQLStockRepository struct {
+	db *sql.DB
+}
+
+// NewSQLStockRepository initializes a new SQL-based stock repository.
+func NewSQLStockRepository(db *sql.DB) StockRepository {
+	return &SQLStockRepository{db: db}
+}
+
+// GetStock retrieves the stock of every pack size that has its stock tracked.
+func (r *SQLStockRepository) GetStock() ([]models.PackStock, error) {
+	query := `SELECT p.size, s.quantity, s.updated_at FROM pack_stock s
+		JOIN pack_sizes p ON p.id = s.pack_size_id ORDER BY p.size DESC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stock []models.PackStock
+	for rows.Next() {
+		var item models.PackStock
+		if err := rows.Scan(&item.Size, &item.Quantity, &item.UpdatedAt); err != nil {
+			return nil, err
+		}
+		stock = append(stock, item)
+	}
+
+	return stock, nil
+}
+
+// SetStock sets the number of packs on hand for a pack size, starting to track it if needed.
+func (r *SQLStockRepository) SetStock(size uint32, quantity uint32) error {
+	query := `INSERT INTO pack_stock (pack_size_id, quantity) SELECT id, $2 FROM pack_sizes WHERE size = $1
+		ON CONFLICT (pack_size_id) DO UPDATE SET quantity = EXCLUDED.quantity, updated_at = NOW()`
+
+	result, err := r.db.Exec(query, size, quantity)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %s", err)
+	}
+
+	if rowsAffected == 0 {
+		return models.ErrPackSizeNotFound
+	}
+
+	return nil
+}
+
+// AdjustStock adds delta packs to the tracked stock of a pack size and returns the new quantity.
+// The sum is taken as a bigint so adjustments that would leave the integer column's range are
+// refused rather than failing in the database.
+func (r *SQLStockRepository) AdjustStock(size uint32, delta int64) (uint32, error) {
+	query := `UPDATE pack_stock s SET quantity = s.quantity + $2::bigint, updated_at = NOW()
+		FROM pack_sizes p WHERE p.id = s.pack_size_id AND p.size = $1
+		AND s.quantity + $2::bigint BETWEEN 0 AND 2147483647
+		RETURNING s.quantity`
+
+	var quantity uint32
+	err := r.db.QueryRow(query, size, delta).Scan(&quantity)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, models.ErrInvalidStockAdjustment
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return quantity, nil
+}
+
+// ClearStock stops tracking the stock of a pack size, making its supply unlimited again.
+func (r *SQLStockRepository) ClearStock(size uint32) error {
+	query := `DELETE FROM pack_stock s USING pack_sizes p WHERE p.id = s.pack_size_id AND p.size = $1`
+
+	result, err := r.db.Exec(query, size)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return models.ErrStockNotTracked
+	}
+
+	return nil
+}
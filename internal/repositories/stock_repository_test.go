@@ -0,0 +1,238 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetStock(t *testing.T) {
+	updatedAt := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	query := `SELECT p.size, s.quantity, s.updated_at FROM pack_stock s
+		JOIN pack_sizes p ON p.id = s.pack_size_id ORDER BY p.size DESC`
+
+	tests := []struct {
+		name          string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expected      []models.PackStock
+		expectedError string
+	}{
+		{
+			name: "successful retrieval",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"size", "quantity", "updated_at"}).
+					AddRow(500, 10, updatedAt).
+					AddRow(250, 0, updatedAt)
+
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnRows(rows)
+			},
+			expected: []models.PackStock{
+				{Size: 500, Quantity: 10, UpdatedAt: updatedAt},
+				{Size: 250, Quantity: 0, UpdatedAt: updatedAt},
+			},
+		},
+		{
+			name: "query error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(errors.New("query error"))
+			},
+			expectedError: "query error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewSQLStockRepository(db)
+			tt.mockSetup(mock)
+
+			stock, err := repo.GetStock()
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, stock)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSetStock(t *testing.T) {
+	query := `INSERT INTO pack_stock (pack_size_id, quantity) SELECT id, $2 FROM pack_sizes WHERE size = $1
+		ON CONFLICT (pack_size_id) DO UPDATE SET quantity = EXCLUDED.quantity, updated_at = NOW()`
+
+	tests := []struct {
+		name        string
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expectedErr error
+	}{
+		{
+			name: "successful upsert",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(500, 10).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "pack size not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(500, 10).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedErr: models.ErrPackSizeNotFound,
+		},
+		{
+			name: "upsert failure",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(500, 10).
+					WillReturnError(errors.New("upsert failed"))
+			},
+			expectedErr: errors.New("upsert failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewSQLStockRepository(db)
+			tt.mockSetup(mock)
+
+			err = repo.SetStock(500, 10)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAdjustStock(t *testing.T) {
+	query := `UPDATE pack_stock s SET quantity = s.quantity + $2::bigint, updated_at = NOW()
+		FROM pack_sizes p WHERE p.id = s.pack_size_id AND p.size = $1
+		AND s.quantity + $2::bigint BETWEEN 0 AND 2147483647
+		RETURNING s.quantity`
+
+	tests := []struct {
+		name             string
+		mockSetup        func(mock sqlmock.Sqlmock)
+		expectedQuantity uint32
+		expectedErr      error
+	}{
+		{
+			name: "successful adjustment",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(500, -3).
+					WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(7))
+			},
+			expectedQuantity: 7,
+		},
+		{
+			name: "untracked or out of range stock",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(500, -3).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: models.ErrInvalidStockAdjustment,
+		},
+		{
+			name: "update failure",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(query)).
+					WithArgs(500, -3).
+					WillReturnError(errors.New("update failed"))
+			},
+			expectedErr: errors.New("update failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewSQLStockRepository(db)
+			tt.mockSetup(mock)
+
+			quantity, err := repo.AdjustStock(500, -3)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedQuantity, quantity)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestClearStock(t *testing.T) {
+	query := `DELETE FROM pack_stock s USING pack_sizes p WHERE p.id = s.pack_size_id AND p.size = $1`
+
+	tests := []struct {
+		name        string
+		mockSetup   func(mock sqlmock.Sqlmock)
+		expectedErr error
+	}{
+		{
+			name: "successful delete",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(500).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "stock not tracked",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(query)).
+					WithArgs(500).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedErr: models.ErrStockNotTracked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			repo := NewSQLStockRepository(db)
+			tt.mockSetup(mock)
+
+			err = repo.ClearStock(500)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
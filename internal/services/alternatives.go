@@ -0,0 +1,318 @@
+package services
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	// MaxAlternatives caps how many alternative pack combinations one calculation ranks.
+	MaxAlternatives = 10
+	// maxAlternativesTotal caps the totals alternatives are ranked over, as the search keeps a table of
+	// every total for each pack size.
+	maxAlternativesTotal = 1 << 18
+)
+
+// ErrAlternativesUnsupported is returned when a calculation asks for alternatives from a strategy that
+// does not rank pack combinations.
+var ErrAlternativesUnsupported = errors.New("packing strategy does not rank alternatives")
+
+// RankingStrategy is a PackingStrategy with an objective that ranks every pack combination, so it can
+// offer the next best combinations as alternatives.
+type RankingStrategy interface {
+	PackingStrategy
+	// Alternatives returns up to k of the best pack combinations the fill mode allows, best first.
+	Alternatives(problem PackingProblem, k int) ([]map[uint32]uint64, error)
+}
+
+// combinationRank is what a strategy objective compares: the items a combination ships, its number of
+// packs and its cost.
+type combinationRank struct {
+	items uint64
+	packs uint64
+	cost  float64
+}
+
+// rankLess reports whether a combination ranked a beats one ranked b under a strategy objective.
+type rankLess func(a, b combinationRank) bool
+
+// alternatives ranks the pack combinations the fill mode allows by less, best first, and returns the
+// first k. Underfilling ranks combinations that ship more items first, whatever the objective. Unless
+// priced, every pack is free, for objectives that ignore cost.
+//
+// The search is best first over the pack counts, one size at a time from the largest. A partial
+// combination is ranked by the best way to complete it, which tables of the cheapest combination of
+// the remaining sizes for every total give exactly, so combinations are completed in rank order.
+func (p PackingProblem) alternatives(priced bool, k int, less rankLess) ([]map[uint32]uint64, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+
+	packs := sortedPacks(p.PackSizes)
+	if p.Quantity == 0 || len(packs) == 0 {
+		return []map[uint32]uint64{make(map[uint32]uint64)}, nil
+	}
+
+	sizes := make([]uint32, len(packs))
+	var unitCosts []float64
+	if priced {
+		unitCosts = make([]float64, len(packs))
+	}
+	for i, pack := range packs {
+		sizes[i] = pack.Size
+		if priced {
+			unitCosts[i] = pack.UnitCost(p.ShippingRate)
+		}
+	}
+	if err := p.checkCover(sizes); err != nil {
+		return nil, err
+	}
+	if p.Mode.Name == FillUnderfill {
+		objective := less
+		less = func(a, b combinationRank) bool {
+			if a.items != b.items {
+				return a.items > b.items
+			}
+			return objective(a, b)
+		}
+	}
+
+	low, high := p.Mode.bounds(p.Quantity, sizes[0])
+	if high > maxAlternativesTotal {
+		return nil, fmt.Errorf("%w: alternatives are ranked for totals up to %d", ErrQuantityTooLarge, uint64(maxAlternativesTotal))
+	}
+	limits := p.limits(sizes)
+	suffixes := buildSuffixTables(sizes, unitCosts, limits, high)
+
+	search := &combinationSearch{less: less}
+	seed := func(total uint64) {
+		search.push(partialCombination{
+			total:  total,
+			counts: make([]uint32, len(sizes)),
+			rank:   combinationRank{items: total, packs: uint64(suffixes[0].packs[total]), cost: suffixes[0].cost[total]},
+		})
+	}
+	if p.Mode.Name == FillUnderfill {
+		// Every reachable total has a combination, so the k largest hold the k best.
+		for total, seeded := high, 0; seeded < k; total-- {
+			if suffixes[0].reachable(total) {
+				seed(total)
+				seeded++
+			}
+			if total == low {
+				break
+			}
+		}
+	} else {
+		for total := low; total <= high; total++ {
+			if suffixes[0].reachable(total) {
+				seed(total)
+			}
+		}
+	}
+
+	results := make([]map[uint32]uint64, 0, k)
+	for search.Len() > 0 && len(results) < k {
+		partial := search.pop()
+		if partial.level == len(sizes) {
+			results = append(results, partial.combination(sizes))
+			continue
+		}
+
+		i := partial.level
+		size, next := uint64(sizes[i]), suffixes[i+1]
+		unitCost := 0.0
+		if unitCosts != nil {
+			unitCost = unitCosts[i]
+		}
+		maxCount := uint64(math.MaxUint32)
+		if limits != nil {
+			maxCount = uint64(limits[i])
+		}
+
+		remaining := partial.total - partial.items
+		first := uint64(0)
+		if i == len(sizes)-1 {
+			// The last size must make up the rest on its own.
+			if remaining%size != 0 {
+				continue
+			}
+			first = remaining / size
+		}
+		for count := first; count*size <= remaining && count <= maxCount; count++ {
+			rest := remaining - count*size
+			if !next.reachable(rest) {
+				continue
+			}
+
+			child := partial
+			child.level = i + 1
+			child.items += count * size
+			child.packs += count
+			child.cost += float64(count) * unitCost
+			child.counts = append([]uint32(nil), partial.counts...)
+			child.counts[i] = uint32(count)
+			child.rank = combinationRank{
+				items: partial.total,
+				packs: child.packs + uint64(next.packs[rest]),
+				cost:  child.cost + next.cost[rest],
+			}
+			search.push(child)
+		}
+	}
+	if len(results) == 0 {
+		return nil, p.Mode.noFit()
+	}
+
+	return results, nil
+}
+
+// buildSuffixTables returns, for every i, the table of the cheapest combination of sizes[i:] for every
+// total up to limit. The last table holds only the empty combination.
+func buildSuffixTables(sizes []uint32, unitCosts []float64, limits []uint32, limit uint64) []*packTable {
+	table := &packTable{
+		sizes: make([]uint32, len(sizes)),
+		cost:  make([]float64, limit+1),
+		packs: make([]uint32, limit+1),
+		take:  make([][]uint32, len(sizes)),
+	}
+	for t := uint64(1); t <= limit; t++ {
+		table.cost[t] = math.Inf(1)
+		table.packs[t] = unreachable
+	}
+
+	suffixes := make([]*packTable, len(sizes)+1)
+	suffixes[len(sizes)] = table.snapshot()
+
+	// The pack counts are rebuilt by the search, so every size can share one scratch take column.
+	scratch := make([]uint32, limit+1)
+	for i := len(sizes) - 1; i >= 0; i-- {
+		unitCost := 0.0
+		if unitCosts != nil {
+			unitCost = unitCosts[i]
+		}
+
+		table.sizes[i], table.take[i] = sizes[i], scratch
+		if limits == nil || uint64(limits[i]) >= limit/uint64(sizes[i]) {
+			table.addUnbounded(i, unitCost)
+		} else {
+			table.addBounded(i, unitCost, limits[i])
+		}
+		suffixes[i] = table.snapshot()
+	}
+
+	return suffixes
+}
+
+// snapshot copies the cost and packs of every total, without the pack counts.
+func (t *packTable) snapshot() *packTable {
+	return &packTable{
+		cost:  append([]float64(nil), t.cost...),
+		packs: append([]uint32(nil), t.packs...),
+	}
+}
+
+// partialCombination is a pack combination aiming at total with the counts of the sizes before level
+// chosen. Its rank is that of the best combination completing it.
+type partialCombination struct {
+	total  uint64
+	level  int
+	items  uint64
+	packs  uint64
+	cost   float64
+	counts []uint32
+	rank   combinationRank
+	// seq breaks rank ties in the order partial combinations were found, keeping results stable.
+	seq int
+}
+
+// combination returns the pack counts of a complete combination.
+func (p partialCombination) combination(sizes []uint32) map[uint32]uint64 {
+	result := make(map[uint32]uint64)
+	for i, count := range p.counts {
+		if count > 0 {
+			result[sizes[i]] = uint64(count)
+		}
+	}
+
+	return result
+}
+
+// combinationSearch is a priority queue of partial combinations, best rank first.
+type combinationSearch struct {
+	partials []partialCombination
+	less     rankLess
+	seq      int
+}
+
+func (s *combinationSearch) Len() int { return len(s.partials) }
+
+func (s *combinationSearch) Less(i, j int) bool {
+	a, b := s.partials[i], s.partials[j]
+	if s.less(a.rank, b.rank) {
+		return true
+	}
+	if s.less(b.rank, a.rank) {
+		return false
+	}
+
+	return a.seq < b.seq
+}
+
+func (s *combinationSearch) Swap(i, j int) {
+	s.partials[i], s.partials[j] = s.partials[j], s.partials[i]
+}
+
+func (s *combinationSearch) Push(x any) { s.partials = append(s.partials, x.(partialCombination)) }
+
+func (s *combinationSearch) Pop() any {
+	last := s.partials[len(s.partials)-1]
+	s.partials = s.partials[:len(s.partials)-1]
+
+	return last
+}
+
+// push queues a partial combination.
+func (s *combinationSearch) push(partial partialCombination) {
+	partial.seq = s.seq
+	s.seq++
+	heap.Push(s, partial)
+}
+
+// pop takes the best ranked partial combination off the queue.
+func (s *combinationSearch) pop() partialCombination {
+	return heap.Pop(s).(partialCombination)
+}
+
+// fewerItems ranks combinations that ship fewer items first, then those with fewer packs.
+func fewerItems(a, b combinationRank) bool {
+	if a.items != b.items {
+		return a.items < b.items
+	}
+
+	return a.packs < b.packs
+}
+
+// fewerPacks ranks combinations with fewer packs first, then those that ship fewer items.
+func fewerPacks(a, b combinationRank) bool {
+	if a.packs != b.packs {
+		return a.packs < b.packs
+	}
+
+	return a.items < b.items
+}
+
+// lowerCost ranks cheaper combinations first, then those that ship fewer items, then those with
+// fewer packs.
+func lowerCost(a, b combinationRank) bool {
+	if a.cost < b.cost-costEpsilon {
+		return true
+	}
+	if a.cost > b.cost+costEpsilon {
+		return false
+	}
+
+	return fewerItems(a, b)
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bruteForceRanks enumerates every pack combination within stock whose total lies in [low, high] and
+// returns their ranks sorted by less. A nil stock is unlimited.
+func bruteForceRanks(sizes []uint32, costs []float64, stock map[uint32]uint32, low, high uint64, less rankLess) []combinationRank {
+	var ranks []combinationRank
+
+	var walk func(i int, rank combinationRank)
+	walk = func(i int, rank combinationRank) {
+		if i == len(sizes) {
+			if rank.items >= low {
+				ranks = append(ranks, rank)
+			}
+			return
+		}
+		for n := uint64(0); rank.items+n*uint64(sizes[i]) <= high; n++ {
+			if limit, ok := stock[sizes[i]]; ok && n > uint64(limit) {
+				break
+			}
+			walk(i+1, combinationRank{
+				items: rank.items + n*uint64(sizes[i]),
+				packs: rank.packs + n,
+				cost:  rank.cost + float64(n)*costs[i],
+			})
+		}
+	}
+	walk(0, combinationRank{})
+	sort.SliceStable(ranks, func(i, j int) bool { return less(ranks[i], ranks[j]) })
+
+	return ranks
+}
+
+// rankOf ranks a pack combination of the given sizes and unit costs.
+func rankOf(packs map[uint32]uint64, sizes []uint32, costs []float64) combinationRank {
+	var rank combinationRank
+	for i, size := range sizes {
+		n := packs[size]
+		rank.items += n * uint64(size)
+		rank.packs += n
+		rank.cost += float64(n) * costs[i]
+	}
+
+	return rank
+}
+
+func TestAlternativesMatchBruteForce(t *testing.T) {
+	k := 6
+	testCases := []struct {
+		strategy RankingStrategy
+		less     rankLess
+		priced   bool
+	}{
+		{strategy: OptimalStrategy{}, less: fewerItems},
+		{strategy: FewestPacksStrategy{}, less: fewerPacks},
+		{strategy: MinCostStrategy{}, less: lowerCost, priced: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.strategy.Name(), func(t *testing.T) {
+			for _, sizes := range oraclePackSets {
+				packSizes := toPackSizes(sizes)
+				costs := make([]float64, len(sizes))
+				limited := make(map[uint32]uint32, len(sizes))
+				for i, size := range sizes {
+					// Prices that are not proportional to size make the cheapest combination differ.
+					packSizes[i].Price = float64(size%7 + 1)
+					if tc.priced {
+						costs[i] = packSizes[i].Price
+					}
+					limited[size] = uint32(i + 2)
+				}
+
+				for _, stock := range []map[uint32]uint32{nil, limited} {
+					for _, mode := range []FillMode{{}, {Name: FillExact}, {Name: FillUnderfill}} {
+						for orderQty := uint64(1); orderQty <= 120; orderQty++ {
+							msg := fmt.Sprintf("sizes %v, stock %v, mode %q, quantity %d", sizes, stock, mode.Name, orderQty)
+							problem := PackingProblem{PackSizes: packSizes, Quantity: orderQty, Stock: stock, Mode: mode}
+							alternatives, err := tc.strategy.Alternatives(problem, k)
+
+							less := tc.less
+							if mode.Name == FillUnderfill {
+								less = func(a, b combinationRank) bool {
+									if a.items != b.items {
+										return a.items > b.items
+									}
+									return tc.less(a, b)
+								}
+							}
+							var largest uint32
+							for _, size := range sizes {
+								largest = max(largest, size)
+							}
+							low, high := mode.bounds(orderQty, largest)
+							expected := bruteForceRanks(sizes, costs, stock, low, high, less)
+							if len(expected) == 0 {
+								assert.Error(t, err, msg)
+								continue
+							}
+							if mode.Name != FillUnderfill && problem.checkStock(sizes) != nil {
+								assert.Error(t, err, msg)
+								continue
+							}
+
+							assert.NoError(t, err, msg)
+							assert.Len(t, alternatives, min(k, len(expected)), msg)
+							seen := make(map[string]bool)
+							for i, alternative := range alternatives {
+								rank := rankOf(alternative, sizes, costs)
+								assert.False(t, less(rank, expected[i]) || less(expected[i], rank), "%s: alternative %d", msg, i)
+
+								key := fmt.Sprint(alternative)
+								assert.False(t, seen[key], "%s: alternative %d repeats", msg, i)
+								seen[key] = true
+							}
+
+							// The best alternative ranks with the combination Pack chooses.
+							packed, err := tc.strategy.Pack(problem)
+							assert.NoError(t, err, msg)
+							best := rankOf(packed, sizes, costs)
+							assert.False(t, less(best, expected[0]) || less(expected[0], best), msg)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAlternatives(t *testing.T) {
+	problem := PackingProblem{PackSizes: toPackSizes([]uint32{250, 500, 1000}), Quantity: 1000}
+
+	alternatives, err := OptimalStrategy{}.Alternatives(problem, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[uint32]uint64{
+		{1000: 1},
+		{500: 2},
+		{500: 1, 250: 2},
+		{250: 4},
+	}, alternatives)
+
+	alternatives, err = OptimalStrategy{}.Alternatives(PackingProblem{PackSizes: problem.PackSizes}, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[uint32]uint64{{}}, alternatives)
+
+	problem.Quantity = 1001
+	problem.Mode = FillMode{Name: FillExact}
+	_, err = FewestPacksStrategy{}.Alternatives(problem, 3)
+	assert.ErrorIs(t, err, ErrNoExactFit)
+
+	// Orders beyond the table are refused rather than ranked over billions of totals.
+	for _, orderQty := range []uint64{maxAlternativesTotal, 1_000_000_000} {
+		problem := PackingProblem{PackSizes: toPackSizes([]uint32{250, 500, 1000, 2000, 5000}), Quantity: orderQty}
+		_, err = OptimalStrategy{}.Alternatives(problem, 3)
+		assert.ErrorIs(t, err, ErrQuantityTooLarge, "quantity %d", orderQty)
+	}
+
+	var _ RankingStrategy = MinCostStrategy{}
+	_, ranks := PackingStrategy(GreedyStrategy{}).(RankingStrategy)
+	assert.False(t, ranks)
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"github.com/klemis/packs-calculator/internal/repositories"
+	"github.com/klemis/packs-calculator/models"
+)
+
+// AuditLog defines the interface for reading who changed the pack catalog and how.
+type AuditLog interface {
+	GetAuditEntries(filter models.AuditFilter) (*models.AuditPage, error)
+}
+
+// AuditService is an implementation of AuditLog.
+type AuditService struct {
+	repo repositories.AuditRepository
+}
+
+// NewAuditService creates a new instance of AuditLog with an injected repository.
+func NewAuditService(auditRepo repositories.AuditRepository) AuditLog {
+	return &AuditService{
+		repo: auditRepo,
+	}
+}
+
+// GetAuditEntries returns a page of the catalog changes matching filter, newest first.
+func (s *AuditService) GetAuditEntries(filter models.AuditFilter) (*models.AuditPage, error) {
+	entries, total, err := s.repo.GetAuditEntries(filter)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []models.AuditEntry{}
+	}
+
+	return &models.AuditPage{
+		Entries: entries,
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}, nil
+}
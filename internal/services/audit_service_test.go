@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/repositories/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAuditEntries(t *testing.T) {
+	filter := models.AuditFilter{From: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), Actor: "alice", Limit: 50, Offset: 100}
+	entry := models.AuditEntry{ID: 1, Actor: "alice", Action: models.AuditActionDelete}
+
+	testCases := []struct {
+		name         string
+		mockResponse []models.AuditEntry
+		mockTotal    uint64
+		mockError    error
+		expected     *models.AuditPage
+		expectError  bool
+	}{
+		{
+			name:         "Matching entries",
+			mockResponse: []models.AuditEntry{entry},
+			mockTotal:    101,
+			expected:     &models.AuditPage{Entries: []models.AuditEntry{entry}, Total: 101, Limit: 50, Offset: 100},
+		},
+		{
+			name:     "No matching entries",
+			expected: &models.AuditPage{Entries: []models.AuditEntry{}, Limit: 50, Offset: 100},
+		},
+		{
+			name:        "Repository error",
+			mockError:   errors.New("query error"),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := mocks.NewMockAuditRepository(ctrl)
+			mockRepo.EXPECT().GetAuditEntries(filter).Return(tc.mockResponse, tc.mockTotal, tc.mockError).Times(1)
+
+			service := NewAuditService(mockRepo)
+
+			page, err := service.GetAuditEntries(filter)
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expected, page)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"github.com/klemis/packs-calculator/internal/repositories"
+	"github.com/klemis/packs-calculator/models"
+)
+
+// CalculationHistory defines the interface for reading past calculations.
+type CalculationHistory interface {
+	ListCalculations(filter models.CalculationFilter) (*models.CalculationPage, error)
+	GetCalculation(id uint64) (*models.CalculationRecord, error)
+}
+
+// CalculationHistoryService is an implementation of CalculationHistory.
+type CalculationHistoryService struct {
+	repo repositories.CalculationRepository
+}
+
+// NewCalculationHistoryService creates a new instance of CalculationHistory with an injected repository.
+func NewCalculationHistoryService(calculationRepo repositories.CalculationRepository) CalculationHistory {
+	return &CalculationHistoryService{
+		repo: calculationRepo,
+	}
+}
+
+// ListCalculations returns a page of the calculations matching filter, newest first.
+func (s *CalculationHistoryService) ListCalculations(filter models.CalculationFilter) (*models.CalculationPage, error) {
+	records, total, err := s.repo.GetCalculations(filter)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = []models.CalculationRecord{}
+	}
+
+	return &models.CalculationPage{
+		Calculations: records,
+		Total:        total,
+		Limit:        filter.Limit,
+		Offset:       filter.Offset,
+	}, nil
+}
+
+// GetCalculation returns a past calculation by its ID.
+func (s *CalculationHistoryService) GetCalculation(id uint64) (*models.CalculationRecord, error) {
+	return s.repo.GetCalculation(id)
+}
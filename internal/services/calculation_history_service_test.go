@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/repositories/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListCalculations(t *testing.T) {
+	filter := models.CalculationFilter{MinQuantity: 100, MaxQuantity: math.MaxUint32, Limit: 2, Offset: 4}
+	record := models.CalculationRecord{ID: 9, Quantity: 251, Packs: map[uint32]uint64{500: 1}}
+
+	testCases := []struct {
+		name         string
+		mockResponse []models.CalculationRecord
+		mockTotal    uint64
+		mockError    error
+		expected     *models.CalculationPage
+		expectError  bool
+	}{
+		{
+			name:         "Page of calculations",
+			mockResponse: []models.CalculationRecord{record},
+			mockTotal:    5,
+			expected:     &models.CalculationPage{Calculations: []models.CalculationRecord{record}, Total: 5, Limit: 2, Offset: 4},
+		},
+		{
+			name:     "Past the last page",
+			expected: &models.CalculationPage{Calculations: []models.CalculationRecord{}, Limit: 2, Offset: 4},
+		},
+		{
+			name:        "Repository error",
+			mockError:   errors.New("query error"),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := mocks.NewMockCalculationRepository(ctrl)
+			mockRepo.EXPECT().GetCalculations(filter).Return(tc.mockResponse, tc.mockTotal, tc.mockError).Times(1)
+
+			service := NewCalculationHistoryService(mockRepo)
+
+			page, err := service.ListCalculations(filter)
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expected, page)
+		})
+	}
+}
+
+func TestGetCalculation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockCalculationRepository(ctrl)
+
+	record := &models.CalculationRecord{ID: 9, Quantity: 251, Packs: map[uint32]uint64{500: 1}}
+	mockRepo.EXPECT().GetCalculation(uint64(9)).Return(record, nil).Times(1)
+	mockRepo.EXPECT().GetCalculation(uint64(10)).Return(nil, models.ErrCalculationNotFound).Times(1)
+
+	service := NewCalculationHistoryService(mockRepo)
+
+	found, err := service.GetCalculation(9)
+	assert.NoError(t, err)
+	assert.Equal(t, record, found)
+
+	found, err = service.GetCalculation(10)
+	assert.ErrorIs(t, err, models.ErrCalculationNotFound)
+	assert.Nil(t, found)
+}
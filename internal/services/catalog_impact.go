@@ -0,0 +1,213 @@
+package services
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+
+	"github.com/klemis/packs-calculator/models"
+)
+
+// Kinds of catalog change an impact report can be made for.
+const (
+	// ChangeAdd adds pack sizes to the catalog.
+	ChangeAdd = "add"
+	// ChangeRemove removes a pack size from the catalog.
+	ChangeRemove = "remove"
+	// ChangeUpdate changes the size of a pack size in place.
+	ChangeUpdate = "update"
+	// ChangeReplace makes a new set of pack sizes the whole catalog.
+	ChangeReplace = "replace"
+)
+
+// Sources of the order quantities an impact report recalculates.
+const (
+	ImpactSourceRange   = "range"
+	ImpactSourceHistory = "history"
+)
+
+const (
+	// MaxImpactQuantities caps the distinct order quantities an impact report recalculates.
+	MaxImpactQuantities = 10000
+	// MaxImpactWorse caps the quantities an impact report lists as worse off.
+	MaxImpactWorse = 100
+)
+
+var (
+	// ErrInvalidCatalogChange is returned when an impact report is asked for an unknown kind of change,
+	// or a change missing what it adds, removes or updates.
+	ErrInvalidCatalogChange = errors.New("invalid catalog change")
+	// ErrInvalidImpactRange is returned when an impact report would recalculate too many quantities.
+	ErrInvalidImpactRange = errors.New("invalid impact range")
+)
+
+// CatalogProposal is a change to the catalog to report the impact of before making it.
+type CatalogProposal struct {
+	// Kind is ChangeAdd, ChangeRemove, ChangeUpdate or ChangeReplace.
+	Kind string
+	// Packs are the pack sizes added, or the whole catalog replacing the current one.
+	Packs []models.PackSize
+	// Size is the size removed.
+	Size uint32
+	// ID is the pack size an update changes, and Update the fields it changes.
+	ID     uint32
+	Update models.PackSizeUpdateRequest
+}
+
+// ImpactOptions selects the orders an impact report recalculates and how.
+type ImpactOptions struct {
+	// Strategy is the name of a registered PackingStrategy; empty selects DefaultStrategy.
+	Strategy string
+	// QuantityFrom and QuantityTo are the order quantities recalculated, each counted as one order. A
+	// zero QuantityTo recalculates the quantities of the calculation history matching History instead.
+	QuantityFrom uint64
+	QuantityTo   uint64
+	History      models.CalculationFilter
+}
+
+// proposedCatalog applies a proposal to the current pack sizes, checking the change as the catalog would.
+func proposedCatalog(current []models.PackSize, proposal CatalogProposal) ([]models.PackSize, error) {
+	proposed := slices.Clone(current)
+	switch proposal.Kind {
+	case ChangeAdd:
+		if len(proposal.Packs) == 0 {
+			return nil, fmt.Errorf("%w: an add needs the packs to add", ErrInvalidCatalogChange)
+		}
+		for _, pack := range proposal.Packs {
+			if slices.ContainsFunc(current, func(p models.PackSize) bool { return p.Size == pack.Size }) {
+				return nil, models.ErrPackSizeExists
+			}
+		}
+		proposed = append(proposed, proposal.Packs...)
+	case ChangeRemove:
+		if proposal.Size == 0 {
+			return nil, fmt.Errorf("%w: a remove needs the size to remove", ErrInvalidCatalogChange)
+		}
+		i := slices.IndexFunc(proposed, func(p models.PackSize) bool { return p.Size == proposal.Size })
+		if i < 0 {
+			return nil, models.ErrPackSizeNotFound
+		}
+		proposed = slices.Delete(proposed, i, i+1)
+	case ChangeUpdate:
+		if proposal.ID == 0 {
+			return nil, fmt.Errorf("%w: an update needs the ID of the pack size to update", ErrInvalidCatalogChange)
+		}
+		i := slices.IndexFunc(proposed, func(p models.PackSize) bool { return p.ID == proposal.ID })
+		if i < 0 {
+			return nil, models.ErrPackSizeNotFound
+		}
+		if proposal.Update.Size != nil {
+			proposed[i].Size = *proposal.Update.Size
+		}
+		if proposal.Update.Price != nil {
+			proposed[i].Price = *proposal.Update.Price
+		}
+		if proposal.Update.Weight != nil {
+			proposed[i].Weight = *proposal.Update.Weight
+		}
+		if proposal.Update.HandlingCost != nil {
+			proposed[i].HandlingCost = *proposal.Update.HandlingCost
+		}
+	case ChangeReplace:
+		proposed = proposal.Packs
+	default:
+		return nil, fmt.Errorf("%w: unknown change %q", ErrInvalidCatalogChange, proposal.Kind)
+	}
+
+	if err := validatePackSizes(proposed); err != nil {
+		return nil, err
+	}
+
+	return proposed, nil
+}
+
+// impactQuantities lists the order quantities in a range, each asked for by one order.
+func impactQuantities(from, to uint64) ([]models.QuantityCount, error) {
+	if from == 0 {
+		return nil, fmt.Errorf("%w: the quantity range starts at 1", ErrInvalidImpactRange)
+	}
+	if from > to {
+		return nil, fmt.Errorf("%w: the quantity range ends before it starts", ErrInvalidImpactRange)
+	}
+	if to-from >= MaxImpactQuantities {
+		return nil, fmt.Errorf("%w: at most %d quantities can be recalculated", ErrInvalidImpactRange, MaxImpactQuantities)
+	}
+
+	quantities := make([]models.QuantityCount, 0, to-from+1)
+	for q := from; ; q++ {
+		quantities = append(quantities, models.QuantityCount{Quantity: q, Count: 1})
+		if q == to {
+			break
+		}
+	}
+
+	return quantities, nil
+}
+
+// compareImpact reports how the packs of every quantity change between the current and proposed
+// calculations, where current[i] and proposed[i] pack quantities[i].
+func compareImpact(quantities []models.QuantityCount, current, proposed []*models.Calculation) *models.CatalogImpact {
+	impact := &models.CatalogImpact{
+		Quantities:     len(quantities),
+		OverfillDelta:  new(big.Int),
+		PackCountDelta: new(big.Int),
+		Worse:          []models.QuantityImpact{},
+	}
+	for i, quantity := range quantities {
+		impact.Orders += quantity.Count
+		if maps.Equal(current[i].Packs, proposed[i].Packs) {
+			continue
+		}
+
+		orders := new(big.Int).SetUint64(quantity.Count)
+		change := models.QuantityImpact{
+			Quantity:       quantity.Quantity,
+			Orders:         quantity.Count,
+			Current:        current[i].Packs,
+			Proposed:       proposed[i].Packs,
+			OverfillDelta:  models.NewDelta(packedItems(current[i].Packs), packedItems(proposed[i].Packs)),
+			PackCountDelta: models.NewDelta(PackCount(current[i].Packs), PackCount(proposed[i].Packs)),
+		}
+		impact.ChangedOrders += quantity.Count
+		impact.OverfillDelta.Add(impact.OverfillDelta, new(big.Int).Mul(orders, change.OverfillDelta.Int()))
+		impact.PackCountDelta.Add(impact.PackCountDelta, new(big.Int).Mul(orders, change.PackCountDelta.Int()))
+
+		overfill, packs := change.OverfillDelta.Sign(), change.PackCountDelta.Sign()
+		switch {
+		case overfill > 0 || overfill == 0 && packs > 0:
+			impact.WorseOrders += quantity.Count
+			impact.Worse = append(impact.Worse, change)
+		case overfill < 0 || packs < 0:
+			impact.BetterOrders += quantity.Count
+		}
+	}
+
+	// The quantities hit hardest come first.
+	slices.SortFunc(impact.Worse, func(a, b models.QuantityImpact) int {
+		if c := b.OverfillDelta.Compare(a.OverfillDelta); c != 0 {
+			return c
+		}
+		if c := b.PackCountDelta.Compare(a.PackCountDelta); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Quantity, b.Quantity)
+	})
+	impact.Worse = impact.Worse[:min(len(impact.Worse), MaxImpactWorse)]
+
+	return impact
+}
+
+// sizesOf lists the sizes of pack sizes, largest first.
+func sizesOf(packs []models.PackSize) []uint32 {
+	sizes := make([]uint32, len(packs))
+	for i, pack := range packs {
+		sizes[i] = pack.Size
+	}
+	slices.Sort(sizes)
+	slices.Reverse(sizes)
+
+	return sizes
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/repositories/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newImpactService(t *testing.T, sizes []uint32) (PacksCalculator, *mocks.MockCalculationRepository) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+	mockRepo.EXPECT().GetPackSizes().Return(toPackSizes(sizes), nil).Times(1)
+
+	return NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo), mockCalculationRepo
+}
+
+func TestAnalyseImpactRemoveOverRange(t *testing.T) {
+	service, _ := newImpactService(t, []uint32{250, 500, 1000, 2000, 5000})
+
+	impact, err := service.AnalyseImpact(
+		CatalogProposal{Kind: ChangeRemove, Size: 250},
+		ImpactOptions{QuantityFrom: 1, QuantityTo: 1000},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, ChangeRemove, impact.Change)
+	assert.Equal(t, ImpactSourceRange, impact.Source)
+	assert.Equal(t, DefaultStrategy, impact.Strategy)
+	assert.Equal(t, []uint32{5000, 2000, 1000, 500, 250}, impact.CurrentSizes)
+	assert.Equal(t, []uint32{5000, 2000, 1000, 500}, impact.ProposedSizes)
+	assert.Equal(t, 1000, impact.Quantities)
+	assert.Equal(t, uint64(1000), impact.Orders)
+
+	// 1-250 ship 500 instead of 250 and 501-750 ship 1000 instead of 500+250; the rest are unchanged.
+	assert.Equal(t, uint64(500), impact.ChangedOrders)
+	assert.Equal(t, uint64(500), impact.WorseOrders)
+	assert.Equal(t, uint64(0), impact.BetterOrders)
+	assert.Equal(t, "125000", impact.OverfillDelta.String())
+	assert.Equal(t, "-250", impact.PackCountDelta.String())
+	assert.Len(t, impact.Worse, MaxImpactWorse)
+	assert.Equal(t, models.QuantityImpact{
+		Quantity:       1,
+		Orders:         1,
+		Current:        map[uint32]uint64{250: 1},
+		Proposed:       map[uint32]uint64{500: 1},
+		OverfillDelta:  models.Delta{Magnitude: 250},
+		PackCountDelta: models.Delta{},
+	}, impact.Worse[0])
+}
+
+func TestAnalyseImpactAddOverRange(t *testing.T) {
+	service, _ := newImpactService(t, []uint32{500, 1000})
+
+	impact, err := service.AnalyseImpact(
+		CatalogProposal{Kind: ChangeAdd, Packs: []models.PackSize{{Size: 250}}},
+		ImpactOptions{QuantityFrom: 1, QuantityTo: 500},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{1000, 500, 250}, impact.ProposedSizes)
+	assert.Equal(t, uint64(250), impact.ChangedOrders)
+	assert.Equal(t, uint64(250), impact.BetterOrders)
+	assert.Equal(t, uint64(0), impact.WorseOrders)
+	assert.Equal(t, "-62500", impact.OverfillDelta.String())
+	assert.Empty(t, impact.Worse)
+}
+
+func TestAnalyseImpactOverHistory(t *testing.T) {
+	service, mockCalculationRepo := newImpactService(t, []uint32{250, 500})
+	filter := models.CalculationFilter{MinQuantity: 1, MaxQuantity: 1000}
+	mockCalculationRepo.EXPECT().GetQuantityDistribution(filter).
+		Return([]models.QuantityCount{{Quantity: 1, Count: 3}, {Quantity: 501, Count: 2}}, nil).Times(1)
+
+	impact, err := service.AnalyseImpact(CatalogProposal{Kind: ChangeRemove, Size: 500}, ImpactOptions{History: filter})
+	assert.NoError(t, err)
+	assert.Equal(t, ImpactSourceHistory, impact.Source)
+	assert.Equal(t, 2, impact.Quantities)
+	assert.Equal(t, uint64(5), impact.Orders)
+
+	// 501 ships the same 750 items in three packs of 250 instead of 500+250, for both its orders.
+	assert.Equal(t, uint64(2), impact.ChangedOrders)
+	assert.Equal(t, uint64(2), impact.WorseOrders)
+	assert.Equal(t, "0", impact.OverfillDelta.String())
+	assert.Equal(t, "2", impact.PackCountDelta.String())
+	assert.Equal(t, []models.QuantityImpact{{
+		Quantity:       501,
+		Orders:         2,
+		Current:        map[uint32]uint64{500: 1, 250: 1},
+		Proposed:       map[uint32]uint64{250: 3},
+		OverfillDelta:  models.Delta{},
+		PackCountDelta: models.Delta{Magnitude: 1},
+	}}, impact.Worse)
+}
+
+func TestAnalyseImpactUpdate(t *testing.T) {
+	service, _ := newImpactService(t, []uint32{250, 500})
+	size := uint32(300)
+
+	impact, err := service.AnalyseImpact(
+		CatalogProposal{Kind: ChangeUpdate, ID: 1, Update: models.PackSizeUpdateRequest{Size: &size}},
+		ImpactOptions{QuantityFrom: 300, QuantityTo: 300},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{500, 300}, impact.ProposedSizes)
+	assert.Equal(t, uint64(1), impact.BetterOrders)
+	assert.Equal(t, "-200", impact.OverfillDelta.String())
+}
+
+func TestCompareImpactBeyondInt64(t *testing.T) {
+	small := &models.Calculation{Packs: map[uint32]uint64{1: 1}}
+	huge := &models.Calculation{Packs: map[uint32]uint64{math.MaxUint32: math.MaxUint32 + 2}}
+	other := &models.Calculation{Packs: map[uint32]uint64{2: 1}}
+
+	impact := compareImpact(
+		[]models.QuantityCount{{Quantity: 1, Count: math.MaxInt64}, {Quantity: 2, Count: 1}},
+		[]*models.Calculation{small, huge},
+		[]*models.Calculation{huge, other},
+	)
+
+	assert.Equal(t, uint64(math.MaxInt64), impact.WorseOrders)
+	assert.Equal(t, uint64(1), impact.BetterOrders)
+	assert.Equal(t, "170141183460469231676347071494755450885", impact.OverfillDelta.String())
+	assert.Equal(t, "39614081257132168788182040576", impact.PackCountDelta.String())
+	assert.Equal(t, models.Delta{Magnitude: math.MaxUint64 - 1}, impact.Worse[0].OverfillDelta)
+	assert.Equal(t, models.Delta{Magnitude: math.MaxUint32 + 1}, impact.Worse[0].PackCountDelta)
+}
+
+func TestAnalyseImpactErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		proposal CatalogProposal
+		opts     ImpactOptions
+		expected error
+	}{
+		{
+			name:     "Unknown change",
+			proposal: CatalogProposal{Kind: "rename"},
+			expected: ErrInvalidCatalogChange,
+		},
+		{
+			name:     "Add without packs",
+			proposal: CatalogProposal{Kind: ChangeAdd},
+			expected: ErrInvalidCatalogChange,
+		},
+		{
+			name:     "Add an existing size",
+			proposal: CatalogProposal{Kind: ChangeAdd, Packs: []models.PackSize{{Size: 500}}},
+			expected: models.ErrPackSizeExists,
+		},
+		{
+			name:     "Remove a missing size",
+			proposal: CatalogProposal{Kind: ChangeRemove, Size: 750},
+			expected: models.ErrPackSizeNotFound,
+		},
+		{
+			name:     "Update a missing pack size",
+			proposal: CatalogProposal{Kind: ChangeUpdate, ID: 9},
+			expected: models.ErrPackSizeNotFound,
+		},
+		{
+			name:     "Replace with duplicates",
+			proposal: CatalogProposal{Kind: ChangeReplace, Packs: toPackSizes([]uint32{250, 250})},
+			expected: models.ErrDuplicateSize,
+		},
+		{
+			name:     "Range ending before it starts",
+			proposal: CatalogProposal{Kind: ChangeRemove, Size: 250},
+			opts:     ImpactOptions{QuantityFrom: 10, QuantityTo: 9},
+			expected: ErrInvalidImpactRange,
+		},
+		{
+			name:     "Range too long",
+			proposal: CatalogProposal{Kind: ChangeRemove, Size: 250},
+			opts:     ImpactOptions{QuantityFrom: 1, QuantityTo: MaxImpactQuantities + 1},
+			expected: ErrInvalidImpactRange,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, _ := newImpactService(t, []uint32{250, 500})
+
+			_, err := service.AnalyseImpact(tc.proposal, tc.opts)
+			assert.ErrorIs(t, err, tc.expected)
+		})
+	}
+}
+
+func TestAnalyseImpactRemovingLastSize(t *testing.T) {
+	service, _ := newImpactService(t, []uint32{250})
+
+	_, err := service.AnalyseImpact(CatalogProposal{Kind: ChangeRemove, Size: 250}, ImpactOptions{QuantityTo: 10})
+	assert.ErrorIs(t, err, models.ErrNoPackSizes)
+}
+
+func TestAnalyseImpactHistoryTooLarge(t *testing.T) {
+	service, mockCalculationRepo := newImpactService(t, []uint32{250, 500})
+	quantities := make([]models.QuantityCount, MaxImpactQuantities+1)
+	for i := range quantities {
+		quantities[i] = models.QuantityCount{Quantity: uint64(i + 1), Count: 1}
+	}
+	mockCalculationRepo.EXPECT().GetQuantityDistribution(gomock.Any()).Return(quantities, nil).Times(1)
+
+	_, err := service.AnalyseImpact(CatalogProposal{Kind: ChangeRemove, Size: 250}, ImpactOptions{})
+	assert.ErrorIs(t, err, ErrInvalidImpactRange)
+}
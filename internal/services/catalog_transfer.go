@@ -0,0 +1,341 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/klemis/packs-calculator/models"
+)
+
+// Catalog file formats supported by import and export.
+const (
+	CatalogFormatCSV  = "csv"
+	CatalogFormatJSON = "json"
+)
+
+// Catalog import modes.
+const (
+	// ImportModeMerge adds the imported sizes and updates existing ones, leaving the others untouched.
+	ImportModeMerge = "merge"
+	// ImportModeReplace makes the imported sizes the whole catalog.
+	ImportModeReplace = "replace"
+)
+
+// catalogColumns are the columns of an exported catalog. Only size is required on import.
+var catalogColumns = []string{"size", "price", "weight", "handling_cost"}
+
+// ImportOptions tunes how ImportPackSizes applies an imported catalog.
+type ImportOptions struct {
+	// Format is CatalogFormatCSV or CatalogFormatJSON.
+	Format string
+	// Mode is ImportModeMerge or ImportModeReplace; empty selects ImportModeMerge.
+	Mode string
+	// DryRun reports what the import would change without changing anything.
+	DryRun bool
+	// Actor names who imports the catalog in the audit log.
+	Actor string
+}
+
+// CatalogImportError reports every line of an imported catalog that was rejected.
+type CatalogImportError struct {
+	Lines []models.ImportLineError
+}
+
+func (e *CatalogImportError) Error() string {
+	return fmt.Sprintf("catalog rejected: %d invalid lines", len(e.Lines))
+}
+
+// encodeCatalog writes packs to w in the given format, in a form ImportPackSizes reads back.
+func encodeCatalog(w io.Writer, format string, packs []models.PackSize) error {
+	switch format {
+	case CatalogFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(catalogColumns); err != nil {
+			return err
+		}
+		for _, pack := range packs {
+			err := writer.Write([]string{
+				strconv.FormatUint(uint64(pack.Size), 10),
+				strconv.FormatFloat(pack.Price, 'f', -1, 64),
+				strconv.FormatFloat(pack.Weight, 'f', -1, 64),
+				strconv.FormatFloat(pack.HandlingCost, 'f', -1, 64),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	case CatalogFormatJSON:
+		doc := models.PackSizesReplaceRequest{Packs: make([]models.PackSizeRequest, 0, len(packs))}
+		for _, pack := range packs {
+			doc.Packs = append(doc.Packs, models.PackSizeRequest{
+				Size:         pack.Size,
+				Price:        pack.Price,
+				Weight:       pack.Weight,
+				HandlingCost: pack.HandlingCost,
+			})
+		}
+		return json.NewEncoder(w).Encode(doc)
+	default:
+		return fmt.Errorf("%w: unknown format %q", models.ErrInvalidCatalog, format)
+	}
+}
+
+// decodeCatalog reads the pack sizes of a catalog file. Every invalid line is reported in a
+// CatalogImportError; a file that cannot be read at all is reported as models.ErrInvalidCatalog.
+func decodeCatalog(r io.Reader, format string) ([]models.PackSize, error) {
+	parser := &catalogParser{lines: make(map[uint32]int)}
+
+	var err error
+	switch format {
+	case CatalogFormatCSV:
+		err = parser.parseCSV(r)
+	case CatalogFormatJSON:
+		err = parser.parseJSON(r)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", models.ErrInvalidCatalog, err)
+	}
+
+	if len(parser.errors) > 0 {
+		return nil, &CatalogImportError{Lines: parser.errors}
+	}
+	if len(parser.packs) == 0 {
+		return nil, fmt.Errorf("%w: no pack sizes", models.ErrInvalidCatalog)
+	}
+
+	return parser.packs, nil
+}
+
+// catalogParser collects the pack sizes and line errors of a catalog file.
+type catalogParser struct {
+	packs []models.PackSize
+	// lines maps each size read to the line it was read from, to report duplicates.
+	lines  map[uint32]int
+	errors []models.ImportLineError
+}
+
+// parseCSV reads a CSV catalog whose first line names the columns.
+func (p *catalogParser) parseCSV(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		header[i] = column
+		switch {
+		case !isCatalogColumn(column):
+			p.fail(1, column, "is not a known column")
+		case seen[column]:
+			p.fail(1, column, "appears more than once")
+		}
+		seen[column] = true
+	}
+	if !seen["size"] {
+		p.fail(1, "size", "column is required")
+	}
+	if len(p.errors) > 0 {
+		return nil
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(record) != len(header) {
+			p.fail(line, "", fmt.Sprintf("has %d fields, expected %d", len(record), len(header)))
+			continue
+		}
+
+		fields := make(map[string]string, len(record))
+		for i, value := range record {
+			fields[header[i]] = strings.TrimSpace(value)
+		}
+		p.addRow(line, fields)
+	}
+}
+
+// parseJSON reads a JSON catalog in the form of a catalog replacement request. Lines are numbered
+// by position in the packs array, starting from 1.
+func (p *catalogParser) parseJSON(r io.Reader) error {
+	var doc struct {
+		Packs []map[string]json.RawMessage `json:"packs"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return err
+	}
+
+	for i, entry := range doc.Packs {
+		line := i + 1
+		valid := true
+
+		var unknown []string
+		for name := range entry {
+			if !isCatalogColumn(name) {
+				unknown = append(unknown, name)
+			}
+		}
+		sort.Strings(unknown)
+		for _, name := range unknown {
+			p.fail(line, name, "is not a known field")
+			valid = false
+		}
+
+		fields := make(map[string]string, len(entry))
+		for _, name := range catalogColumns {
+			raw, ok := entry[name]
+			if !ok || string(raw) == "null" {
+				continue
+			}
+
+			var number json.Number
+			if err := json.Unmarshal(raw, &number); err != nil {
+				p.fail(line, name, "must be a number")
+				valid = false
+				continue
+			}
+			fields[name] = number.String()
+		}
+		if valid {
+			p.addRow(line, fields)
+		}
+	}
+
+	return nil
+}
+
+// addRow validates the fields of one catalog line and keeps the pack size they describe.
+func (p *catalogParser) addRow(line int, fields map[string]string) {
+	var pack models.PackSize
+	valid := true
+
+	if value := fields["size"]; value == "" {
+		p.fail(line, "size", "is required")
+		valid = false
+	} else if size, err := strconv.ParseUint(value, 10, 32); err != nil || size == 0 {
+		p.fail(line, "size", "must be a positive whole number")
+		valid = false
+	} else {
+		pack.Size = uint32(size)
+	}
+
+	for _, field := range []struct {
+		name  string
+		value *float64
+	}{
+		{"price", &pack.Price},
+		{"weight", &pack.Weight},
+		{"handling_cost", &pack.HandlingCost},
+	} {
+		value := fields[field.name]
+		if value == "" {
+			continue
+		}
+
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || number < 0 || math.IsInf(number, 0) {
+			p.fail(line, field.name, "must be a number of at least 0")
+			valid = false
+			continue
+		}
+		*field.value = number
+	}
+
+	if !valid {
+		return
+	}
+	if first, ok := p.lines[pack.Size]; ok {
+		p.fail(line, "size", fmt.Sprintf("duplicates line %d", first))
+		return
+	}
+
+	p.lines[pack.Size] = line
+	p.packs = append(p.packs, pack)
+}
+
+// fail records why a line was rejected.
+func (p *catalogParser) fail(line int, field, message string) {
+	p.errors = append(p.errors, models.ImportLineError{Line: line, Field: field, Message: message})
+}
+
+// isCatalogColumn reports whether name is one of the catalogColumns.
+func isCatalogColumn(name string) bool {
+	for _, column := range catalogColumns {
+		if name == column {
+			return true
+		}
+	}
+
+	return false
+}
+
+// diffCatalog reports how importing packs changes the current catalog. Only a replacement removes sizes.
+func diffCatalog(current, packs []models.PackSize, replace bool) *models.ImportReport {
+	report := &models.ImportReport{
+		Rows:      len(packs),
+		Added:     []uint32{},
+		Updated:   []uint32{},
+		Unchanged: []uint32{},
+		Removed:   []uint32{},
+	}
+
+	existing := make(map[uint32]models.PackSize, len(current))
+	for _, pack := range current {
+		existing[pack.Size] = pack
+	}
+
+	imported := make(map[uint32]bool, len(packs))
+	for _, pack := range packs {
+		imported[pack.Size] = true
+
+		old, ok := existing[pack.Size]
+		switch {
+		case !ok:
+			report.Added = append(report.Added, pack.Size)
+		case old.Price != pack.Price || old.Weight != pack.Weight || old.HandlingCost != pack.HandlingCost:
+			report.Updated = append(report.Updated, pack.Size)
+		default:
+			report.Unchanged = append(report.Unchanged, pack.Size)
+		}
+	}
+
+	if replace {
+		for _, pack := range current {
+			if !imported[pack.Size] {
+				report.Removed = append(report.Removed, pack.Size)
+			}
+		}
+	}
+
+	for _, sizes := range [][]uint32{report.Added, report.Updated, report.Unchanged, report.Removed} {
+		sort.Slice(sizes, func(i, j int) bool { return sizes[i] > sizes[j] })
+	}
+
+	return report
+}
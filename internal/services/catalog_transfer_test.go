@@ -0,0 +1,223 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/repositories/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeCatalog(t *testing.T) {
+	testCases := []struct {
+		name          string
+		format        string
+		file          string
+		expected      []models.PackSize
+		expectedLines []models.ImportLineError
+		expectedError string
+	}{
+		{
+			name:   "CSV catalog",
+			format: CatalogFormatCSV,
+			file:   "size,price,weight,handling_cost\n1000,7.5,1.2,0.5\n250,,,\n",
+			expected: []models.PackSize{
+				{Size: 1000, Price: 7.5, Weight: 1.2, HandlingCost: 0.5},
+				{Size: 250},
+			},
+		},
+		{
+			name:     "CSV catalog with only sizes, in any case and order",
+			format:   CatalogFormatCSV,
+			file:     "Price, Size\n2.5, 250\n",
+			expected: []models.PackSize{{Size: 250, Price: 2.5}},
+		},
+		{
+			name:   "CSV catalog with invalid lines",
+			format: CatalogFormatCSV,
+			file:   "size,price\n250,2.5\n0,1\nlarge,-1\n250,3\n500\n",
+			expectedLines: []models.ImportLineError{
+				{Line: 3, Field: "size", Message: "must be a positive whole number"},
+				{Line: 4, Field: "size", Message: "must be a positive whole number"},
+				{Line: 4, Field: "price", Message: "must be a number of at least 0"},
+				{Line: 5, Field: "size", Message: "duplicates line 2"},
+				{Line: 6, Message: "has 1 fields, expected 2"},
+			},
+		},
+		{
+			name:   "CSV catalog with an invalid header",
+			format: CatalogFormatCSV,
+			file:   "price,colour,price\n2.5,red,3\n",
+			expectedLines: []models.ImportLineError{
+				{Line: 1, Field: "colour", Message: "is not a known column"},
+				{Line: 1, Field: "price", Message: "appears more than once"},
+				{Line: 1, Field: "size", Message: "column is required"},
+			},
+		},
+		{
+			name:          "Empty CSV catalog",
+			format:        CatalogFormatCSV,
+			file:          "size,price\n",
+			expectedError: "invalid catalog file: no pack sizes",
+		},
+		{
+			name:   "JSON catalog",
+			format: CatalogFormatJSON,
+			file:   `{"packs": [{"size": 1000, "price": 7.5, "weight": null}, {"size": "250", "handling_cost": 0.5}]}`,
+			expected: []models.PackSize{
+				{Size: 1000, Price: 7.5},
+				{Size: 250, HandlingCost: 0.5},
+			},
+		},
+		{
+			name:   "JSON catalog with invalid entries",
+			format: CatalogFormatJSON,
+			file:   `{"packs": [{"size": 2.5}, {"size": 500, "price": "cheap", "colour": "red"}, {"price": 1}]}`,
+			expectedLines: []models.ImportLineError{
+				{Line: 1, Field: "size", Message: "must be a positive whole number"},
+				{Line: 2, Field: "colour", Message: "is not a known field"},
+				{Line: 2, Field: "price", Message: "must be a number"},
+				{Line: 3, Field: "size", Message: "is required"},
+			},
+		},
+		{
+			name:          "Malformed JSON catalog",
+			format:        CatalogFormatJSON,
+			file:          `{"packs": [`,
+			expectedError: "invalid catalog file: unexpected EOF",
+		},
+		{
+			name:          "Unknown format",
+			format:        "xlsx",
+			file:          "size\n250\n",
+			expectedError: `invalid catalog file: unknown format "xlsx"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			packs, err := decodeCatalog(strings.NewReader(tc.file), tc.format)
+
+			var importErr *CatalogImportError
+			switch {
+			case tc.expectedLines != nil:
+				assert.ErrorAs(t, err, &importErr)
+				assert.Equal(t, tc.expectedLines, importErr.Lines)
+			case tc.expectedError != "":
+				assert.EqualError(t, err, tc.expectedError)
+				assert.ErrorIs(t, err, models.ErrInvalidCatalog)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, packs)
+			}
+		})
+	}
+}
+
+func TestEncodeCatalogRoundTrip(t *testing.T) {
+	packs := []models.PackSize{{Size: 1000, Price: 7.5, Weight: 1.2, HandlingCost: 0.5}, {Size: 250}}
+
+	for _, format := range []string{CatalogFormatCSV, CatalogFormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			var file bytes.Buffer
+			assert.NoError(t, encodeCatalog(&file, format, packs))
+
+			decoded, err := decodeCatalog(&file, format)
+			assert.NoError(t, err)
+			assert.Equal(t, packs, decoded)
+		})
+	}
+}
+
+func TestImportPackSizes(t *testing.T) {
+	current := []models.PackSize{
+		{ID: 1, Size: 1000, Price: 7},
+		{ID: 2, Size: 500, Price: 4},
+		{ID: 3, Size: 250, Price: 2.5},
+	}
+	file := "size,price\n1000,7\n500,4.5\n750,6\n"
+	imported := []models.PackSize{{Size: 1000, Price: 7}, {Size: 500, Price: 4.5}, {Size: 750, Price: 6}}
+
+	testCases := []struct {
+		name      string
+		opts      ImportOptions
+		mockSetup func(repo *mocks.MockPackSizeRepository)
+		expected  *models.ImportReport
+	}{
+		{
+			name: "Merge",
+			opts: ImportOptions{Format: CatalogFormatCSV, Actor: "alice"},
+			mockSetup: func(repo *mocks.MockPackSizeRepository) {
+				repo.EXPECT().MergePackSizes(imported, "alice").Return(nil).Times(1)
+			},
+			expected: &models.ImportReport{
+				Mode: ImportModeMerge, Rows: 3, Added: []uint32{750}, Updated: []uint32{500},
+				Unchanged: []uint32{1000}, Removed: []uint32{},
+			},
+		},
+		{
+			name: "Replace",
+			opts: ImportOptions{Format: CatalogFormatCSV, Mode: ImportModeReplace, Actor: "alice"},
+			mockSetup: func(repo *mocks.MockPackSizeRepository) {
+				repo.EXPECT().ReplacePackSizes(imported, "alice").Return(&models.CatalogChange{}, nil).Times(1)
+			},
+			expected: &models.ImportReport{
+				Mode: ImportModeReplace, Rows: 3, Added: []uint32{750}, Updated: []uint32{500},
+				Unchanged: []uint32{1000}, Removed: []uint32{250},
+			},
+		},
+		{
+			name:      "Dry run",
+			opts:      ImportOptions{Format: CatalogFormatCSV, Mode: ImportModeReplace, DryRun: true, Actor: "alice"},
+			mockSetup: func(repo *mocks.MockPackSizeRepository) {},
+			expected: &models.ImportReport{
+				Mode: ImportModeReplace, DryRun: true, Rows: 3, Added: []uint32{750}, Updated: []uint32{500},
+				Unchanged: []uint32{1000}, Removed: []uint32{250},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+			mockRepo.EXPECT().GetPackSizes().Return(current, nil).Times(1)
+			tc.mockSetup(mockRepo)
+
+			service := NewPacksCalculatorService(mockRepo, nil, nil)
+
+			report, err := service.ImportPackSizes(strings.NewReader(file), tc.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, report)
+		})
+	}
+}
+
+func TestImportPackSizesUnchanged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockRepo.EXPECT().GetPackSizes().Return([]models.PackSize{{ID: 1, Size: 250, Price: 2.5}}, nil).Times(1)
+
+	service := NewPacksCalculatorService(mockRepo, nil, nil)
+
+	// Nothing changes, so no catalog version is recorded.
+	report, err := service.ImportPackSizes(strings.NewReader("size,price\n250,2.5\n"), ImportOptions{Format: CatalogFormatCSV})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{250}, report.Unchanged)
+}
+
+func TestImportPackSizesInvalidFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+
+	service := NewPacksCalculatorService(mockRepo, nil, nil)
+
+	report, err := service.ImportPackSizes(strings.NewReader("size\n0\n"), ImportOptions{Format: CatalogFormatCSV})
+	var importErr *CatalogImportError
+	assert.True(t, errors.As(err, &importErr))
+	assert.Nil(t, report)
+}
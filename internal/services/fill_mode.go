@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// Names of the fill modes, which bound how far the packs may stray from the order quantity.
+const (
+	// FillOverfill covers the order, shipping more items than ordered when the pack sizes require it.
+	FillOverfill = "overfill"
+	// FillExact ships exactly the items ordered, or nothing at all.
+	FillExact = "exact"
+	// FillUnderfill never ships more items than ordered and ships as many as it can.
+	FillUnderfill = "underfill"
+	// FillTolerance covers the order with no more overfill than the tolerance allows.
+	FillTolerance = "tolerance"
+)
+
+var (
+	// ErrInvalidFillMode is returned when a calculation asks for an unknown fill mode, or a tolerance
+	// without a maximum overfill.
+	ErrInvalidFillMode = errors.New("invalid fill mode")
+	// ErrNoExactFit is returned in exact mode when no pack combination adds up to the order quantity.
+	ErrNoExactFit = errors.New("no pack combination matches the quantity exactly")
+	// ErrOverfillExceeded is returned in tolerance mode when every pack combination covering the order
+	// ships more items than the tolerance allows.
+	ErrOverfillExceeded = errors.New("no pack combination covers the quantity within the overfill tolerance")
+)
+
+// FillMode bounds the number of items a pack combination may add up to.
+type FillMode struct {
+	// Name is one of the fill mode names; empty selects FillOverfill.
+	Name string
+	// MaxOverfill caps the items shipped beyond the quantity in tolerance mode.
+	MaxOverfill *uint64
+	// MaxOverfillPercent caps the items shipped beyond the quantity in tolerance mode, as a percentage
+	// of the quantity. When both caps are set, the stricter one applies.
+	MaxOverfillPercent *float64
+}
+
+// validate checks that the fill mode is known and that a tolerance has a maximum overfill.
+func (m FillMode) validate() error {
+	switch m.Name {
+	case "", FillOverfill, FillExact, FillUnderfill:
+		return nil
+	case FillTolerance:
+		if m.MaxOverfill == nil && m.MaxOverfillPercent == nil {
+			return fmt.Errorf("%w: a tolerance needs a maximum overfill", ErrInvalidFillMode)
+		}
+		if m.MaxOverfillPercent != nil && *m.MaxOverfillPercent < 0 {
+			return fmt.Errorf("%w: the maximum overfill percentage cannot be negative", ErrInvalidFillMode)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidFillMode, m.Name)
+	}
+}
+
+// bounds returns the smallest and largest totals the packs may add up to for an order of quantity
+// items, given the largest pack size. Beyond quantity + largest - 1, a combination always has a pack
+// it can drop, so no mode looks further. The largest total stops at math.MaxUint64.
+func (m FillMode) bounds(quantity uint64, largest uint32) (low, high uint64) {
+	low, high = quantity, addCapped(quantity, uint64(largest)-1)
+	switch m.Name {
+	case FillExact:
+		high = low
+	case FillUnderfill:
+		low, high = 0, quantity
+	case FillTolerance:
+		if m.MaxOverfill != nil {
+			high = min(high, addCapped(low, *m.MaxOverfill))
+		}
+		if m.MaxOverfillPercent != nil {
+			overfill := float64(quantity) * *m.MaxOverfillPercent / 100
+			if overfill < math.MaxUint64 {
+				high = min(high, addCapped(low, uint64(overfill)))
+			}
+		}
+	}
+
+	return low, high
+}
+
+// addCapped returns a + b, or math.MaxUint64 when the sum overflows.
+func addCapped(a, b uint64) uint64 {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return math.MaxUint64
+	}
+
+	return sum
+}
+
+// noFit returns the error for an order that no pack combination within the bounds of the mode covers.
+func (m FillMode) noFit() error {
+	if m.Name == FillExact {
+		return ErrNoExactFit
+	}
+
+	return ErrOverfillExceeded
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reachableTotals returns every total below bound that packs within stock add up to exactly.
+// A nil stock is unlimited.
+func reachableTotals(sizes []uint32, stock map[uint32]uint32, bound uint32) map[uint32]bool {
+	reachable := make(map[uint32]bool)
+
+	var walk func(i int, total uint32)
+	walk = func(i int, total uint32) {
+		if i == len(sizes) {
+			reachable[total] = true
+			return
+		}
+		for n := uint32(0); total+n*sizes[i] < bound; n++ {
+			if limit, ok := stock[sizes[i]]; ok && n > limit {
+				break
+			}
+			walk(i+1, total+n*sizes[i])
+		}
+	}
+	walk(0, 0)
+
+	return reachable
+}
+
+func TestStrategiesFillModesMatchBruteForce(t *testing.T) {
+	maxOverfill := uint64(10)
+
+	for _, strategy := range []PackingStrategy{OptimalStrategy{}, FewestPacksStrategy{}, MinCostStrategy{}} {
+		t.Run(strategy.Name(), func(t *testing.T) {
+			for _, sizes := range oraclePackSets {
+				limited := make(map[uint32]uint32, len(sizes))
+				var largest uint32
+				for i, size := range sizes {
+					limited[size] = uint32(i + 2)
+					largest = max(largest, size)
+				}
+
+				for _, stock := range []map[uint32]uint32{nil, limited} {
+					for orderQty := uint32(1); orderQty <= 300; orderQty++ {
+						msg := fmt.Sprintf("sizes %v, stock %v, quantity %d", sizes, stock, orderQty)
+						reachable := reachableTotals(sizes, stock, orderQty+largest)
+						problem := PackingProblem{PackSizes: toPackSizes(sizes), Quantity: uint64(orderQty), Stock: stock}
+
+						// Exact mode ships the quantity or fails.
+						problem.Mode = FillMode{Name: FillExact}
+						result, err := strategy.Pack(problem)
+						if reachable[orderQty] {
+							items, _ := totals(result)
+							assert.NoError(t, err, msg)
+							assert.Equal(t, orderQty, items, msg)
+						} else {
+							var stockErr *InsufficientStockError
+							assert.True(t, errors.Is(err, ErrNoExactFit) || errors.As(err, &stockErr), msg)
+						}
+
+						// Underfill mode ships the most items not above the quantity.
+						problem.Mode = FillMode{Name: FillUnderfill}
+						result, err = strategy.Pack(problem)
+						expected := orderQty
+						for !reachable[expected] {
+							expected--
+						}
+						items, _ := totals(result)
+						assert.NoError(t, err, msg)
+						assert.Equal(t, expected, items, msg)
+
+						// Tolerance mode covers the quantity with the overfill capped.
+						problem.Mode = FillMode{Name: FillTolerance, MaxOverfill: &maxOverfill}
+						result, err = strategy.Pack(problem)
+						cover := orderQty
+						for cover < orderQty+largest && !reachable[cover] {
+							cover++
+						}
+						var stockErr *InsufficientStockError
+						switch {
+						case cover == orderQty+largest:
+							assert.ErrorAs(t, err, &stockErr, msg)
+						case uint64(cover-orderQty) > maxOverfill:
+							assert.ErrorIs(t, err, ErrOverfillExceeded, msg)
+						default:
+							items, _ := totals(result)
+							assert.NoError(t, err, msg)
+							assert.GreaterOrEqual(t, items, orderQty, msg)
+							assert.LessOrEqual(t, uint64(items-orderQty), maxOverfill, msg)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGreedyStrategyFillModes(t *testing.T) {
+	packSizes := toPackSizes([]uint32{250, 500, 1000})
+	tenPercent := 10.0
+
+	testCases := []struct {
+		name          string
+		quantity      uint64
+		mode          FillMode
+		expected      map[uint32]uint64
+		expectedError error
+	}{
+		{name: "Exact fit", quantity: 1750, mode: FillMode{Name: FillExact}, expected: map[uint32]uint64{1000: 1, 500: 1, 250: 1}},
+		{name: "No exact fit", quantity: 1751, mode: FillMode{Name: FillExact}, expectedError: ErrNoExactFit},
+		{name: "Underfill", quantity: 1999, mode: FillMode{Name: FillUnderfill}, expected: map[uint32]uint64{1000: 1, 500: 1, 250: 1}},
+		{name: "Within tolerance", quantity: 1900, mode: FillMode{Name: FillTolerance, MaxOverfillPercent: &tenPercent}, expected: map[uint32]uint64{1000: 1, 500: 1, 250: 2}},
+		{name: "Beyond tolerance", quantity: 1010, mode: FillMode{Name: FillTolerance, MaxOverfillPercent: &tenPercent}, expectedError: ErrOverfillExceeded},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := GreedyStrategy{}.Pack(PackingProblem{PackSizes: packSizes, Quantity: tc.quantity, Mode: tc.mode})
+			assert.ErrorIs(t, err, tc.expectedError)
+			if tc.expectedError == nil {
+				assert.Equal(t, tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestUnderfillIgnoresStockShortage(t *testing.T) {
+	problem := PackingProblem{
+		PackSizes: toPackSizes([]uint32{250, 500, 1000}),
+		Quantity:  2000,
+		Stock:     map[uint32]uint32{1000: 1, 500: 1, 250: 1},
+		Mode:      FillMode{Name: FillUnderfill},
+	}
+
+	for _, name := range StrategyNames() {
+		t.Run(name, func(t *testing.T) {
+			strategy, err := GetStrategy(name)
+			assert.NoError(t, err)
+
+			result, err := strategy.Pack(problem)
+			assert.NoError(t, err)
+			assert.Equal(t, map[uint32]uint64{1000: 1, 500: 1, 250: 1}, result)
+		})
+	}
+}
+
+func TestFillModeValidate(t *testing.T) {
+	items := uint64(5)
+	percent := 2.5
+	negative := -1.0
+
+	testCases := []struct {
+		name          string
+		mode          FillMode
+		expectedError string
+	}{
+		{name: "Default", mode: FillMode{}},
+		{name: "Exact", mode: FillMode{Name: FillExact}},
+		{name: "Tolerance in items", mode: FillMode{Name: FillTolerance, MaxOverfill: &items}},
+		{name: "Tolerance in percent", mode: FillMode{Name: FillTolerance, MaxOverfillPercent: &percent}},
+		{name: "Tolerance without a cap", mode: FillMode{Name: FillTolerance}, expectedError: "invalid fill mode: a tolerance needs a maximum overfill"},
+		{name: "Negative percentage", mode: FillMode{Name: FillTolerance, MaxOverfillPercent: &negative}, expectedError: "invalid fill mode: the maximum overfill percentage cannot be negative"},
+		{name: "Unknown", mode: FillMode{Name: "round"}, expectedError: `invalid fill mode: "round"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.mode.validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidFillMode)
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestFillModeBounds(t *testing.T) {
+	items := uint64(30)
+	percent := 10.0
+
+	testCases := []struct {
+		name         string
+		mode         FillMode
+		expectedLow  uint64
+		expectedHigh uint64
+	}{
+		{name: "Overfill", mode: FillMode{}, expectedLow: 200, expectedHigh: 252},
+		{name: "Exact", mode: FillMode{Name: FillExact}, expectedLow: 200, expectedHigh: 200},
+		{name: "Underfill", mode: FillMode{Name: FillUnderfill}, expectedLow: 0, expectedHigh: 200},
+		{name: "Tolerance in items", mode: FillMode{Name: FillTolerance, MaxOverfill: &items}, expectedLow: 200, expectedHigh: 230},
+		{name: "Stricter tolerance applies", mode: FillMode{Name: FillTolerance, MaxOverfill: &items, MaxOverfillPercent: &percent}, expectedLow: 200, expectedHigh: 220},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			low, high := tc.mode.bounds(200, 53)
+			assert.Equal(t, tc.expectedLow, low)
+			assert.Equal(t, tc.expectedHigh, high)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"github.com/klemis/packs-calculator/internal/repositories"
+	"github.com/klemis/packs-calculator/models"
+)
+
+// IdempotencyGuard defines the interface for making retried requests take effect only once.
+type IdempotencyGuard interface {
+	Begin(key, requestHash string) (*models.StoredResponse, error)
+	Complete(key string, response models.StoredResponse) error
+	Abandon(key string) error
+}
+
+// IdempotencyService is an implementation of IdempotencyGuard.
+type IdempotencyService struct {
+	repo repositories.IdempotencyRepository
+}
+
+// NewIdempotencyService creates a new instance of IdempotencyGuard with an injected repository.
+func NewIdempotencyService(idempotencyRepo repositories.IdempotencyRepository) IdempotencyGuard {
+	return &IdempotencyService{
+		repo: idempotencyRepo,
+	}
+}
+
+// Begin claims key for the request with the given hash. It returns nil when the request should be
+// handled, or the stored response to replay when the same request was already handled with key.
+// A key reused for a different request, or whose first request is still being handled, is rejected.
+func (s *IdempotencyService) Begin(key, requestHash string) (*models.StoredResponse, error) {
+	record, err := s.repo.ReserveKey(key, requestHash)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, nil
+	}
+
+	if record.RequestHash != requestHash {
+		return nil, models.ErrIdempotencyKeyReused
+	}
+	if record.Response == nil {
+		return nil, models.ErrIdempotencyKeyInProgress
+	}
+
+	return record.Response, nil
+}
+
+// Complete stores the response to the request that claimed key, to be replayed on retries.
+func (s *IdempotencyService) Complete(key string, response models.StoredResponse) error {
+	return s.repo.SaveResponse(key, response)
+}
+
+// Abandon frees key after its request failed, so that a retry is handled afresh.
+func (s *IdempotencyService) Abandon(key string) error {
+	return s.repo.ReleaseKey(key)
+}
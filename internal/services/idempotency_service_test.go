@@ -0,0 +1,66 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/repositories/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyBegin(t *testing.T) {
+	stored := &models.StoredResponse{Status: 200, ContentType: "application/json", Body: []byte(`{}`)}
+
+	testCases := []struct {
+		name        string
+		mockRecord  *models.IdempotencyRecord
+		mockError   error
+		expected    *models.StoredResponse
+		expectedErr error
+	}{
+		{
+			name: "New key",
+		},
+		{
+			name:       "Retry of a handled request",
+			mockRecord: &models.IdempotencyRecord{Key: "order-7", RequestHash: "abc", Response: stored},
+			expected:   stored,
+		},
+		{
+			name:        "Key reused for a different request",
+			mockRecord:  &models.IdempotencyRecord{Key: "order-7", RequestHash: "def", Response: stored},
+			expectedErr: models.ErrIdempotencyKeyReused,
+		},
+		{
+			name:        "Retry while the first request is in progress",
+			mockRecord:  &models.IdempotencyRecord{Key: "order-7", RequestHash: "abc"},
+			expectedErr: models.ErrIdempotencyKeyInProgress,
+		},
+		{
+			name:        "Repository error",
+			mockError:   errors.New("query error"),
+			expectedErr: errors.New("query error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := mocks.NewMockIdempotencyRepository(ctrl)
+			mockRepo.EXPECT().ReserveKey("order-7", "abc").Return(tc.mockRecord, tc.mockError).Times(1)
+
+			service := NewIdempotencyService(mockRepo)
+
+			response, err := service.Begin("order-7", "abc")
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expected, response)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/calculation_history_service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/klemis/packs-calculator/models"
+)
+
+// MockCalculationHistory is a mock of CalculationHistory interface.
+type MockCalculationHistory struct {
+	ctrl     *gomock.Controller
+	recorder *MockCalculationHistoryMockRecorder
+}
+
+// MockCalculationHistoryMockRecorder is the mock recorder for MockCalculationHistory.
+type MockCalculationHistoryMockRecorder struct {
+	mock *MockCalculationHistory
+}
+
+// NewMockCalculationHistory creates a new mock instance.
+func NewMockCalculationHistory(ctrl *gomock.Controller) *MockCalculationHistory {
+	mock := &MockCalculationHistory{ctrl: ctrl}
+	mock.recorder = &MockCalculationHistoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCalculationHistory) EXPECT() *MockCalculationHistoryMockRecorder {
+	return m.recorder
+}
+
+// GetCalculation mocks base method.
+func (m *MockCalculationHistory) GetCalculation(id uint64) (*models.CalculationRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCalculation", id)
+	ret0, _ := ret[0].(*models.CalculationRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCalculation indicates an expected call of GetCalculation.
+func (mr *MockCalculationHistoryMockRecorder) GetCalculation(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCalculation", reflect.TypeOf((*MockCalculationHistory)(nil).GetCalculation), id)
+}
+
+// ListCalculations mocks base method.
+func (m *MockCalculationHistory) ListCalculations(filter models.CalculationFilter) (*models.CalculationPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCalculations", filter)
+	ret0, _ := ret[0].(*models.CalculationPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCalculations indicates an expected call of ListCalculations.
+func (mr *MockCalculationHistoryMockRecorder) ListCalculations(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCalculations", reflect.TypeOf((*MockCalculationHistory)(nil).ListCalculations), filter)
+}
@@ -0,0 +1,78 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/idempotency_service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/klemis/packs-calculator/models"
+)
+
+// MockIdempotencyGuard is a mock of IdempotencyGuard interface.
+type MockIdempotencyGuard struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyGuardMockRecorder
+}
+
+// MockIdempotencyGuardMockRecorder is the mock recorder for MockIdempotencyGuard.
+type MockIdempotencyGuardMockRecorder struct {
+	mock *MockIdempotencyGuard
+}
+
+// NewMockIdempotencyGuard creates a new mock instance.
+func NewMockIdempotencyGuard(ctrl *gomock.Controller) *MockIdempotencyGuard {
+	mock := &MockIdempotencyGuard{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyGuardMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyGuard) EXPECT() *MockIdempotencyGuardMockRecorder {
+	return m.recorder
+}
+
+// Abandon mocks base method.
+func (m *MockIdempotencyGuard) Abandon(key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Abandon", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Abandon indicates an expected call of Abandon.
+func (mr *MockIdempotencyGuardMockRecorder) Abandon(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Abandon", reflect.TypeOf((*MockIdempotencyGuard)(nil).Abandon), key)
+}
+
+// Begin mocks base method.
+func (m *MockIdempotencyGuard) Begin(key, requestHash string) (*models.StoredResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", key, requestHash)
+	ret0, _ := ret[0].(*models.StoredResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockIdempotencyGuardMockRecorder) Begin(key, requestHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockIdempotencyGuard)(nil).Begin), key, requestHash)
+}
+
+// Complete mocks base method.
+func (m *MockIdempotencyGuard) Complete(key string, response models.StoredResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", key, response)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockIdempotencyGuardMockRecorder) Complete(key, response interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockIdempotencyGuard)(nil).Complete), key, response)
+}
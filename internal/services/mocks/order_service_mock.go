@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/order_service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	services "github.com/klemis/packs-calculator/internal/services"
+	models "github.com/klemis/packs-calculator/models"
+)
+
+// MockOrderManager is a mock of OrderManager interface.
+type MockOrderManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderManagerMockRecorder
+}
+
+// MockOrderManagerMockRecorder is the mock recorder for MockOrderManager.
+type MockOrderManagerMockRecorder struct {
+	mock *MockOrderManager
+}
+
+// NewMockOrderManager creates a new mock instance.
+func NewMockOrderManager(ctrl *gomock.Controller) *MockOrderManager {
+	mock := &MockOrderManager{ctrl: ctrl}
+	mock.recorder = &MockOrderManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderManager) EXPECT() *MockOrderManagerMockRecorder {
+	return m.recorder
+}
+
+// CreateOrder mocks base method.
+func (m *MockOrderManager) CreateOrder(quantity uint64, opts services.CalculateOptions) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrder", quantity, opts)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrder indicates an expected call of CreateOrder.
+func (mr *MockOrderManagerMockRecorder) CreateOrder(quantity, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockOrderManager)(nil).CreateOrder), quantity, opts)
+}
+
+// GetOrder mocks base method.
+func (m *MockOrderManager) GetOrder(id uint64) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", id)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockOrderManagerMockRecorder) GetOrder(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockOrderManager)(nil).GetOrder), id)
+}
+
+// ListOrders mocks base method.
+func (m *MockOrderManager) ListOrders(status models.OrderStatus) ([]models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrders", status)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrders indicates an expected call of ListOrders.
+func (mr *MockOrderManagerMockRecorder) ListOrders(status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockOrderManager)(nil).ListOrders), status)
+}
+
+// TransitionOrder mocks base method.
+func (m *MockOrderManager) TransitionOrder(id uint64, to models.OrderStatus) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransitionOrder", id, to)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransitionOrder indicates an expected call of TransitionOrder.
+func (mr *MockOrderManagerMockRecorder) TransitionOrder(id, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitionOrder", reflect.TypeOf((*MockOrderManager)(nil).TransitionOrder), id, to)
+}
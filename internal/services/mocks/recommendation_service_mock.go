@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/recommendation_service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	services "github.com/klemis/packs-calculator/internal/services"
+	models "github.com/klemis/packs-calculator/models"
+)
+
+// MockPackRecommender is a mock of PackRecommender interface.
+type MockPackRecommender struct {
+	ctrl     *gomock.Controller
+	recorder *MockPackRecommenderMockRecorder
+}
+
+// MockPackRecommenderMockRecorder is the mock recorder for MockPackRecommender.
+type MockPackRecommenderMockRecorder struct {
+	mock *MockPackRecommender
+}
+
+// NewMockPackRecommender creates a new mock instance.
+func NewMockPackRecommender(ctrl *gomock.Controller) *MockPackRecommender {
+	mock := &MockPackRecommender{ctrl: ctrl}
+	mock.recorder = &MockPackRecommenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPackRecommender) EXPECT() *MockPackRecommenderMockRecorder {
+	return m.recorder
+}
+
+// RecommendPackSizes mocks base method.
+func (m *MockPackRecommender) RecommendPackSizes(opts services.RecommendationOptions) (*models.PackRecommendation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecommendPackSizes", opts)
+	ret0, _ := ret[0].(*models.PackRecommendation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecommendPackSizes indicates an expected call of RecommendPackSizes.
+func (mr *MockPackRecommenderMockRecorder) RecommendPackSizes(opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecommendPackSizes", reflect.TypeOf((*MockPackRecommender)(nil).RecommendPackSizes), opts)
+}
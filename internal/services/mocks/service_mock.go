@@ -5,9 +5,12 @@
 package mocks
 
 import (
+	io "io"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	services "github.com/klemis/packs-calculator/internal/services"
+	models "github.com/klemis/packs-calculator/models"
 )
 
 // MockPacksCalculator is a mock of PacksCalculator interface.
@@ -34,44 +37,235 @@ func (m *MockPacksCalculator) EXPECT() *MockPacksCalculatorMockRecorder {
 }
 
 // AddPackSize mocks base method.
-func (m *MockPacksCalculator) AddPackSize(size uint32) error {
+func (m *MockPacksCalculator) AddPackSize(pack models.PackSize, actor string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddPackSize", size)
+	ret := m.ctrl.Call(m, "AddPackSize", pack, actor)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // AddPackSize indicates an expected call of AddPackSize.
-func (mr *MockPacksCalculatorMockRecorder) AddPackSize(size interface{}) *gomock.Call {
+func (mr *MockPacksCalculatorMockRecorder) AddPackSize(pack, actor interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPackSize", reflect.TypeOf((*MockPacksCalculator)(nil).AddPackSize), size)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPackSize", reflect.TypeOf((*MockPacksCalculator)(nil).AddPackSize), pack, actor)
+}
+
+// AnalyseImpact mocks base method.
+func (m *MockPacksCalculator) AnalyseImpact(proposal services.CatalogProposal, opts services.ImpactOptions) (*models.CatalogImpact, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnalyseImpact", proposal, opts)
+	ret0, _ := ret[0].(*models.CatalogImpact)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnalyseImpact indicates an expected call of AnalyseImpact.
+func (mr *MockPacksCalculatorMockRecorder) AnalyseImpact(proposal, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnalyseImpact", reflect.TypeOf((*MockPacksCalculator)(nil).AnalyseImpact), proposal, opts)
+}
+
+// AnalysePackSizes mocks base method.
+func (m *MockPacksCalculator) AnalysePackSizes(opts services.AnalysisOptions) (*models.PackSetAnalysis, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnalysePackSizes", opts)
+	ret0, _ := ret[0].(*models.PackSetAnalysis)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnalysePackSizes indicates an expected call of AnalysePackSizes.
+func (mr *MockPacksCalculatorMockRecorder) AnalysePackSizes(opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnalysePackSizes", reflect.TypeOf((*MockPacksCalculator)(nil).AnalysePackSizes), opts)
+}
+
+// CalculateBatch mocks base method.
+func (m *MockPacksCalculator) CalculateBatch(items []services.BatchItem, opts services.CalculateOptions) (*services.BatchCalculation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalculateBatch", items, opts)
+	ret0, _ := ret[0].(*services.BatchCalculation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CalculateBatch indicates an expected call of CalculateBatch.
+func (mr *MockPacksCalculatorMockRecorder) CalculateBatch(items, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalculateBatch", reflect.TypeOf((*MockPacksCalculator)(nil).CalculateBatch), items, opts)
 }
 
 // CalculatePacks mocks base method.
-func (m *MockPacksCalculator) CalculatePacks(orderQty uint32) (map[uint32]uint32, error) {
+func (m *MockPacksCalculator) CalculatePacks(orderQty uint64, opts services.CalculateOptions) (*models.Calculation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CalculatePacks", orderQty)
-	ret0, _ := ret[0].(map[uint32]uint32)
+	ret := m.ctrl.Call(m, "CalculatePacks", orderQty, opts)
+	ret0, _ := ret[0].(*models.Calculation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CalculatePacks indicates an expected call of CalculatePacks.
-func (mr *MockPacksCalculatorMockRecorder) CalculatePacks(orderQty interface{}) *gomock.Call {
+func (mr *MockPacksCalculatorMockRecorder) CalculatePacks(orderQty, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalculatePacks", reflect.TypeOf((*MockPacksCalculator)(nil).CalculatePacks), orderQty, opts)
+}
+
+// CalculateStream mocks base method.
+func (m *MockPacksCalculator) CalculateStream(items services.BatchItemSeq, opts services.CalculateOptions, emit func(services.BatchResult) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalculateStream", items, opts, emit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CalculateStream indicates an expected call of CalculateStream.
+func (mr *MockPacksCalculatorMockRecorder) CalculateStream(items, opts, emit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalculateStream", reflect.TypeOf((*MockPacksCalculator)(nil).CalculateStream), items, opts, emit)
+}
+
+// CompareCalculation mocks base method.
+func (m *MockPacksCalculator) CompareCalculation(orderQty uint64, opts services.CalculateOptions) (*services.CalculationComparison, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareCalculation", orderQty, opts)
+	ret0, _ := ret[0].(*services.CalculationComparison)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompareCalculation indicates an expected call of CompareCalculation.
+func (mr *MockPacksCalculatorMockRecorder) CompareCalculation(orderQty, opts interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalculatePacks", reflect.TypeOf((*MockPacksCalculator)(nil).CalculatePacks), orderQty)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareCalculation", reflect.TypeOf((*MockPacksCalculator)(nil).CompareCalculation), orderQty, opts)
 }
 
 // DeletePackSize mocks base method.
-func (m *MockPacksCalculator) DeletePackSize(size uint32) error {
+func (m *MockPacksCalculator) DeletePackSize(size uint32, actor string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeletePackSize", size)
+	ret := m.ctrl.Call(m, "DeletePackSize", size, actor)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeletePackSize indicates an expected call of DeletePackSize.
-func (mr *MockPacksCalculatorMockRecorder) DeletePackSize(size interface{}) *gomock.Call {
+func (mr *MockPacksCalculatorMockRecorder) DeletePackSize(size, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePackSize", reflect.TypeOf((*MockPacksCalculator)(nil).DeletePackSize), size, actor)
+}
+
+// ExportPackSizes mocks base method.
+func (m *MockPacksCalculator) ExportPackSizes(w io.Writer, format string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportPackSizes", w, format)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportPackSizes indicates an expected call of ExportPackSizes.
+func (mr *MockPacksCalculatorMockRecorder) ExportPackSizes(w, format interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportPackSizes", reflect.TypeOf((*MockPacksCalculator)(nil).ExportPackSizes), w, format)
+}
+
+// GetPackSize mocks base method.
+func (m *MockPacksCalculator) GetPackSize(id uint32) (*models.PackSize, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPackSize", id)
+	ret0, _ := ret[0].(*models.PackSize)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPackSize indicates an expected call of GetPackSize.
+func (mr *MockPacksCalculatorMockRecorder) GetPackSize(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPackSize", reflect.TypeOf((*MockPacksCalculator)(nil).GetPackSize), id)
+}
+
+// ImportPackSizes mocks base method.
+func (m *MockPacksCalculator) ImportPackSizes(r io.Reader, opts services.ImportOptions) (*models.ImportReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportPackSizes", r, opts)
+	ret0, _ := ret[0].(*models.ImportReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportPackSizes indicates an expected call of ImportPackSizes.
+func (mr *MockPacksCalculatorMockRecorder) ImportPackSizes(r, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportPackSizes", reflect.TypeOf((*MockPacksCalculator)(nil).ImportPackSizes), r, opts)
+}
+
+// ListPackSizes mocks base method.
+func (m *MockPacksCalculator) ListPackSizes() ([]models.PackSize, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPackSizes")
+	ret0, _ := ret[0].([]models.PackSize)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPackSizes indicates an expected call of ListPackSizes.
+func (mr *MockPacksCalculatorMockRecorder) ListPackSizes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPackSizes", reflect.TypeOf((*MockPacksCalculator)(nil).ListPackSizes))
+}
+
+// ReplacePackSizes mocks base method.
+func (m *MockPacksCalculator) ReplacePackSizes(packs []models.PackSize, actor string) (*models.CatalogChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplacePackSizes", packs, actor)
+	ret0, _ := ret[0].(*models.CatalogChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplacePackSizes indicates an expected call of ReplacePackSizes.
+func (mr *MockPacksCalculatorMockRecorder) ReplacePackSizes(packs, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplacePackSizes", reflect.TypeOf((*MockPacksCalculator)(nil).ReplacePackSizes), packs, actor)
+}
+
+// SolutionCacheStats mocks base method.
+func (m *MockPacksCalculator) SolutionCacheStats() models.SolutionCacheStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SolutionCacheStats")
+	ret0, _ := ret[0].(models.SolutionCacheStats)
+	return ret0
+}
+
+// SolutionCacheStats indicates an expected call of SolutionCacheStats.
+func (mr *MockPacksCalculatorMockRecorder) SolutionCacheStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SolutionCacheStats", reflect.TypeOf((*MockPacksCalculator)(nil).SolutionCacheStats))
+}
+
+// UpdatePackSize mocks base method.
+func (m *MockPacksCalculator) UpdatePackSize(id uint32, update models.PackSizeUpdateRequest, actor string) (*models.PackSize, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePackSize", id, update, actor)
+	ret0, _ := ret[0].(*models.PackSize)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePackSize indicates an expected call of UpdatePackSize.
+func (mr *MockPacksCalculatorMockRecorder) UpdatePackSize(id, update, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePackSize", reflect.TypeOf((*MockPacksCalculator)(nil).UpdatePackSize), id, update, actor)
+}
+
+// WarmSolutionCache mocks base method.
+func (m *MockPacksCalculator) WarmSolutionCache() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WarmSolutionCache")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WarmSolutionCache indicates an expected call of WarmSolutionCache.
+func (mr *MockPacksCalculatorMockRecorder) WarmSolutionCache() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePackSize", reflect.TypeOf((*MockPacksCalculator)(nil).DeletePackSize), size)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WarmSolutionCache", reflect.TypeOf((*MockPacksCalculator)(nil).WarmSolutionCache))
 }
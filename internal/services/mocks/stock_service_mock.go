@@ -0,0 +1,93 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/stock_service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/klemis/packs-calculator/models"
+)
+
+// MockStockManager is a mock of StockManager interface.
+type MockStockManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockStockManagerMockRecorder
+}
+
+// MockStockManagerMockRecorder is the mock recorder for MockStockManager.
+type MockStockManagerMockRecorder struct {
+	mock *MockStockManager
+}
+
+// NewMockStockManager creates a new mock instance.
+func NewMockStockManager(ctrl *gomock.Controller) *MockStockManager {
+	mock := &MockStockManager{ctrl: ctrl}
+	mock.recorder = &MockStockManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStockManager) EXPECT() *MockStockManagerMockRecorder {
+	return m.recorder
+}
+
+// AdjustStock mocks base method.
+func (m *MockStockManager) AdjustStock(size uint32, delta int64) (uint32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustStock", size, delta)
+	ret0, _ := ret[0].(uint32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustStock indicates an expected call of AdjustStock.
+func (mr *MockStockManagerMockRecorder) AdjustStock(size, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustStock", reflect.TypeOf((*MockStockManager)(nil).AdjustStock), size, delta)
+}
+
+// ClearStock mocks base method.
+func (m *MockStockManager) ClearStock(size uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearStock", size)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearStock indicates an expected call of ClearStock.
+func (mr *MockStockManagerMockRecorder) ClearStock(size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearStock", reflect.TypeOf((*MockStockManager)(nil).ClearStock), size)
+}
+
+// GetStock mocks base method.
+func (m *MockStockManager) GetStock() ([]models.PackStock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStock")
+	ret0, _ := ret[0].([]models.PackStock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStock indicates an expected call of GetStock.
+func (mr *MockStockManagerMockRecorder) GetStock() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStock", reflect.TypeOf((*MockStockManager)(nil).GetStock))
+}
+
+// SetStock mocks base method.
+func (m *MockStockManager) SetStock(size, quantity uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStock", size, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetStock indicates an expected call of SetStock.
+func (mr *MockStockManagerMockRecorder) SetStock(size, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStock", reflect.TypeOf((*MockStockManager)(nil).SetStock), size, quantity)
+}
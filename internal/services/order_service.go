@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/klemis/packs-calculator/internal/repositories"
+	"github.com/klemis/packs-calculator/models"
+)
+
+// OrderManager defines the interface for quoting orders and moving them through their lifecycle.
+type OrderManager interface {
+	CreateOrder(quantity uint64, opts CalculateOptions) (*models.Order, error)
+	ListOrders(status models.OrderStatus) ([]models.Order, error)
+	GetOrder(id uint64) (*models.Order, error)
+	TransitionOrder(id uint64, to models.OrderStatus) (*models.Order, error)
+}
+
+// orderTransitions lists the statuses an order may move to from each status.
+// Shipped and cancelled orders are final.
+var orderTransitions = map[models.OrderStatus][]models.OrderStatus{
+	models.OrderQuoted:    {models.OrderConfirmed, models.OrderCancelled},
+	models.OrderConfirmed: {models.OrderPacked, models.OrderCancelled},
+	models.OrderPacked:    {models.OrderShipped, models.OrderCancelled},
+}
+
+// OrderService is an implementation of OrderManager.
+type OrderService struct {
+	repo       repositories.OrderRepository
+	calculator PacksCalculator
+}
+
+// NewOrderService creates a new instance of OrderManager with an injected repository, quoting
+// orders with calculator.
+func NewOrderService(orderRepo repositories.OrderRepository, calculator PacksCalculator) OrderManager {
+	return &OrderService{
+		repo:       orderRepo,
+		calculator: calculator,
+	}
+}
+
+// CreateOrder calculates the packs for quantity and stores them as a new quoted order.
+func (s *OrderService) CreateOrder(quantity uint64, opts CalculateOptions) (*models.Order, error) {
+	calculation, err := s.calculator.CalculatePacks(quantity, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &models.Order{
+		Status:         models.OrderQuoted,
+		Quantity:       quantity,
+		CalculationID:  calculation.ID,
+		CatalogVersion: calculation.CatalogVersion,
+		Strategy:       calculation.Strategy,
+		Packs:          calculation.Packs,
+		TotalCost:      calculation.Cost.Total,
+	}
+	if err := s.repo.CreateOrder(order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ListOrders returns the orders in the given status, newest first. An empty status lists every order.
+func (s *OrderService) ListOrders(status models.OrderStatus) ([]models.Order, error) {
+	orders, err := s.repo.GetOrders(status)
+	if err != nil {
+		return nil, err
+	}
+	if orders == nil {
+		orders = []models.Order{}
+	}
+
+	return orders, nil
+}
+
+// GetOrder returns an order by its ID.
+func (s *OrderService) GetOrder(id uint64) (*models.Order, error) {
+	return s.repo.GetOrder(id)
+}
+
+// TransitionOrder moves an order to the given status, rejecting moves the order lifecycle does not allow.
+func (s *OrderService) TransitionOrder(id uint64, to models.OrderStatus) (*models.Order, error) {
+	order, err := s.repo.GetOrder(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(orderTransitions[order.Status], to) {
+		return nil, fmt.Errorf("%w: cannot move a %s order to %s", models.ErrInvalidOrderTransition, order.Status, to)
+	}
+
+	return s.repo.UpdateOrderStatus(id, order.Status, to)
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/repositories/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+
+	catalog := &models.CatalogVersion{ID: 5, PackSizes: []models.PackSize{{ID: 1, Size: 500, Price: 4}, {ID: 2, Size: 250, Price: 2.5}}}
+	mockRepo.EXPECT().GetCatalogVersion(uint32(0)).Return(catalog, nil).Times(1)
+	mockStockRepo.EXPECT().GetStock().Return(nil, nil).Times(1)
+	mockCalculationRepo.EXPECT().SaveCalculation(gomock.Any()).DoAndReturn(func(record *models.CalculationRecord) error {
+		record.ID = 42
+		return nil
+	}).Times(1)
+	mockOrderRepo.EXPECT().CreateOrder(gomock.Any()).DoAndReturn(func(order *models.Order) error {
+		order.ID = 3
+		return nil
+	}).Times(1)
+
+	service := NewOrderService(mockOrderRepo, NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo))
+
+	order, err := service.CreateOrder(501, CalculateOptions{Caller: "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Order{
+		ID:             3,
+		Status:         models.OrderQuoted,
+		Quantity:       501,
+		CalculationID:  42,
+		CatalogVersion: 5,
+		Strategy:       StrategyOptimal,
+		Packs:          map[uint32]uint64{500: 1, 250: 1},
+		TotalCost:      6.5,
+	}, order)
+}
+
+func TestCreateOrderCalculationError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+
+	service := NewOrderService(mockOrderRepo, NewPacksCalculatorService(nil, nil, nil))
+
+	order, err := service.CreateOrder(501, CalculateOptions{Strategy: "cheapest"})
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+	assert.Nil(t, order)
+}
+
+func TestTransitionOrder(t *testing.T) {
+	testCases := []struct {
+		name        string
+		from        models.OrderStatus
+		to          models.OrderStatus
+		expectMove  bool
+		expectedErr string
+	}{
+		{name: "Confirm quoted order", from: models.OrderQuoted, to: models.OrderConfirmed, expectMove: true},
+		{name: "Pack confirmed order", from: models.OrderConfirmed, to: models.OrderPacked, expectMove: true},
+		{name: "Ship packed order", from: models.OrderPacked, to: models.OrderShipped, expectMove: true},
+		{name: "Cancel quoted order", from: models.OrderQuoted, to: models.OrderCancelled, expectMove: true},
+		{
+			name:        "Ship unpacked order",
+			from:        models.OrderConfirmed,
+			to:          models.OrderShipped,
+			expectedErr: "invalid order transition: cannot move a confirmed order to shipped",
+		},
+		{
+			name:        "Cancel shipped order",
+			from:        models.OrderShipped,
+			to:          models.OrderCancelled,
+			expectedErr: "invalid order transition: cannot move a shipped order to cancelled",
+		},
+		{
+			name:        "Confirm cancelled order",
+			from:        models.OrderCancelled,
+			to:          models.OrderConfirmed,
+			expectedErr: "invalid order transition: cannot move a cancelled order to confirmed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+			mockOrderRepo.EXPECT().GetOrder(uint64(3)).Return(&models.Order{ID: 3, Status: tc.from}, nil).Times(1)
+			if tc.expectMove {
+				mockOrderRepo.EXPECT().UpdateOrderStatus(uint64(3), tc.from, tc.to).
+					Return(&models.Order{ID: 3, Status: tc.to}, nil).Times(1)
+			}
+
+			service := NewOrderService(mockOrderRepo, nil)
+
+			order, err := service.TransitionOrder(3, tc.to)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				assert.ErrorIs(t, err, models.ErrInvalidOrderTransition)
+				assert.Nil(t, order)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.to, order.Status)
+			}
+		})
+	}
+}
+
+func TestTransitionOrderNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockOrderRepo.EXPECT().GetOrder(uint64(3)).Return(nil, models.ErrOrderNotFound).Times(1)
+
+	service := NewOrderService(mockOrderRepo, nil)
+
+	order, err := service.TransitionOrder(3, models.OrderConfirmed)
+	assert.ErrorIs(t, err, models.ErrOrderNotFound)
+	assert.Nil(t, order)
+}
+
+func TestListOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockOrderRepo.EXPECT().GetOrders(models.OrderPacked).Return(nil, nil).Times(1)
+
+	service := NewOrderService(mockOrderRepo, nil)
+
+	orders, err := service.ListOrders(models.OrderPacked)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Order{}, orders)
+}
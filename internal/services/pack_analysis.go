@@ -0,0 +1,229 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/klemis/packs-calculator/models"
+)
+
+// Bounds of a pack set analysis.
+const (
+	// DefaultUnreachableBelow is the bound below which unreachable quantities are listed by default.
+	DefaultUnreachableBelow = 1000
+	// MaxUnreachableBelow caps the bound below which unreachable quantities are listed.
+	MaxUnreachableBelow = 100000
+	// DefaultOverfillTo is the last quantity of the default overfill range, which starts at 1.
+	DefaultOverfillTo = 10000
+	// MaxOverfillRange caps the number of quantities the overfill is measured over.
+	MaxOverfillRange = 1000000
+	// maxAnalysisModulus caps the smallest pack size an analysis works with, which sizes its tables.
+	maxAnalysisModulus = 1 << 20
+)
+
+var (
+	// ErrInvalidAnalysisRange is returned when an analysis asks for quantity ranges it cannot cover.
+	ErrInvalidAnalysisRange = errors.New("invalid analysis range")
+	// ErrAnalysisTooLarge is returned when the smallest pack size is too large to analyse.
+	ErrAnalysisTooLarge = errors.New("smallest pack size is too large to analyse")
+)
+
+// AnalysisOptions bounds the quantities a pack set analysis looks at.
+type AnalysisOptions struct {
+	// UnreachableBelow lists the unreachable quantities below it, up to MaxUnreachableBelow.
+	UnreachableBelow uint64
+	// OverfillFrom and OverfillTo are the first and last quantities the overfill is measured over, at
+	// most MaxOverfillRange of them.
+	OverfillFrom uint64
+	OverfillTo   uint64
+}
+
+// validate checks that the analysis ranges are within their caps.
+func (o AnalysisOptions) validate() error {
+	if o.UnreachableBelow > MaxUnreachableBelow {
+		return fmt.Errorf("%w: unreachable quantities can be listed below %d at most", ErrInvalidAnalysisRange, MaxUnreachableBelow)
+	}
+	if o.OverfillFrom > o.OverfillTo {
+		return fmt.Errorf("%w: the overfill range ends before it starts", ErrInvalidAnalysisRange)
+	}
+	if o.OverfillTo-o.OverfillFrom >= MaxOverfillRange {
+		return fmt.Errorf("%w: the overfill range spans more than %d quantities", ErrInvalidAnalysisRange, MaxOverfillRange)
+	}
+
+	return nil
+}
+
+// residueTable records, for every remainder modulo the smallest pack size, the smallest total of that
+// remainder the other sizes add up to. Adding packs of the smallest size reaches every larger total of
+// the same remainder, so the table answers whether any total can be shipped exactly.
+type residueTable struct {
+	modulus uint64
+	// least[r] is the smallest reachable total t with t % modulus == r, or math.MaxUint64.
+	least []uint64
+	// via[r] is the size of the last pack added to reach least[r]; 0 at remainder 0.
+	via []uint32
+}
+
+// buildResidueTable runs the round robin algorithm over sizes, which must be sorted ascending, adding
+// one size at a time to the table of the smallest.
+func buildResidueTable(sizes []uint32) *residueTable {
+	modulus := uint64(sizes[0])
+	table := &residueTable{
+		modulus: modulus,
+		least:   make([]uint64, modulus),
+		via:     make([]uint32, modulus),
+	}
+	for r := uint64(1); r < modulus; r++ {
+		table.least[r] = math.MaxUint64
+	}
+
+	for _, size := range sizes[1:] {
+		step := uint64(size)
+		cycles := gcd(modulus, step%modulus)
+		// The remainders fall into cycles of step. Each is walked round once from its smallest total,
+		// which nothing in the cycle can improve on.
+		for start := uint64(0); start < cycles; start++ {
+			n := uint64(math.MaxUint64)
+			for r := start; r < modulus; r += cycles {
+				n = min(n, table.least[r])
+			}
+			if n == math.MaxUint64 {
+				continue
+			}
+
+			for i := uint64(1); i < modulus/cycles; i++ {
+				n += step
+				r := n % modulus
+				if n < table.least[r] {
+					table.least[r], table.via[r] = n, size
+				} else {
+					n = table.least[r]
+				}
+			}
+		}
+	}
+
+	return table
+}
+
+// reachable reports whether the sizes add up to total exactly.
+func (t *residueTable) reachable(total uint64) bool {
+	return t.least[total%t.modulus] <= total
+}
+
+// combination returns packs adding up to a reachable total: the packs recorded for its remainder, then
+// packs of the smallest size for the rest.
+func (t *residueTable) combination(total uint64) map[uint32]uint64 {
+	packs := make(map[uint32]uint64)
+	for r := total % t.modulus; t.via[r] != 0; r = total % t.modulus {
+		packs[t.via[r]]++
+		total -= uint64(t.via[r])
+	}
+	if total > 0 {
+		packs[uint32(t.modulus)] += total / t.modulus
+	}
+
+	return packs
+}
+
+// analysePackSet describes how the sizes cover order quantities in the ranges opts asks for.
+func analysePackSet(packSizes []models.PackSize, opts AnalysisOptions) (*models.PackSetAnalysis, error) {
+	if len(packSizes) == 0 {
+		return nil, models.ErrNoPackSizes
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	sizes := make([]uint32, len(packSizes))
+	for i, pack := range packSizes {
+		sizes[i] = pack.Size
+	}
+	slices.Sort(sizes)
+	if sizes[0] > maxAnalysisModulus {
+		return nil, ErrAnalysisTooLarge
+	}
+	// Quantities this close to math.MaxUint64 may have no multiple of the smallest size covering them.
+	if opts.OverfillTo > math.MaxUint64-uint64(sizes[0]) {
+		return nil, fmt.Errorf("%w: the overfill range ends past %d", ErrInvalidAnalysisRange, uint64(math.MaxUint64-uint64(sizes[0])))
+	}
+
+	analysis := &models.PackSetAnalysis{
+		PackSizes:        slices.Clone(sizes),
+		UnreachableBelow: opts.UnreachableBelow,
+		Unreachable:      []uint64{},
+		Redundant:        []models.RedundantSize{},
+	}
+	slices.Reverse(analysis.PackSizes)
+	for _, size := range sizes {
+		analysis.GCD = gcd(analysis.GCD, uint64(size))
+	}
+
+	table := buildResidueTable(sizes)
+	if analysis.GCD == 1 {
+		// The least total of every remainder is reachable and the one a smallest pack below it is not,
+		// so the last unreachable total lies a smallest pack below the largest least total.
+		frobenius := int64(slices.Max(table.least)) - int64(table.modulus)
+		analysis.FrobeniusNumber = &frobenius
+	}
+	for q := uint64(1); q < opts.UnreachableBelow; q++ {
+		if !table.reachable(q) {
+			analysis.Unreachable = append(analysis.Unreachable, q)
+		}
+	}
+	analysis.Overfill = overfillAnalysis(table, opts.OverfillFrom, opts.OverfillTo)
+
+	// A size is redundant when the others add up to it; only smaller sizes can.
+	for i, size := range sizes[1:] {
+		others := slices.Delete(slices.Clone(sizes), i+1, i+2)
+		if othersTable := buildResidueTable(others); othersTable.reachable(uint64(size)) {
+			analysis.Redundant = append(analysis.Redundant, models.RedundantSize{
+				Size:  size,
+				Packs: othersTable.combination(uint64(size)),
+			})
+		}
+	}
+	slices.Reverse(analysis.Redundant)
+
+	return analysis, nil
+}
+
+// overfillAnalysis measures the overfill of covering every quantity from from to to with the fewest
+// items, walking down from the first total sure to cover to so each quantity sees the next reachable total.
+func overfillAnalysis(table *residueTable, from, to uint64) models.OverfillAnalysis {
+	analysis := models.OverfillAnalysis{From: from, To: to}
+
+	// A multiple of the smallest size lies within it of any quantity, so this total is reachable.
+	next := to + table.modulus - 1
+	next -= next % table.modulus
+	var sum uint64
+	for q := next; ; q-- {
+		if table.reachable(q) {
+			next = q
+		}
+		if q <= to {
+			overfill := next - q
+			sum += overfill
+			if overfill >= analysis.Worst {
+				analysis.Worst, analysis.WorstQuantity = overfill, q
+			}
+		}
+		if q == from {
+			break
+		}
+	}
+	analysis.Average = float64(sum) / float64(to-from+1)
+
+	return analysis
+}
+
+// gcd returns the greatest common divisor of a and b, where gcd(0, b) is b.
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/repositories/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalysePackSetMatchesBruteForce(t *testing.T) {
+	const bound = 2000
+	opts := AnalysisOptions{UnreachableBelow: bound, OverfillFrom: 1, OverfillTo: bound / 2}
+
+	for _, sizes := range oraclePackSets {
+		msg := fmt.Sprintf("sizes %v", sizes)
+		reachable := reachableTotals(sizes, nil, 2*bound)
+
+		analysis, err := analysePackSet(toPackSizes(sizes), opts)
+		assert.NoError(t, err, msg)
+
+		var unreachable []uint64
+		for q := uint64(1); q < bound; q++ {
+			if !reachable[uint32(q)] {
+				unreachable = append(unreachable, q)
+			}
+		}
+		assert.Equal(t, unreachable, analysis.Unreachable, msg)
+		if analysis.GCD == 1 {
+			// Every Frobenius number of the oracle sets lies below the bound.
+			assert.Equal(t, int64(unreachable[len(unreachable)-1]), *analysis.FrobeniusNumber, msg)
+		} else {
+			assert.Nil(t, analysis.FrobeniusNumber, msg)
+		}
+
+		var worst, sum uint64
+		for q := uint32(opts.OverfillFrom); q <= uint32(opts.OverfillTo); q++ {
+			cover := q
+			for !reachable[cover] {
+				cover++
+			}
+			worst = max(worst, uint64(cover-q))
+			sum += uint64(cover - q)
+		}
+		assert.Equal(t, worst, analysis.Overfill.Worst, msg)
+		assert.InDelta(t, float64(sum)/float64(opts.OverfillTo), analysis.Overfill.Average, 1e-9, msg)
+
+		for _, redundant := range analysis.Redundant {
+			items, _ := totals(redundant.Packs)
+			assert.Equal(t, redundant.Size, items, msg)
+			assert.NotContains(t, redundant.Packs, redundant.Size, msg)
+		}
+	}
+}
+
+func TestAnalysePackSet(t *testing.T) {
+	frobenius := int64(43)
+
+	testCases := []struct {
+		name     string
+		sizes    []uint32
+		opts     AnalysisOptions
+		expected *models.PackSetAnalysis
+	}{
+		{
+			name:  "Sizes sharing a divisor",
+			sizes: []uint32{250, 500},
+			opts:  AnalysisOptions{UnreachableBelow: 5, OverfillFrom: 1, OverfillTo: 1000},
+			expected: &models.PackSetAnalysis{
+				PackSizes:        []uint32{500, 250},
+				GCD:              250,
+				UnreachableBelow: 5,
+				Unreachable:      []uint64{1, 2, 3, 4},
+				Overfill:         models.OverfillAnalysis{From: 1, To: 1000, Worst: 249, WorstQuantity: 1, Average: 124.5},
+				Redundant:        []models.RedundantSize{{Size: 500, Packs: map[uint32]uint64{250: 2}}},
+			},
+		},
+		{
+			name:  "Chicken nuggets",
+			sizes: []uint32{20, 6, 9},
+			opts:  AnalysisOptions{UnreachableBelow: 12, OverfillFrom: 40, OverfillTo: 45},
+			expected: &models.PackSetAnalysis{
+				PackSizes:        []uint32{20, 9, 6},
+				GCD:              1,
+				FrobeniusNumber:  &frobenius,
+				UnreachableBelow: 12,
+				Unreachable:      []uint64{1, 2, 3, 4, 5, 7, 8, 10, 11},
+				Overfill:         models.OverfillAnalysis{From: 40, To: 45, Worst: 1, WorstQuantity: 43, Average: 1.0 / 6},
+				Redundant:        []models.RedundantSize{},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis, err := analysePackSet(toPackSizes(tc.sizes), tc.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, analysis)
+		})
+	}
+}
+
+func TestAnalysePackSetErrors(t *testing.T) {
+	opts := AnalysisOptions{UnreachableBelow: 10, OverfillFrom: 1, OverfillTo: 10}
+
+	_, err := analysePackSet(nil, opts)
+	assert.ErrorIs(t, err, models.ErrNoPackSizes)
+
+	_, err = analysePackSet(toPackSizes([]uint32{maxAnalysisModulus + 1}), opts)
+	assert.ErrorIs(t, err, ErrAnalysisTooLarge)
+
+	for _, invalid := range []AnalysisOptions{
+		{UnreachableBelow: MaxUnreachableBelow + 1, OverfillFrom: 1, OverfillTo: 10},
+		{UnreachableBelow: 10, OverfillFrom: 11, OverfillTo: 10},
+		{UnreachableBelow: 10, OverfillFrom: 1, OverfillTo: MaxOverfillRange + 1},
+	} {
+		_, err = analysePackSet(toPackSizes([]uint32{3, 5}), invalid)
+		assert.ErrorIs(t, err, ErrInvalidAnalysisRange, "%+v", invalid)
+	}
+
+	// The overfill range may end a smallest pack short of math.MaxUint64, but no closer.
+	analysis, err := analysePackSet(toPackSizes([]uint32{3, 5}), AnalysisOptions{OverfillFrom: math.MaxUint64 - 10, OverfillTo: math.MaxUint64 - 3})
+	assert.NoError(t, err)
+	assert.Equal(t, models.OverfillAnalysis{From: math.MaxUint64 - 10, To: math.MaxUint64 - 3, WorstQuantity: math.MaxUint64 - 10}, analysis.Overfill)
+
+	_, err = analysePackSet(toPackSizes([]uint32{3, 5}), AnalysisOptions{OverfillFrom: math.MaxUint64 - 10, OverfillTo: math.MaxUint64 - 2})
+	assert.ErrorIs(t, err, ErrInvalidAnalysisRange)
+}
+
+func TestAnalysePackSizes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+	mockRepo.EXPECT().GetPackSizes().Return(toPackSizes([]uint32{5, 3}), nil).Times(1)
+
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	analysis, err := service.AnalysePackSizes(AnalysisOptions{UnreachableBelow: 10, OverfillFrom: 1, OverfillTo: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{5, 3}, analysis.PackSizes)
+	assert.Equal(t, []uint64{1, 2, 4, 7}, analysis.Unreachable)
+	assert.Equal(t, int64(7), *analysis.FrobeniusNumber)
+}
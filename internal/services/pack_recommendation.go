@@ -0,0 +1,303 @@
+package services
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/klemis/packs-calculator/models"
+)
+
+// Bounds of a pack size recommendation.
+const (
+	// MaxRecommendedSizes caps the number of pack sizes a recommended catalog holds.
+	MaxRecommendedSizes = 6
+	// DefaultRecommendations is the number of candidate catalogs recommended by default.
+	DefaultRecommendations = 5
+	// MaxRecommendations caps the number of candidate catalogs recommended.
+	MaxRecommendations = 20
+	// minBeamWidth is the fewest catalogs the search keeps at each step.
+	minBeamWidth = 10
+	// recommendationBudget caps the totals the search may evaluate, keeping a recommendation within a
+	// few seconds.
+	recommendationBudget = 200_000_000
+)
+
+var (
+	// ErrInvalidRecommendation is returned when the constraints of a recommendation cannot be met or
+	// make the search too large.
+	ErrInvalidRecommendation = errors.New("invalid recommendation constraints")
+	// ErrNoOrderQuantities is returned when there are no order quantities to recommend pack sizes for.
+	ErrNoOrderQuantities = errors.New("no order quantities to recommend pack sizes for")
+)
+
+// catalogKey holds the sizes of a catalog in ascending order, padded with zeros.
+type catalogKey [MaxRecommendedSizes]uint32
+
+// with returns the key of the catalog with size added.
+func (k catalogKey) with(size uint32, n int) catalogKey {
+	sizes := append(slices.Clone(k[:n]), size)
+	slices.Sort(sizes)
+
+	var key catalogKey
+	copy(key[:], sizes)
+
+	return key
+}
+
+// orderDistribution is a distribution of order quantities, with each quantity rounded up to the step
+// every candidate size is a multiple of, which is what the packs must cover.
+type orderDistribution struct {
+	step uint64
+	// quantities are the distinct order quantities, ascending, and counts how many orders asked for each.
+	quantities []uint64
+	counts     []uint64
+	// targets[i] is quantities[i] in steps, rounded up.
+	targets []uint64
+	orders  uint64
+	items   float64
+}
+
+// newOrderDistribution merges the counts of every order quantity, skipping empty orders.
+func newOrderDistribution(quantities []models.QuantityCount, step uint64) orderDistribution {
+	merged := make(map[uint64]uint64)
+	for _, quantity := range quantities {
+		if quantity.Quantity > 0 && quantity.Count > 0 {
+			merged[uint64(quantity.Quantity)] += quantity.Count
+		}
+	}
+
+	dist := orderDistribution{step: step}
+	for _, quantity := range slices.Sorted(maps.Keys(merged)) {
+		count := merged[quantity]
+		dist.quantities = append(dist.quantities, quantity)
+		dist.counts = append(dist.counts, count)
+		dist.targets = append(dist.targets, (quantity+step-1)/step)
+		dist.orders += count
+		dist.items += float64(count) * float64(quantity)
+	}
+
+	return dist
+}
+
+// evaluate measures how a catalog would pack the orders, given the fewest packs reaching every total
+// in steps, and covering each order with the fewest items and then the fewest packs.
+func (d orderDistribution) evaluate(packs []uint32, packWeight float64) models.CatalogCandidate {
+	var candidate models.CatalogCandidate
+	var overfill, packCount float64
+	var exact uint64
+
+	// Walking down, next is the smallest reachable total from t on.
+	next := uint64(len(packs))
+	i := len(d.targets) - 1
+	for t := uint64(len(packs)) - 1; i >= 0; t-- {
+		if packs[t] != unreachable {
+			next = t
+		}
+		for ; i >= 0 && d.targets[i] == t; i-- {
+			extra := next*d.step - d.quantities[i]
+			overfill += float64(d.counts[i]) * float64(extra)
+			packCount += float64(d.counts[i]) * float64(packs[next])
+			candidate.WorstOverfill = max(candidate.WorstOverfill, extra)
+			if extra == 0 {
+				exact += d.counts[i]
+			}
+		}
+	}
+
+	orders := float64(d.orders)
+	candidate.ExpectedOverfill = overfill / orders
+	candidate.OverfillPercent = overfill / d.items * 100
+	candidate.ExpectedPackCount = packCount / orders
+	candidate.ExactFitRate = float64(exact) / orders
+	candidate.Score = candidate.ExpectedOverfill + packWeight*candidate.ExpectedPackCount
+
+	return candidate
+}
+
+// addPackSize lowers the fewest packs reaching every total, in steps, with packs of size steps.
+func addPackSize(packs []uint32, size uint64) {
+	for t := size; t < uint64(len(packs)); t++ {
+		if packs[t-size] != unreachable && packs[t-size]+1 < packs[t] {
+			packs[t] = packs[t-size] + 1
+		}
+	}
+}
+
+// recommendationSearch is a beam search for the catalogs of n sizes that pack a distribution of orders
+// best. Catalogs grow one size at a time and only the best few are grown further.
+type recommendationSearch struct {
+	dist       orderDistribution
+	candidates []uint32
+	n          int
+	beamWidth  int
+	packWeight float64
+	// horizon is the number of totals tracked, in steps: enough for a pack of the largest candidate
+	// size above the largest order.
+	horizon uint64
+}
+
+// catalogNode is a catalog in the search with the fewest packs reaching every total.
+type catalogNode struct {
+	key   catalogKey
+	packs []uint32
+}
+
+// scoredCatalog is a catalog one size larger than the node it grows from, with its metrics.
+type scoredCatalog struct {
+	node      int
+	size      uint32
+	key       catalogKey
+	candidate models.CatalogCandidate
+}
+
+// newRecommendationSearch checks that the search fits the budget before anything is evaluated. The
+// candidate sizes are the multiples of the step from first on.
+func newRecommendationSearch(dist orderDistribution, first, count uint64, n, limit int, packWeight float64) (*recommendationSearch, error) {
+	last := first + (count-1)*dist.step
+	search := &recommendationSearch{
+		dist:       dist,
+		n:          n,
+		beamWidth:  max(2*limit, minBeamWidth),
+		packWeight: packWeight,
+		horizon:    addCapped(dist.targets[len(dist.targets)-1], last/dist.step+1),
+	}
+
+	work := float64(n) * float64(search.beamWidth) * float64(count) * float64(search.horizon)
+	if work > recommendationBudget {
+		return nil, fmt.Errorf("%w: the search is too large; narrow the size range or raise multiple_of", ErrInvalidRecommendation)
+	}
+
+	search.candidates = make([]uint32, count)
+	for i := range search.candidates {
+		search.candidates[i] = uint32(first + uint64(i)*dist.step)
+	}
+
+	return search, nil
+}
+
+// run returns the best limit catalogs, best first.
+func (s *recommendationSearch) run(limit int) []models.CatalogCandidate {
+	empty := make([]uint32, s.horizon)
+	for t := range empty {
+		empty[t] = unreachable
+	}
+	empty[0] = 0
+	beam := []catalogNode{{packs: empty}}
+
+	var scored []scoredCatalog
+	scratch := make([]uint32, s.horizon)
+	for level := 0; level < s.n; level++ {
+		scored = scored[:0]
+		seen := make(map[catalogKey]bool)
+		for i, node := range beam {
+			for _, size := range s.candidates {
+				if slices.Contains(node.key[:level], size) {
+					continue
+				}
+				key := node.key.with(size, level)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				copy(scratch, node.packs)
+				addPackSize(scratch, uint64(size)/s.dist.step)
+				scored = append(scored, scoredCatalog{node: i, size: size, key: key, candidate: s.dist.evaluate(scratch, s.packWeight)})
+			}
+		}
+
+		slices.SortFunc(scored, compareScored)
+		scored = scored[:min(len(scored), s.beamWidth)]
+
+		next := make([]catalogNode, len(scored))
+		for i, catalog := range scored {
+			packs := slices.Clone(beam[catalog.node].packs)
+			addPackSize(packs, uint64(catalog.size)/s.dist.step)
+			next[i] = catalogNode{key: catalog.key, packs: packs}
+		}
+		beam = next
+	}
+
+	results := make([]models.CatalogCandidate, 0, limit)
+	for i, catalog := range scored[:min(len(scored), limit)] {
+		candidate := catalog.candidate
+		candidate.Rank = i + 1
+		candidate.Sizes = slices.Clone(catalog.key[:s.n])
+		slices.Reverse(candidate.Sizes)
+		results = append(results, candidate)
+	}
+
+	return results
+}
+
+// compareScored orders catalogs by score, then by expected pack count, then by their sizes.
+func compareScored(a, b scoredCatalog) int {
+	if c := cmp.Compare(a.candidate.Score, b.candidate.Score); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.candidate.ExpectedPackCount, b.candidate.ExpectedPackCount); c != 0 {
+		return c
+	}
+
+	return slices.Compare(a.key[:], b.key[:])
+}
+
+// candidateRange returns the first multiple of step from minSize and how many there are up to maxSize.
+func candidateRange(minSize, maxSize, step uint32) (first, count uint64) {
+	first = (uint64(minSize) + uint64(step) - 1) / uint64(step) * uint64(step)
+	if first > uint64(maxSize) {
+		return first, 0
+	}
+
+	return first, (uint64(maxSize)-first)/uint64(step) + 1
+}
+
+// recommendPackSizes ranks the catalogs that best pack a distribution of order quantities under the
+// constraints of opts.
+func recommendPackSizes(quantities []models.QuantityCount, opts RecommendationOptions) (*models.PackRecommendation, error) {
+	if opts.Sizes < 1 || opts.Sizes > MaxRecommendedSizes {
+		return nil, fmt.Errorf("%w: a catalog holds between 1 and %d sizes", ErrInvalidRecommendation, MaxRecommendedSizes)
+	}
+	if opts.Limit < 0 || opts.Limit > MaxRecommendations {
+		return nil, fmt.Errorf("%w: at most %d catalogs can be recommended", ErrInvalidRecommendation, MaxRecommendations)
+	}
+	if opts.PackWeight < 0 || math.IsNaN(opts.PackWeight) {
+		return nil, fmt.Errorf("%w: the pack weight cannot be negative", ErrInvalidRecommendation)
+	}
+
+	step := max(opts.MultipleOf, 1)
+	dist := newOrderDistribution(quantities, uint64(step))
+	if dist.orders == 0 {
+		return nil, ErrNoOrderQuantities
+	}
+
+	minSize, maxSize := max(opts.MinSize, step), opts.MaxSize
+	if maxSize == 0 {
+		// Packs larger than every order only add overfill.
+		maxSize = uint32(min(dist.targets[len(dist.targets)-1]*dist.step, math.MaxUint32))
+	}
+	first, count := candidateRange(minSize, maxSize, step)
+	if count < uint64(opts.Sizes) {
+		return nil, fmt.Errorf("%w: only %d sizes between %d and %d are multiples of %d", ErrInvalidRecommendation,
+			count, minSize, maxSize, step)
+	}
+
+	limit := opts.Limit
+	if limit == 0 {
+		limit = DefaultRecommendations
+	}
+	search, err := newRecommendationSearch(dist, first, count, opts.Sizes, limit, opts.PackWeight)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PackRecommendation{
+		Orders:     dist.orders,
+		Quantities: len(dist.quantities),
+		Candidates: search.run(limit),
+	}, nil
+}
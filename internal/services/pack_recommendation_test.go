@@ -0,0 +1,149 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/repositories/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// bruteForceCandidate measures a catalog by covering every order with the fewest items and then the
+// fewest packs, found by trying every combination.
+func bruteForceCandidate(sizes []uint32, quantities []models.QuantityCount, packWeight float64) models.CatalogCandidate {
+	var candidate models.CatalogCandidate
+	var orders, items, overfill, packs, exact float64
+	for _, quantity := range quantities {
+		bestItems, bestPacks := bruteForce(sizes, nil, uint32(quantity.Quantity), func(aItems, aPacks, bItems, bPacks uint32) bool {
+			return aItems < bItems || aItems == bItems && aPacks < bPacks
+		})
+
+		count := float64(quantity.Count)
+		extra := uint64(bestItems) - quantity.Quantity
+		orders += count
+		items += count * float64(quantity.Quantity)
+		overfill += count * float64(extra)
+		packs += count * float64(bestPacks)
+		candidate.WorstOverfill = max(candidate.WorstOverfill, extra)
+		if extra == 0 {
+			exact += count
+		}
+	}
+
+	candidate.ExpectedOverfill = overfill / orders
+	candidate.OverfillPercent = overfill / items * 100
+	candidate.ExpectedPackCount = packs / orders
+	candidate.ExactFitRate = exact / orders
+	candidate.Score = candidate.ExpectedOverfill + packWeight*candidate.ExpectedPackCount
+
+	return candidate
+}
+
+func TestRecommendPackSizesMatchesBruteForce(t *testing.T) {
+	quantities := []models.QuantityCount{
+		{Quantity: 120, Count: 4}, {Quantity: 260, Count: 9}, {Quantity: 310, Count: 2},
+		{Quantity: 505, Count: 1}, {Quantity: 740, Count: 6}, {Quantity: 1010, Count: 3},
+	}
+	// Six candidate sizes keep every level of the search within its beam, so it is exhaustive.
+	opts := RecommendationOptions{Sizes: 3, MinSize: 100, MaxSize: 600, MultipleOf: 100, Limit: MaxRecommendations / 2}
+
+	for _, packWeight := range []float64{0, 10, 1000} {
+		t.Run(fmt.Sprint(packWeight), func(t *testing.T) {
+			opts.PackWeight = packWeight
+			recommendation, err := recommendPackSizes(quantities, opts)
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(25), recommendation.Orders)
+			assert.Equal(t, 6, recommendation.Quantities)
+
+			var expected []models.CatalogCandidate
+			candidates := []uint32{100, 200, 300, 400, 500, 600}
+			for i := range candidates {
+				for j := i + 1; j < len(candidates); j++ {
+					for k := j + 1; k < len(candidates); k++ {
+						candidate := bruteForceCandidate([]uint32{candidates[i], candidates[j], candidates[k]}, quantities, packWeight)
+						candidate.Sizes = []uint32{candidates[k], candidates[j], candidates[i]}
+						expected = append(expected, candidate)
+					}
+				}
+			}
+			sort.SliceStable(expected, func(i, j int) bool {
+				a, b := expected[i], expected[j]
+				if math.Abs(a.Score-b.Score) > 1e-9 {
+					return a.Score < b.Score
+				}
+				return a.ExpectedPackCount < b.ExpectedPackCount-1e-9
+			})
+
+			assert.Len(t, recommendation.Candidates, opts.Limit)
+			for i, candidate := range recommendation.Candidates {
+				assert.Equal(t, i+1, candidate.Rank)
+				assert.InDelta(t, expected[i].Score, candidate.Score, 1e-9, "rank %d", i+1)
+
+				actual := bruteForceCandidate(slices.Clone(candidate.Sizes), quantities, packWeight)
+				assert.InDelta(t, actual.ExpectedOverfill, candidate.ExpectedOverfill, 1e-9)
+				assert.InDelta(t, actual.OverfillPercent, candidate.OverfillPercent, 1e-9)
+				assert.InDelta(t, actual.ExpectedPackCount, candidate.ExpectedPackCount, 1e-9)
+				assert.InDelta(t, actual.ExactFitRate, candidate.ExactFitRate, 1e-9)
+				assert.Equal(t, actual.WorstOverfill, candidate.WorstOverfill)
+			}
+		})
+	}
+}
+
+func TestRecommendPackSizesErrors(t *testing.T) {
+	quantities := []models.QuantityCount{{Quantity: 1200, Count: 3}}
+
+	testCases := []struct {
+		name          string
+		quantities    []models.QuantityCount
+		opts          RecommendationOptions
+		expectedError string
+	}{
+		{name: "No sizes", quantities: quantities, opts: RecommendationOptions{}, expectedError: "invalid recommendation constraints: a catalog holds between 1 and 6 sizes"},
+		{name: "Too many catalogs", quantities: quantities, opts: RecommendationOptions{Sizes: 2, Limit: 21}, expectedError: "invalid recommendation constraints: at most 20 catalogs can be recommended"},
+		{name: "Negative pack weight", quantities: quantities, opts: RecommendationOptions{Sizes: 2, PackWeight: -1}, expectedError: "invalid recommendation constraints: the pack weight cannot be negative"},
+		{name: "No orders", quantities: []models.QuantityCount{{Quantity: 0, Count: 4}}, opts: RecommendationOptions{Sizes: 2}, expectedError: "no order quantities to recommend pack sizes for"},
+		{name: "Too few candidate sizes", quantities: quantities, opts: RecommendationOptions{Sizes: 3, MinSize: 250, MaxSize: 700, MultipleOf: 250}, expectedError: "invalid recommendation constraints: only 2 sizes between 250 and 700 are multiples of 250"},
+		{name: "Search too large", quantities: []models.QuantityCount{{Quantity: 4000000000, Count: 1}}, opts: RecommendationOptions{Sizes: 2}, expectedError: "invalid recommendation constraints: the search is too large; narrow the size range or raise multiple_of"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := recommendPackSizes(tc.quantities, tc.opts)
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestRecommendPackSizesService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+	filter := models.CalculationFilter{MinQuantity: 1, MaxQuantity: math.MaxUint32}
+	mockCalculationRepo.EXPECT().GetQuantityDistribution(filter).
+		Return([]models.QuantityCount{{Quantity: 250, Count: 7}, {Quantity: 500, Count: 2}}, nil).Times(1)
+
+	service := NewRecommendationService(mockCalculationRepo)
+
+	recommendation, err := service.RecommendPackSizes(RecommendationOptions{History: filter, Sizes: 1, MultipleOf: 250, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.PackRecommendation{
+		Source:     RecommendationSourceHistory,
+		Orders:     9,
+		Quantities: 2,
+		Candidates: []models.CatalogCandidate{
+			{Rank: 1, Sizes: []uint32{250}, ExpectedOverfill: 0, OverfillPercent: 0, ExpectedPackCount: 11.0 / 9, ExactFitRate: 1},
+			{Rank: 2, Sizes: []uint32{500}, Score: 1750.0 / 9, ExpectedOverfill: 1750.0 / 9, OverfillPercent: 1750.0 / 2750 * 100, ExpectedPackCount: 1, WorstOverfill: 250, ExactFitRate: 2.0 / 9},
+		},
+	}, recommendation)
+
+	// Uploaded quantities leave the history alone.
+	recommendation, err = service.RecommendPackSizes(RecommendationOptions{Quantities: []models.QuantityCount{{Quantity: 3, Count: 1}}, Sizes: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, RecommendationSourceUpload, recommendation.Source)
+	assert.Equal(t, []uint32{3}, recommendation.Candidates[0].Sizes)
+}
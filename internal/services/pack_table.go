@@ -0,0 +1,175 @@
+package services
+
+import "math"
+
+const (
+	// unreachable marks totals that cannot be composed exactly from the pack sizes.
+	unreachable = ^uint32(0)
+	// noLimit marks a pack size with unlimited supply.
+	noLimit = ^uint32(0)
+)
+
+// costEpsilon absorbs floating point noise when comparing accumulated costs.
+const costEpsilon = 1e-9
+
+// packTable holds, for every total up to its limit, the cheapest combination summing exactly
+// to it, breaking cost ties by the fewest packs.
+type packTable struct {
+	sizes []uint32
+	// cost[t] is the minimum cost of a combination summing exactly to t.
+	cost []float64
+	// packs[t] is the number of packs in that combination.
+	packs []uint32
+	// take[i][t] is the number of packs of sizes[i] in the best combination for t
+	// that only uses sizes[:i+1].
+	take [][]uint32
+}
+
+// buildPackTable runs a dynamic programme over every total from 0 to limit, adding one pack
+// size at a time. A nil unitCosts makes every pack free, so the table only minimises the
+// number of packs. limits[i] caps the number of packs of sizes[i]; a nil limits or noLimit
+// leaves the supply unbounded.
+func buildPackTable(sizes []uint32, unitCosts []float64, limits []uint32, limit uint64) *packTable {
+	table := &packTable{
+		sizes: sizes,
+		cost:  make([]float64, limit+1),
+		packs: make([]uint32, limit+1),
+		take:  make([][]uint32, len(sizes)),
+	}
+	for t := uint64(1); t <= limit; t++ {
+		table.cost[t] = math.Inf(1)
+		table.packs[t] = unreachable
+	}
+
+	for i, size := range sizes {
+		unitCost := 0.0
+		if unitCosts != nil {
+			unitCost = unitCosts[i]
+		}
+
+		table.take[i] = make([]uint32, limit+1)
+		if limits == nil || uint64(limits[i]) >= limit/uint64(size) {
+			table.addUnbounded(i, unitCost)
+		} else {
+			table.addBounded(i, unitCost, limits[i])
+		}
+	}
+
+	return table
+}
+
+// addUnbounded folds sizes[i] into the table without a cap on its number of packs.
+func (t *packTable) addUnbounded(i int, unitCost float64) {
+	size := uint64(t.sizes[i])
+	take := t.take[i]
+
+	// Walking upwards lets a total reuse the packs of sizes[i] already added to total - size.
+	for total := size; total <= t.limit(); total++ {
+		prev := total - size
+		if t.packs[prev] == unreachable {
+			continue
+		}
+
+		cost := t.cost[prev] + unitCost
+		packs := t.packs[prev] + 1
+		if cheaper(cost, int64(packs), t.cost[total], int64(t.packs[total])) {
+			t.cost[total] = cost
+			t.packs[total] = packs
+			take[total] = take[prev] + 1
+		}
+	}
+}
+
+// addBounded folds sizes[i] into the table using at most maxPacks packs of it. Totals sharing
+// a residue modulo the size form a chain, and a monotone queue over each chain keeps the best
+// predecessor within maxPacks steps, so every total is settled in constant amortised time.
+func (t *packTable) addBounded(i int, unitCost float64, maxPacks uint32) {
+	size := uint64(t.sizes[i])
+	take := t.take[i]
+
+	// candidate is the combination for the j-th total of a chain, with the cost and packs of
+	// j packs of sizes[i] taken off so candidates at different steps compare directly.
+	type candidate struct {
+		j     uint64
+		cost  float64
+		packs int64
+	}
+	queue := make([]candidate, 0)
+
+	for residue := uint64(0); residue < size && residue <= t.limit(); residue++ {
+		queue = queue[:0]
+		head := 0
+
+		for j := uint64(0); residue+j*size <= t.limit(); j++ {
+			total := residue + j*size
+			if t.packs[total] != unreachable {
+				next := candidate{
+					j:     j,
+					cost:  t.cost[total] - float64(j)*unitCost,
+					packs: int64(t.packs[total]) - int64(j),
+				}
+				// Newer candidates stay usable for longer, so they evict any that are no better.
+				for len(queue) > head && !cheaper(queue[len(queue)-1].cost, queue[len(queue)-1].packs, next.cost, next.packs) {
+					queue = queue[:len(queue)-1]
+				}
+				queue = append(queue, next)
+			}
+			for head < len(queue) && queue[head].j+uint64(maxPacks) < j {
+				head++
+			}
+
+			if head == len(queue) {
+				t.cost[total] = math.Inf(1)
+				t.packs[total] = unreachable
+				continue
+			}
+
+			best := queue[head]
+			t.cost[total] = best.cost + float64(j)*unitCost
+			t.packs[total] = uint32(best.packs + int64(j))
+			take[total] = uint32(j - best.j)
+		}
+	}
+}
+
+// cheaper reports whether a combination costing costA in packsA packs beats one costing costB in packsB packs.
+func cheaper(costA float64, packsA int64, costB float64, packsB int64) bool {
+	if costA < costB-costEpsilon {
+		return true
+	}
+
+	return costA <= costB+costEpsilon && packsA < packsB
+}
+
+// limit returns the largest total covered by the table.
+func (t *packTable) limit() uint64 {
+	return uint64(len(t.packs) - 1)
+}
+
+// reachable reports whether total can be composed exactly from the pack sizes.
+func (t *packTable) reachable(total uint64) bool {
+	return t.packs[total] != unreachable
+}
+
+// packCount returns the number of packs in the combination stored for a reachable total.
+func (t *packTable) packCount(total uint64) uint64 {
+	return uint64(t.packs[total])
+}
+
+// totalCost returns the cost of the combination stored for a reachable total.
+func (t *packTable) totalCost(total uint64) float64 {
+	return t.cost[total]
+}
+
+// combination rebuilds the pack counts of the combination stored for total.
+func (t *packTable) combination(total uint64) map[uint32]uint64 {
+	result := make(map[uint32]uint64)
+	for i := len(t.sizes) - 1; i >= 0; i-- {
+		if n := t.take[i][total]; n > 0 {
+			result[t.sizes[i]] = uint64(n)
+			total -= uint64(n) * uint64(t.sizes[i])
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,391 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/klemis/packs-calculator/models"
+)
+
+// Names of the built-in packing strategies.
+const (
+	StrategyGreedy      = "greedy"
+	StrategyOptimal     = "optimal"
+	StrategyFewestPacks = "fewest-packs"
+	StrategyMinCost     = "min-cost"
+
+	// DefaultStrategy is used when a calculation does not ask for a specific strategy.
+	DefaultStrategy = StrategyOptimal
+)
+
+// ErrUnknownStrategy is returned when a calculation asks for a strategy that is not registered.
+var ErrUnknownStrategy = errors.New("unknown packing strategy")
+
+// InsufficientStockError is returned when the packs in stock cannot cover the order quantity.
+type InsufficientStockError struct {
+	Quantity uint64
+	// Available is the number of items the packs in stock add up to.
+	Available uint64
+	// Stock is the number of packs in stock per pack size.
+	Stock map[uint32]uint32
+}
+
+// Shortfall returns how many items the stock is short of the order quantity.
+func (e *InsufficientStockError) Shortfall() uint64 {
+	return e.Quantity - e.Available
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("insufficient stock: %d items ordered, %d available, short by %d", e.Quantity, e.Available, e.Shortfall())
+}
+
+// PackingProblem describes an order quantity to be covered from a set of pack sizes.
+type PackingProblem struct {
+	PackSizes []models.PackSize
+	Quantity  uint64
+	// ShippingRate is the shipping cost per unit of pack weight.
+	ShippingRate float64
+	// Stock caps the number of packs available per pack size; sizes without an entry are unlimited.
+	Stock map[uint32]uint32
+	// Mode bounds the totals the packs may add up to.
+	Mode FillMode
+}
+
+// available returns how many packs of size can be used, or math.MaxUint64 when the supply is unlimited.
+func (p PackingProblem) available(size uint32) uint64 {
+	if n, ok := p.Stock[size]; ok {
+		return uint64(n)
+	}
+
+	return math.MaxUint64
+}
+
+// limits returns the stock caps for sizes in order, or nil when every size is unlimited.
+func (p PackingProblem) limits(sizes []uint32) []uint32 {
+	if len(p.Stock) == 0 {
+		return nil
+	}
+
+	limits := make([]uint32, len(sizes))
+	for i, size := range sizes {
+		limits[i] = noLimit
+		if n, ok := p.Stock[size]; ok {
+			limits[i] = n
+		}
+	}
+
+	return limits
+}
+
+// checkStock returns an InsufficientStockError when all packs in stock add up to less than the quantity.
+func (p PackingProblem) checkStock(sizes []uint32) error {
+	var items uint64
+	for _, size := range sizes {
+		n, ok := p.Stock[size]
+		if !ok {
+			return nil
+		}
+		items = addCapped(items, uint64(n)*uint64(size))
+	}
+
+	if items >= p.Quantity {
+		return nil
+	}
+
+	stock := make(map[uint32]uint32, len(sizes))
+	for _, size := range sizes {
+		stock[size] = p.Stock[size]
+	}
+
+	return &InsufficientStockError{Quantity: p.Quantity, Available: items, Stock: stock}
+}
+
+// checkCover returns an InsufficientStockError when the stock cannot cover the quantity in a mode that
+// must cover it. Underfilling ships whatever the stock allows.
+func (p PackingProblem) checkCover(sizes []uint32) error {
+	if p.Mode.Name == FillUnderfill {
+		return nil
+	}
+
+	return p.checkStock(sizes)
+}
+
+// chooseTotal picks the total the packs add up to. Underfilling ships the largest reachable total not
+// above the quantity. Other modes take the reachable total from the quantity upwards that no later one
+// is better than, failing when the mode allows none, or when every covering total is past
+// math.MaxUint64.
+func (p PackingProblem) chooseTotal(solver packSolver, better func(total, best uint64) bool) (uint64, error) {
+	if p.Mode.Name == FillUnderfill {
+		// An empty combination always reaches 0.
+		total := p.Quantity
+		for !solver.reachable(total) {
+			total--
+		}
+		return total, nil
+	}
+
+	best, found := uint64(0), false
+	for total := p.Quantity; total <= solver.limit(); total++ {
+		if solver.reachable(total) && (!found || better(total, best)) {
+			best, found = total, true
+		}
+		if total == math.MaxUint64 {
+			if !found && p.Mode.Name != FillExact {
+				return 0, ErrQuantityTooLarge
+			}
+			break
+		}
+	}
+	if !found {
+		return 0, p.Mode.noFit()
+	}
+
+	return best, nil
+}
+
+// PackingStrategy defines how pack sizes are combined to cover an order quantity.
+type PackingStrategy interface {
+	Name() string
+	Pack(problem PackingProblem) (map[uint32]uint64, error)
+}
+
+// strategies holds every registered packing strategy by name.
+var strategies = map[string]PackingStrategy{}
+
+func init() {
+	RegisterStrategy(GreedyStrategy{})
+	RegisterStrategy(OptimalStrategy{})
+	RegisterStrategy(FewestPacksStrategy{})
+	RegisterStrategy(MinCostStrategy{})
+}
+
+// RegisterStrategy makes a packing strategy available under its name, replacing any previous one.
+func RegisterStrategy(strategy PackingStrategy) {
+	strategies[strategy.Name()] = strategy
+}
+
+// GetStrategy looks up a registered packing strategy, falling back to DefaultStrategy for an empty name.
+func GetStrategy(name string) (PackingStrategy, error) {
+	if name == "" {
+		name = DefaultStrategy
+	}
+
+	strategy, ok := strategies[name]
+	if !ok {
+		return nil, ErrUnknownStrategy
+	}
+
+	return strategy, nil
+}
+
+// StrategyNames returns the names of all registered packing strategies in alphabetical order.
+func StrategyNames() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// GreedyStrategy is the legacy algorithm: it fills the order with the largest packs first
+// and tops up any remainder with one smallest pack still in stock. Underfilling leaves the
+// remainder out instead; other modes reject a top-up that overfills more than they allow.
+type GreedyStrategy struct{}
+
+// Name returns the registered name of the strategy.
+func (GreedyStrategy) Name() string { return StrategyGreedy }
+
+// Pack calculates the pack combination for the order quantity.
+func (GreedyStrategy) Pack(problem PackingProblem) (map[uint32]uint64, error) {
+	sizes := sortedSizes(problem.PackSizes)
+	if problem.Quantity == 0 || len(sizes) == 0 {
+		return make(map[uint32]uint64), nil
+	}
+	if err := problem.checkCover(sizes); err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint32]uint64)
+	remainingQty := problem.Quantity
+
+	for _, size := range sizes {
+		if numPacks := min(remainingQty/uint64(size), problem.available(size)); numPacks > 0 {
+			result[size] = numPacks
+			remainingQty -= numPacks * uint64(size)
+		}
+	}
+
+	if problem.Mode.Name == FillUnderfill {
+		return result, nil
+	}
+
+	// If there's still a remaining quantity, use the smallest pack left in stock.
+	// Every size with packs left is larger than the remainder, so one pack covers it.
+	overfill := uint64(0)
+	for i := len(sizes) - 1; i >= 0 && remainingQty > 0; i-- {
+		if result[sizes[i]] < problem.available(sizes[i]) {
+			result[sizes[i]]++
+			overfill = uint64(sizes[i]) - remainingQty
+			remainingQty = 0
+		}
+	}
+
+	if _, high := problem.Mode.bounds(problem.Quantity, sizes[0]); overfill > high-problem.Quantity {
+		// The bounds stop at math.MaxUint64, past which the packs would overflow.
+		if high == math.MaxUint64 && problem.Mode.Name != FillExact {
+			return nil, ErrQuantityTooLarge
+		}
+		return nil, problem.Mode.noFit()
+	}
+
+	return result, nil
+}
+
+// OptimalStrategy ships the fewest items possible and, among those, uses the fewest packs.
+type OptimalStrategy struct{}
+
+// Name returns the registered name of the strategy.
+func (OptimalStrategy) Name() string { return StrategyOptimal }
+
+// Pack calculates the pack combination for the order quantity.
+func (OptimalStrategy) Pack(problem PackingProblem) (map[uint32]uint64, error) {
+	sizes := sortedSizes(problem.PackSizes)
+	if problem.Quantity == 0 || len(sizes) == 0 {
+		return make(map[uint32]uint64), nil
+	}
+	if err := problem.checkCover(sizes); err != nil {
+		return nil, err
+	}
+
+	solver, err := problem.solver(sizes, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The smallest reachable total not below the order quantity ships the fewest items.
+	total, err := problem.chooseTotal(solver, func(uint64, uint64) bool { return false })
+	if err != nil {
+		return nil, err
+	}
+
+	return solver.combination(total), nil
+}
+
+// Alternatives returns up to k combinations ranked like Pack: fewest items, then fewest packs.
+func (OptimalStrategy) Alternatives(problem PackingProblem, k int) ([]map[uint32]uint64, error) {
+	return problem.alternatives(false, k, fewerItems)
+}
+
+// FewestPacksStrategy uses the fewest packs possible and, among those, ships the fewest items.
+type FewestPacksStrategy struct{}
+
+// Name returns the registered name of the strategy.
+func (FewestPacksStrategy) Name() string { return StrategyFewestPacks }
+
+// Pack calculates the pack combination for the order quantity.
+func (FewestPacksStrategy) Pack(problem PackingProblem) (map[uint32]uint64, error) {
+	sizes := sortedSizes(problem.PackSizes)
+	if problem.Quantity == 0 || len(sizes) == 0 {
+		return make(map[uint32]uint64), nil
+	}
+	if err := problem.checkCover(sizes); err != nil {
+		return nil, err
+	}
+
+	solver, err := problem.solver(sizes, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	best, err := problem.chooseTotal(solver, func(total, best uint64) bool {
+		return solver.packCount(total) < solver.packCount(best)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return solver.combination(best), nil
+}
+
+// Alternatives returns up to k combinations ranked like Pack: fewest packs, then fewest items.
+func (FewestPacksStrategy) Alternatives(problem PackingProblem, k int) ([]map[uint32]uint64, error) {
+	return problem.alternatives(false, k, fewerPacks)
+}
+
+// MinCostStrategy covers the order at the lowest total cost, where a pack costs its price plus
+// its handling cost plus its weight at the shipping rate. Cost ties ship the fewest items,
+// then use the fewest packs.
+type MinCostStrategy struct{}
+
+// Name returns the registered name of the strategy.
+func (MinCostStrategy) Name() string { return StrategyMinCost }
+
+// Pack calculates the pack combination for the order quantity.
+func (MinCostStrategy) Pack(problem PackingProblem) (map[uint32]uint64, error) {
+	packs := sortedPacks(problem.PackSizes)
+	if problem.Quantity == 0 || len(packs) == 0 {
+		return make(map[uint32]uint64), nil
+	}
+
+	sizes := make([]uint32, len(packs))
+	unitCosts := make([]float64, len(packs))
+	for i, pack := range packs {
+		sizes[i] = pack.Size
+		unitCosts[i] = pack.UnitCost(problem.ShippingRate)
+	}
+	if err := problem.checkCover(sizes); err != nil {
+		return nil, err
+	}
+
+	// Costs are never negative, so dropping a pack never makes a covering combination dearer.
+	solver, err := problem.solver(sizes, unitCosts)
+	if err != nil {
+		return nil, err
+	}
+
+	best, err := problem.chooseTotal(solver, func(total, best uint64) bool {
+		return solver.totalCost(total) < solver.totalCost(best)-costEpsilon
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return solver.combination(best), nil
+}
+
+// Alternatives returns up to k combinations ranked like Pack: lowest cost, then fewest items, then
+// fewest packs.
+func (MinCostStrategy) Alternatives(problem PackingProblem, k int) ([]map[uint32]uint64, error) {
+	return problem.alternatives(true, k, lowerCost)
+}
+
+// sortedSizes returns the distinct sizes of the pack sizes in descending order.
+func sortedSizes(packSizes []models.PackSize) []uint32 {
+	packs := sortedPacks(packSizes)
+	sizes := make([]uint32, len(packs))
+	for i, pack := range packs {
+		sizes[i] = pack.Size
+	}
+
+	return sizes
+}
+
+// sortedPacks returns the pack sizes with distinct, non-zero sizes in descending order of size.
+func sortedPacks(packSizes []models.PackSize) []models.PackSize {
+	seen := make(map[uint32]bool, len(packSizes))
+	packs := make([]models.PackSize, 0, len(packSizes))
+	for _, pack := range packSizes {
+		if pack.Size == 0 || seen[pack.Size] {
+			continue
+		}
+		seen[pack.Size] = true
+		packs = append(packs, pack)
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].Size > packs[j].Size })
+
+	return packs
+}
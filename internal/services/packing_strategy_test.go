@@ -0,0 +1,286 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// oraclePackSets are the pack size sets the strategies are checked against exhaustively.
+var oraclePackSets = [][]uint32{
+	{250, 500},
+	{3, 5},
+	{23, 31, 53},
+	{7, 11, 13},
+	{6, 9, 20},
+	{1000, 500, 250, 2000},
+}
+
+// bruteForce enumerates every pack combination within stock that could be optimal and returns
+// the items and packs of the best one, where better reports whether a beats b. A nil stock is
+// unlimited; when nothing covers the order, both results are unreachable.
+func bruteForce(sizes []uint32, stock map[uint32]uint32, orderQty uint32, better func(aItems, aPacks, bItems, bPacks uint32) bool) (items, packs uint32) {
+	items, packs = unreachable, unreachable
+	if orderQty == 0 {
+		return 0, 0
+	}
+
+	var largest uint32
+	for _, size := range sizes {
+		largest = max(largest, size)
+	}
+	bound := orderQty + largest
+
+	var walk func(i int, total, count uint32)
+	walk = func(i int, total, count uint32) {
+		if i == len(sizes) {
+			if total >= orderQty && (items == unreachable || better(total, count, items, packs)) {
+				items, packs = total, count
+			}
+			return
+		}
+		for n := uint32(0); total+n*sizes[i] < bound; n++ {
+			if limit, ok := stock[sizes[i]]; ok && n > limit {
+				break
+			}
+			walk(i+1, total+n*sizes[i], count+n)
+		}
+	}
+	walk(0, 0, 0)
+
+	return items, packs
+}
+
+// toPackSizes wraps plain sizes into pack size models.
+func toPackSizes(sizes []uint32) []models.PackSize {
+	packSizes := make([]models.PackSize, 0, len(sizes))
+	for i, size := range sizes {
+		packSizes = append(packSizes, models.PackSize{ID: uint32(i + 1), Size: size})
+	}
+
+	return packSizes
+}
+
+// totals sums the items and packs of a pack combination.
+func totals(packs map[uint32]uint64) (items, count uint32) {
+	for size, n := range packs {
+		items += size * uint32(n)
+		count += uint32(n)
+	}
+
+	return items, count
+}
+
+func TestStrategiesMatchBruteForce(t *testing.T) {
+	testCases := []struct {
+		strategy PackingStrategy
+		better   func(aItems, aPacks, bItems, bPacks uint32) bool
+	}{
+		{
+			strategy: OptimalStrategy{},
+			better: func(aItems, aPacks, bItems, bPacks uint32) bool {
+				return aItems < bItems || (aItems == bItems && aPacks < bPacks)
+			},
+		},
+		{
+			strategy: FewestPacksStrategy{},
+			better: func(aItems, aPacks, bItems, bPacks uint32) bool {
+				return aPacks < bPacks || (aPacks == bPacks && aItems < bItems)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.strategy.Name(), func(t *testing.T) {
+			for _, sizes := range oraclePackSets {
+				// Check unlimited supply as well as a few packs of each size in stock.
+				limited := make(map[uint32]uint32, len(sizes))
+				for i, size := range sizes {
+					limited[size] = uint32(i + 2)
+				}
+
+				for _, stock := range []map[uint32]uint32{nil, limited} {
+					for orderQty := uint32(0); orderQty <= 600; orderQty++ {
+						msg := fmt.Sprintf("sizes %v, stock %v, quantity %d", sizes, stock, orderQty)
+						result, err := tc.strategy.Pack(PackingProblem{PackSizes: toPackSizes(sizes), Quantity: uint64(orderQty), Stock: stock})
+
+						expectedItems, expectedPacks := bruteForce(sizes, stock, orderQty, tc.better)
+						if expectedItems == unreachable {
+							var stockErr *InsufficientStockError
+							assert.ErrorAs(t, err, &stockErr, msg)
+							continue
+						}
+
+						assert.NoError(t, err, msg)
+						for size, n := range result {
+							if limit, ok := stock[size]; ok {
+								assert.LessOrEqual(t, n, uint64(limit), msg)
+							}
+						}
+						items, packs := totals(result)
+						assert.Equal(t, expectedItems, items, msg)
+						assert.Equal(t, expectedPacks, packs, msg)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestStrategies(t *testing.T) {
+	packSizes := toPackSizes([]uint32{250, 500, 1000})
+
+	testCases := []struct {
+		name         string
+		strategy     PackingStrategy
+		packSizes    []models.PackSize
+		orderQty     uint64
+		shippingRate float64
+		stock        map[uint32]uint32
+		expected     map[uint32]uint64
+	}{
+		{
+			name:      "Greedy tops up with the smallest pack",
+			strategy:  GreedyStrategy{},
+			packSizes: packSizes,
+			orderQty:  251,
+			expected:  map[uint32]uint64{250: 2},
+		},
+		{
+			name:      "Optimal ships fewest items",
+			strategy:  OptimalStrategy{},
+			packSizes: packSizes,
+			orderQty:  1001,
+			expected:  map[uint32]uint64{1000: 1, 250: 1},
+		},
+		{
+			name:      "Optimal uses more packs to ship fewer items",
+			strategy:  OptimalStrategy{},
+			packSizes: toPackSizes([]uint32{3, 5}),
+			orderQty:  9,
+			expected:  map[uint32]uint64{3: 3},
+		},
+		{
+			name:      "Fewest packs ships more items in fewer packs",
+			strategy:  FewestPacksStrategy{},
+			packSizes: toPackSizes([]uint32{3, 5}),
+			orderQty:  9,
+			expected:  map[uint32]uint64{5: 2},
+		},
+		{
+			name:     "Min cost uses more packs when they are cheaper",
+			strategy: MinCostStrategy{},
+			packSizes: []models.PackSize{
+				{ID: 1, Size: 1000, Price: 20},
+				{ID: 2, Size: 500, Price: 6},
+				{ID: 3, Size: 250, Price: 5},
+			},
+			orderQty: 1001,
+			expected: map[uint32]uint64{500: 2, 250: 1},
+		},
+		{
+			name:     "Min cost weighs shipping",
+			strategy: MinCostStrategy{},
+			packSizes: []models.PackSize{
+				{ID: 1, Size: 500, Price: 10, Weight: 1},
+				{ID: 2, Size: 250, Price: 4, Weight: 2},
+			},
+			orderQty:     500,
+			shippingRate: 2,
+			expected:     map[uint32]uint64{500: 1},
+		},
+		{
+			name:      "Min cost without prices ships fewest items",
+			strategy:  MinCostStrategy{},
+			packSizes: packSizes,
+			orderQty:  1001,
+			expected:  map[uint32]uint64{1000: 1, 250: 1},
+		},
+		{
+			name:      "Greedy respects stock",
+			strategy:  GreedyStrategy{},
+			packSizes: packSizes,
+			orderQty:  2100,
+			stock:     map[uint32]uint32{1000: 1, 500: 1},
+			expected:  map[uint32]uint64{1000: 1, 500: 1, 250: 3},
+		},
+		{
+			name:      "Optimal respects stock",
+			strategy:  OptimalStrategy{},
+			packSizes: packSizes,
+			orderQty:  1001,
+			stock:     map[uint32]uint32{250: 0},
+			expected:  map[uint32]uint64{1000: 1, 500: 1},
+		},
+		{
+			name:     "Min cost respects stock",
+			strategy: MinCostStrategy{},
+			packSizes: []models.PackSize{
+				{ID: 1, Size: 1000, Price: 20},
+				{ID: 2, Size: 500, Price: 6},
+				{ID: 3, Size: 250, Price: 5},
+			},
+			orderQty: 1001,
+			stock:    map[uint32]uint32{500: 1},
+			expected: map[uint32]uint64{500: 1, 250: 3},
+		},
+		{
+			name:     "No pack sizes",
+			strategy: OptimalStrategy{},
+			orderQty: 10,
+			expected: map[uint32]uint64{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.strategy.Pack(PackingProblem{
+				PackSizes:    tc.packSizes,
+				Quantity:     tc.orderQty,
+				ShippingRate: tc.shippingRate,
+				Stock:        tc.stock,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestStrategiesInsufficientStock(t *testing.T) {
+	problem := PackingProblem{
+		PackSizes: toPackSizes([]uint32{250, 500, 1000}),
+		Quantity:  2000,
+		Stock:     map[uint32]uint32{1000: 1, 500: 1, 250: 1},
+	}
+
+	for _, name := range StrategyNames() {
+		t.Run(name, func(t *testing.T) {
+			strategy, err := GetStrategy(name)
+			assert.NoError(t, err)
+
+			result, err := strategy.Pack(problem)
+			assert.Nil(t, result)
+
+			var stockErr *InsufficientStockError
+			if assert.ErrorAs(t, err, &stockErr) {
+				assert.Equal(t, uint64(1750), stockErr.Available)
+				assert.Equal(t, uint64(250), stockErr.Shortfall())
+				assert.Equal(t, problem.Stock, stockErr.Stock)
+				assert.EqualError(t, err, "insufficient stock: 2000 items ordered, 1750 available, short by 250")
+			}
+		})
+	}
+}
+
+func TestGetStrategy(t *testing.T) {
+	strategy, err := GetStrategy("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultStrategy, strategy.Name())
+
+	_, err = GetStrategy("unknown")
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+
+	assert.Equal(t, []string{StrategyFewestPacks, StrategyGreedy, StrategyMinCost, StrategyOptimal}, StrategyNames())
+}
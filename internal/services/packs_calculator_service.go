@@ -1,61 +1,747 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"runtime"
+	"sync"
+	"time"
+
 	"github.com/klemis/packs-calculator/internal/repositories"
+	"github.com/klemis/packs-calculator/models"
 )
 
-// PacksCalculator defines the interface for creating and deleting and calculating packs.
+// PacksCalculator defines the interface for managing pack sizes and calculating packs.
+// Pack size changes are made on behalf of an actor, who is recorded in the audit log.
 type PacksCalculator interface {
-	AddPackSize(size uint32) error
-	DeletePackSize(size uint32) error
-	CalculatePacks(orderQty uint32) (map[uint32]uint32, error)
+	AddPackSize(pack models.PackSize, actor string) error
+	DeletePackSize(size uint32, actor string) error
+	ListPackSizes() ([]models.PackSize, error)
+	GetPackSize(id uint32) (*models.PackSize, error)
+	UpdatePackSize(id uint32, update models.PackSizeUpdateRequest, actor string) (*models.PackSize, error)
+	ReplacePackSizes(packs []models.PackSize, actor string) (*models.CatalogChange, error)
+	ImportPackSizes(r io.Reader, opts ImportOptions) (*models.ImportReport, error)
+	ExportPackSizes(w io.Writer, format string) error
+	AnalysePackSizes(opts AnalysisOptions) (*models.PackSetAnalysis, error)
+	AnalyseImpact(proposal CatalogProposal, opts ImpactOptions) (*models.CatalogImpact, error)
+	CalculatePacks(orderQty uint64, opts CalculateOptions) (*models.Calculation, error)
+	CalculateBatch(items []BatchItem, opts CalculateOptions) (*BatchCalculation, error)
+	CalculateStream(items BatchItemSeq, opts CalculateOptions, emit func(BatchResult) error) error
+	CompareCalculation(orderQty uint64, opts CalculateOptions) (*CalculationComparison, error)
+	WarmSolutionCache() error
+	SolutionCacheStats() models.SolutionCacheStats
+}
+
+// CalculateOptions tunes how CalculatePacks combines the pack sizes.
+type CalculateOptions struct {
+	// Strategy is the name of a registered PackingStrategy; empty selects DefaultStrategy.
+	Strategy string
+	// ShippingRate is the shipping cost per unit of pack weight used to price the packs.
+	ShippingRate float64
+	// CatalogVersion pins the calculation to a historical catalog version; 0 uses the latest.
+	// Pinned calculations reproduce past results, so they ignore the current stock.
+	CatalogVersion uint32
+	// Caller names who asked for the calculation in the calculation history.
+	Caller string
+	// Mode bounds how far the packs may stray from the order quantity; the zero value overfills.
+	Mode FillMode
+	// Alternatives asks for up to this many of the best pack combinations, capped at MaxAlternatives.
+	// The strategy must be a RankingStrategy.
+	Alternatives int
+	// PackSizes runs the calculation against this in-memory set instead of the catalog. Such what-if
+	// calculations ignore CatalogVersion and the stock, and are not kept in the calculation history.
+	PackSizes []models.PackSize
+}
+
+// BatchItem is one order quantity of a batch calculation, with an optional caller-supplied ID.
+type BatchItem struct {
+	// Line is the line of the item in a streamed order file; 0 for items not read from a file.
+	Line     int
+	ID       string
+	Quantity uint64
+}
+
+// BatchItemSeq yields the items of a streamed batch in order, each with the error that kept it from
+// being read, if any.
+type BatchItemSeq iter.Seq2[BatchItem, error]
+
+// BatchResult is the outcome of calculating one item of a batch: its calculation, or why it failed.
+type BatchResult struct {
+	Item        BatchItem
+	Calculation *models.Calculation
+	Err         error
+}
+
+// BatchCalculation is the outcome of a batch calculation, with one result per item in request order.
+type BatchCalculation struct {
+	Strategy       string
+	CatalogVersion uint32
+	Results        []BatchResult
+}
+
+// CalculationComparison sets a what-if calculation against the same order calculated with the live
+// catalog and stock. LiveErr tells why the live catalog could not calculate the order, if it could not.
+type CalculationComparison struct {
+	WhatIf  *models.Calculation
+	Live    *models.Calculation
+	LiveErr error
 }
 
 // PacksCalculatorService is an implementation of PacksCalculatorService
 type PacksCalculatorService struct {
-	repo repositories.PackSizeRepository
+	repo            repositories.PackSizeRepository
+	stockRepo       repositories.StockRepository
+	calculationRepo repositories.CalculationRepository
+	// cache answers the most common calculations against the live catalog without solving them.
+	cache *solutionCache
 }
 
-// NewPacksCalculatorService creates a new instance of PacksCalculator with injected repository.
-func NewPacksCalculatorService(packSizeRepo repositories.PackSizeRepository) PacksCalculator {
+// NewPacksCalculatorService creates a new instance of PacksCalculator with injected repositories.
+func NewPacksCalculatorService(
+	packSizeRepo repositories.PackSizeRepository,
+	stockRepo repositories.StockRepository,
+	calculationRepo repositories.CalculationRepository,
+) PacksCalculator {
 	return &PacksCalculatorService{
-		repo: packSizeRepo,
+		repo:            packSizeRepo,
+		stockRepo:       stockRepo,
+		calculationRepo: calculationRepo,
+		cache:           newSolutionCache(SolutionCacheLimit, SolutionCacheMaxSizes),
 	}
 }
 
 // AddPackSize inserts a new pack size into the database.
-func (s *PacksCalculatorService) AddPackSize(size uint32) error {
-	return s.repo.CreatePackSize(size)
+func (s *PacksCalculatorService) AddPackSize(pack models.PackSize, actor string) error {
+	if pack.Size == 0 {
+		return models.ErrInvalidSize
+	}
+
+	if err := s.repo.CreatePackSize(pack, actor); err != nil {
+		return err
+	}
+	s.cache.add(pack.Size)
+
+	return nil
 }
 
 // DeletePackSize removes a pack size from the database by size.
-func (s *PacksCalculatorService) DeletePackSize(size uint32) error {
-	return s.repo.DeletePackSize(size)
+func (s *PacksCalculatorService) DeletePackSize(size uint32, actor string) error {
+	if err := s.repo.DeletePackSize(size, actor); err != nil {
+		return err
+	}
+	s.cache.remove(size)
+
+	return nil
 }
 
-// CalculatePacks calculates the optimal pack sizes for a given order quantity.
-func (s *PacksCalculatorService) CalculatePacks(orderQty uint32) (map[uint32]uint32, error) {
-	// Get packSizes ordered in desc order.
+// ListPackSizes returns every configured pack size, largest first.
+func (s *PacksCalculatorService) ListPackSizes() ([]models.PackSize, error) {
 	packSizes, err := s.repo.GetPackSizes()
 	if err != nil {
 		return nil, err
 	}
+	if packSizes == nil {
+		packSizes = []models.PackSize{}
+	}
+
+	return packSizes, nil
+}
+
+// ReplacePackSizes atomically replaces every configured pack size with packs.
+func (s *PacksCalculatorService) ReplacePackSizes(packs []models.PackSize, actor string) (*models.CatalogChange, error) {
+	if err := validatePackSizes(packs); err != nil {
+		return nil, err
+	}
+
+	change, err := s.repo.ReplacePackSizes(packs, actor)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.rebuild(sortedSizes(packs))
+
+	return change, nil
+}
+
+// validatePackSizes checks that a full set of pack sizes is not empty and has no zero or repeated sizes.
+func validatePackSizes(packs []models.PackSize) error {
+	if len(packs) == 0 {
+		return models.ErrNoPackSizes
+	}
+
+	seen := make(map[uint32]bool, len(packs))
+	for _, pack := range packs {
+		if pack.Size == 0 {
+			return models.ErrInvalidSize
+		}
+		if seen[pack.Size] {
+			return models.ErrDuplicateSize
+		}
+		seen[pack.Size] = true
+	}
+
+	return nil
+}
+
+// ImportPackSizes reads a catalog file and merges it into, or replaces, the catalog in one transaction.
+// The file is rejected as a whole when any line is invalid. Imports that change nothing, and dry runs,
+// leave the catalog untouched.
+func (s *PacksCalculatorService) ImportPackSizes(r io.Reader, opts ImportOptions) (*models.ImportReport, error) {
+	if opts.Mode == "" {
+		opts.Mode = ImportModeMerge
+	}
+
+	packs, err := decodeCatalog(r, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetPackSizes()
+	if err != nil {
+		return nil, err
+	}
+
+	report := diffCatalog(current, packs, opts.Mode == ImportModeReplace)
+	report.Mode = opts.Mode
+	report.DryRun = opts.DryRun
+	if opts.DryRun || len(report.Added)+len(report.Updated)+len(report.Removed) == 0 {
+		return report, nil
+	}
+
+	if opts.Mode == ImportModeReplace {
+		_, err = s.repo.ReplacePackSizes(packs, opts.Actor)
+	} else {
+		err = s.repo.MergePackSizes(packs, opts.Actor)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.Mode == ImportModeReplace {
+		s.cache.rebuild(sortedSizes(packs))
+	} else {
+		for _, size := range report.Added {
+			s.cache.add(size)
+		}
+	}
+
+	return report, nil
+}
+
+// ExportPackSizes writes every configured pack size to w in the given format, largest first.
+func (s *PacksCalculatorService) ExportPackSizes(w io.Writer, format string) error {
+	packSizes, err := s.repo.GetPackSizes()
+	if err != nil {
+		return err
+	}
+
+	return encodeCatalog(w, format, packSizes)
+}
+
+// AnalysePackSizes describes how the configured pack sizes cover order quantities: which cannot be
+// shipped exactly, how far orders overfill and which sizes the others make redundant.
+func (s *PacksCalculatorService) AnalysePackSizes(opts AnalysisOptions) (*models.PackSetAnalysis, error) {
+	packSizes, err := s.repo.GetPackSizes()
+	if err != nil {
+		return nil, err
+	}
+
+	return analysePackSet(packSizes, opts)
+}
+
+// AnalyseImpact reports how a proposed catalog change would repack a range of order quantities, or
+// the quantities of the calculation history, without making the change. Both sides are calculated as
+// what-if calculations, so they ignore the stock and are not kept in the calculation history.
+func (s *PacksCalculatorService) AnalyseImpact(proposal CatalogProposal, opts ImpactOptions) (*models.CatalogImpact, error) {
+	current, err := s.repo.GetPackSizes()
+	if err != nil {
+		return nil, err
+	}
+	if len(current) == 0 {
+		// Orders cannot be calculated without pack sizes, so there is nothing to compare against.
+		return nil, models.ErrNoPackSizes
+	}
+	proposed, err := proposedCatalog(current, proposal)
+	if err != nil {
+		return nil, err
+	}
+
+	currentSetup, err := s.prepareCalculation(CalculateOptions{Strategy: opts.Strategy, PackSizes: current})
+	if err != nil {
+		return nil, err
+	}
+	proposedSetup, err := s.prepareCalculation(CalculateOptions{Strategy: opts.Strategy, PackSizes: proposed})
+	if err != nil {
+		return nil, err
+	}
+
+	source, quantities := ImpactSourceRange, []models.QuantityCount(nil)
+	if opts.QuantityTo > 0 {
+		quantities, err = impactQuantities(opts.QuantityFrom, opts.QuantityTo)
+	} else {
+		source = ImpactSourceHistory
+		quantities, err = s.calculationRepo.GetQuantityDistribution(opts.History)
+		if err == nil && len(quantities) > MaxImpactQuantities {
+			err = fmt.Errorf("%w: the history holds %d quantities, at most %d can be recalculated; narrow its window",
+				ErrInvalidImpactRange, len(quantities), MaxImpactQuantities)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	before := make([]*models.Calculation, len(quantities))
+	after := make([]*models.Calculation, len(quantities))
+	errs := make([]error, len(quantities))
+	inParallel(len(quantities), func(i int) {
+		if before[i], errs[i] = currentSetup.calculate(quantities[i].Quantity); errs[i] == nil {
+			after[i], errs[i] = proposedSetup.calculate(quantities[i].Quantity)
+		}
+	})
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	impact := compareImpact(quantities, before, after)
+	impact.Change = proposal.Kind
+	impact.Source = source
+	impact.Strategy = currentSetup.strategy.Name()
+	impact.CurrentSizes = sizesOf(current)
+	impact.ProposedSizes = sizesOf(proposed)
+
+	return impact, nil
+}
+
+// GetPackSize returns a pack size by its ID.
+func (s *PacksCalculatorService) GetPackSize(id uint32) (*models.PackSize, error) {
+	return s.repo.GetPackSize(id)
+}
+
+// UpdatePackSize applies the fields present in update to a pack size and returns the result.
+func (s *PacksCalculatorService) UpdatePackSize(id uint32, update models.PackSizeUpdateRequest, actor string) (*models.PackSize, error) {
+	if update.Size != nil && *update.Size == 0 {
+		return nil, models.ErrInvalidSize
+	}
+
+	pack, err := s.repo.GetPackSize(id)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := pack.Size
+	if update.Size != nil {
+		pack.Size = *update.Size
+	}
+	if update.Price != nil {
+		pack.Price = *update.Price
+	}
+	if update.Weight != nil {
+		pack.Weight = *update.Weight
+	}
+	if update.HandlingCost != nil {
+		pack.HandlingCost = *update.HandlingCost
+	}
+
+	if err := s.repo.UpdatePackSize(*pack, actor); err != nil {
+		return nil, err
+	}
+	if pack.Size != previous {
+		s.cache.resize(previous, pack.Size)
+	}
+
+	return pack, nil
+}
+
+// WarmSolutionCache precomputes the solutions the cache holds for the live catalog, so that the first
+// calculations do not wait for them. A catalog without pack sizes leaves nothing to precompute.
+func (s *PacksCalculatorService) WarmSolutionCache() error {
+	packSizes, err := s.repo.GetPackSizes()
+	if err != nil {
+		return err
+	}
+	s.cache.rebuild(sortedSizes(packSizes))
+
+	return nil
+}
+
+// SolutionCacheStats describes the solution cache: the pack sizes and quantities it holds, the memory
+// they take and how often calculations against the live catalog were answered from it.
+func (s *PacksCalculatorService) SolutionCacheStats() models.SolutionCacheStats {
+	return s.cache.stats()
+}
+
+// CalculatePacks calculates the pack sizes for a given order quantity using the requested strategy.
+// Pack sizes with tracked stock never use more packs than are on hand, unless a historical catalog
+// version is requested. Every calculation against the catalog is kept in the calculation history.
+func (s *PacksCalculatorService) CalculatePacks(orderQty uint64, opts CalculateOptions) (*models.Calculation, error) {
+	started := time.Now()
+
+	setup, err := s.prepareCalculation(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	calculation, err := setup.calculate(orderQty)
+	if err != nil {
+		return nil, err
+	}
+	if !setup.recorded() {
+		return calculation, nil
+	}
+
+	record := setup.record(calculation, time.Since(started))
+	if err := s.calculationRepo.SaveCalculation(record); err != nil {
+		return nil, err
+	}
+	calculation.ID = record.ID
+
+	return calculation, nil
+}
+
+// CompareCalculation calculates an order against the what-if pack sizes of opts and, side by side,
+// against the live catalog and stock with the same strategy and shipping rate. Neither calculation is
+// kept in the calculation history. Failing to calculate the order with the live catalog does not fail
+// the comparison.
+func (s *PacksCalculatorService) CompareCalculation(orderQty uint64, opts CalculateOptions) (*CalculationComparison, error) {
+	if opts.PackSizes == nil {
+		return nil, models.ErrNoPackSizes
+	}
 
-	result := make(map[uint32]uint32)
-	remainingQty := orderQty
+	whatIf, err := s.CalculatePacks(orderQty, opts)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, pack := range packSizes {
-		if remainingQty >= pack.Size {
-			numPacks := remainingQty / pack.Size
-			remainingQty = remainingQty % pack.Size
-			result[pack.Size] = numPacks
+	comparison := &CalculationComparison{WhatIf: whatIf}
+	liveOpts := opts
+	liveOpts.PackSizes, liveOpts.CatalogVersion = nil, 0
+	setup, err := s.prepareCalculation(liveOpts)
+	if err == nil {
+		comparison.Live, err = setup.calculate(orderQty)
+	}
+	if err != nil {
+		// Failures to reach the catalog are not about the order, so they fail the comparison.
+		var stockErr *InsufficientStockError
+		if !errors.Is(err, models.ErrNoPackSizes) && !errors.Is(err, ErrNoExactFit) &&
+			!errors.Is(err, ErrOverfillExceeded) && !errors.As(err, &stockErr) {
+			return nil, err
 		}
+		comparison.LiveErr = err
 	}
 
-	// If there's still a remaining quantity, use the smallest pack.
-	if remainingQty > 0 && len(packSizes) > 0 {
-		result[packSizes[len(packSizes)-1].Size]++
+	return comparison, nil
+}
+
+// CalculateBatch calculates the packs for many order quantities against one load of the catalog and
+// stock, solving them in parallel on a bounded pool of workers. Every item is calculated on its own
+// against the full stock. Items that cannot be calculated report their error in their result; the
+// others are kept in the calculation history.
+func (s *PacksCalculatorService) CalculateBatch(items []BatchItem, opts CalculateOptions) (*BatchCalculation, error) {
+	setup, err := s.prepareCalculation(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(items))
+	records := make([]*models.CalculationRecord, len(items))
+	inParallel(len(items), func(i int) {
+		started := time.Now()
+		calculation, err := setup.calculate(items[i].Quantity)
+		results[i] = BatchResult{Item: items[i], Calculation: calculation, Err: err}
+		if err == nil && setup.recorded() {
+			records[i] = setup.record(calculation, time.Since(started))
+		}
+	})
+
+	saved := make([]*models.CalculationRecord, 0, len(records))
+	for _, record := range records {
+		if record != nil {
+			saved = append(saved, record)
+		}
+	}
+	if err := s.calculationRepo.SaveCalculations(saved); err != nil {
+		return nil, err
+	}
+	for i, record := range records {
+		if record != nil {
+			results[i].Calculation.ID = record.ID
+		}
+	}
+
+	return &BatchCalculation{
+		Strategy:       setup.strategy.Name(),
+		CatalogVersion: setup.catalog.ID,
+		Results:        results,
+	}, nil
+}
+
+// inParallel calls fn with every index below n on a bounded pool of workers and waits for them all.
+func inParallel(n int, fn func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := range n {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// CalculateStream calculates the packs for every item of an unbounded sequence against one load of the
+// catalog and stock, emitting the results in order as they are computed. Each result is recorded in the
+// calculation history and emitted before the next item is read, so a client sees it without waiting for
+// more input and memory use does not grow with the number of items. Items that cannot be read or
+// calculated report their error in their result without ending the stream. An error is returned when
+// the stream cannot be calculated at all, or emitting or recording results fails.
+func (s *PacksCalculatorService) CalculateStream(
+	items BatchItemSeq,
+	opts CalculateOptions,
+	emit func(BatchResult) error,
+) error {
+	setup, err := s.prepareCalculation(opts)
+	if err != nil {
+		return err
+	}
+
+	for item, err := range items {
+		result := BatchResult{Item: item, Err: err}
+		if err == nil {
+			started := time.Now()
+			result.Calculation, result.Err = setup.calculate(item.Quantity)
+			if result.Err == nil && setup.recorded() {
+				record := setup.record(result.Calculation, time.Since(started))
+				if err := s.calculationRepo.SaveCalculation(record); err != nil {
+					return err
+				}
+				result.Calculation.ID = record.ID
+			}
+		}
+
+		if err := emit(result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// calculationSetup is what calculating packs needs besides the order quantity, loaded once so that
+// many quantities can be calculated against it.
+type calculationSetup struct {
+	strategy PackingStrategy
+	catalog  *models.CatalogVersion
+	// stock caps the packs used per size; nil leaves the supply unbounded.
+	stock map[uint32]uint32
+	opts  CalculateOptions
+	// cache answers calculations against the live catalog; nil for historical and what-if catalogs.
+	cache *solutionCache
+}
+
+// prepareCalculation resolves the strategy and loads the catalog version and stock that opts ask for,
+// or takes the what-if pack sizes of opts as they are.
+func (s *PacksCalculatorService) prepareCalculation(opts CalculateOptions) (*calculationSetup, error) {
+	strategy, err := GetStrategy(opts.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.Mode.validate(); err != nil {
+		return nil, err
+	}
+	if _, ranks := strategy.(RankingStrategy); opts.Alternatives > 0 && !ranks {
+		return nil, ErrAlternativesUnsupported
+	}
+
+	if opts.PackSizes != nil {
+		if err := validatePackSizes(opts.PackSizes); err != nil {
+			return nil, err
+		}
+
+		catalog := &models.CatalogVersion{PackSizes: opts.PackSizes}
+		return &calculationSetup{strategy: strategy, catalog: catalog, opts: opts}, nil
+	}
+
+	catalog, err := s.repo.GetCatalogVersion(opts.CatalogVersion)
+	if err != nil {
+		return nil, err
+	}
+	if len(catalog.PackSizes) == 0 {
+		return nil, models.ErrNoPackSizes
+	}
+
+	var limits map[uint32]uint32
+	var cache *solutionCache
+	if opts.CatalogVersion == 0 {
+		cache = s.cache
+		stock, err := s.stockRepo.GetStock()
+		if err != nil {
+			return nil, err
+		}
+
+		limits = make(map[uint32]uint32, len(stock))
+		for _, item := range stock {
+			limits[item.Size] = item.Quantity
+		}
+	}
+
+	return &calculationSetup{strategy: strategy, catalog: catalog, stock: limits, opts: opts, cache: cache}, nil
+}
+
+// calculate combines the pack sizes for one order quantity. It is safe for concurrent use.
+func (c *calculationSetup) calculate(orderQty uint64) (*models.Calculation, error) {
+	problem := PackingProblem{
+		PackSizes:    c.catalog.PackSizes,
+		Quantity:     orderQty,
+		ShippingRate: c.opts.ShippingRate,
+		Stock:        c.stock,
+		Mode:         c.opts.Mode,
+	}
+	packs, err := c.pack(problem)
+	if err != nil {
+		return nil, err
+	}
+
+	calculation := &models.Calculation{
+		Quantity:       orderQty,
+		Strategy:       c.strategy.Name(),
+		CatalogVersion: c.catalog.ID,
+		Packs:          packs,
+		Cost:           costBreakdown(c.catalog.PackSizes, packs, c.opts.ShippingRate),
+	}
+	if c.opts.Alternatives > 0 {
+		if calculation.Alternatives, err = c.alternatives(problem, packs); err != nil {
+			return nil, err
+		}
+	}
+
+	return calculation, nil
+}
+
+// pack combines the pack sizes for a problem, looking the combination up in the solution cache when it
+// holds it.
+func (c *calculationSetup) pack(problem PackingProblem) (map[uint32]uint64, error) {
+	if c.cache != nil {
+		if packs, ok := c.cache.solve(problem, c.strategy); ok {
+			return packs, nil
+		}
+	}
+
+	return c.strategy.Pack(problem)
+}
+
+// alternatives ranks the best pack combinations for a problem, leading with the combination the
+// calculation chose so that ties rank it first.
+func (c *calculationSetup) alternatives(problem PackingProblem, chosen map[uint32]uint64) ([]models.PackAlternative, error) {
+	k := min(c.opts.Alternatives, MaxAlternatives)
+	ranked, err := c.strategy.(RankingStrategy).Alternatives(problem, k)
+	if err != nil {
+		return nil, err
+	}
+
+	combinations := []map[uint32]uint64{chosen}
+	for _, packs := range ranked {
+		if len(combinations) < k && !maps.Equal(packs, chosen) {
+			combinations = append(combinations, packs)
+		}
+	}
+
+	alternatives := make([]models.PackAlternative, len(combinations))
+	for i, packs := range combinations {
+		items := packedItems(packs)
+		alternatives[i] = models.PackAlternative{
+			Packs:     packs,
+			Items:     items,
+			Overfill:  items - min(items, problem.Quantity),
+			PackCount: PackCount(packs),
+			TotalCost: costBreakdown(c.catalog.PackSizes, packs, c.opts.ShippingRate).Total,
+		}
+	}
+
+	return alternatives, nil
+}
+
+// recorded reports whether calculations are kept in the calculation history, which what-if
+// calculations are not.
+func (c *calculationSetup) recorded() bool {
+	return c.opts.PackSizes == nil
+}
+
+// record describes a calculation for the calculation history.
+func (c *calculationSetup) record(calculation *models.Calculation, latency time.Duration) *models.CalculationRecord {
+	items := packedItems(calculation.Packs)
+	// Underfilled calculations ship fewer items than ordered, so they have no overfill.
+	overfill := items - min(items, calculation.Quantity)
+
+	return &models.CalculationRecord{
+		Quantity:       calculation.Quantity,
+		Strategy:       calculation.Strategy,
+		CatalogVersion: calculation.CatalogVersion,
+		ShippingRate:   c.opts.ShippingRate,
+		Packs:          calculation.Packs,
+		Items:          items,
+		Overfill:       overfill,
+		TotalCost:      calculation.Cost.Total,
+		LatencyMicros:  latency.Microseconds(),
+		Caller:         c.opts.Caller,
+	}
+}
+
+// packedItems returns the number of items a pack combination holds.
+func packedItems(packs map[uint32]uint64) uint64 {
+	var items uint64
+	for size, count := range packs {
+		items += uint64(size) * count
+	}
+
+	return items
+}
+
+// PackCount returns the number of packs in a combination.
+func PackCount(packs map[uint32]uint64) uint64 {
+	var count uint64
+	for _, n := range packs {
+		count += n
+	}
+
+	return count
+}
+
+// costBreakdown prices a pack combination line by line, largest pack size first.
+func costBreakdown(packSizes []models.PackSize, packs map[uint32]uint64, shippingRate float64) models.CostBreakdown {
+	breakdown := models.CostBreakdown{Lines: []models.CostLine{}}
+	for _, pack := range sortedPacks(packSizes) {
+		count := packs[pack.Size]
+		if count == 0 {
+			continue
+		}
+
+		n := float64(count)
+		line := models.CostLine{
+			Size:         pack.Size,
+			Count:        count,
+			Price:        pack.Price * n,
+			HandlingCost: pack.HandlingCost * n,
+			Weight:       pack.Weight * n,
+			ShippingCost: pack.Weight * shippingRate * n,
+			Total:        pack.UnitCost(shippingRate) * n,
+		}
+		breakdown.Lines = append(breakdown.Lines, line)
+		breakdown.Price += line.Price
+		breakdown.HandlingCost += line.HandlingCost
+		breakdown.Weight += line.Weight
+		breakdown.ShippingCost += line.ShippingCost
+		breakdown.Total += line.Total
 	}
 
-	return result, nil
+	return breakdown
 }
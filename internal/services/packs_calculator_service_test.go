@@ -1,17 +1,22 @@
 package services
 
 import (
+	"errors"
+	"testing"
+
 	"github.com/golang/mock/gomock"
 	"github.com/klemis/packs-calculator/internal/repositories/mocks"
 	"github.com/klemis/packs-calculator/models"
 	"github.com/stretchr/testify/assert"
-	"testing"
 )
 
 func TestCalculatePacks(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	// Create a mock repository.
 	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+	mockCalculationRepo.EXPECT().SaveCalculation(gomock.Any()).Return(nil).AnyTimes()
 
 	mockPackSizes := []models.PackSize{
 		{ID: 1, Size: 5000},
@@ -22,61 +27,763 @@ func TestCalculatePacks(t *testing.T) {
 	}
 
 	// Setting up the mock to return these pack sizes.
-	mockRepo.EXPECT().GetPackSizes().Return(mockPackSizes, nil).AnyTimes()
+	mockRepo.EXPECT().GetCatalogVersion(uint32(0)).Return(&models.CatalogVersion{ID: 1, PackSizes: mockPackSizes}, nil).AnyTimes()
+	mockStockRepo.EXPECT().GetStock().Return(nil, nil).AnyTimes()
 
 	// Initialize the service with the mocked repository.
-	service := NewPacksCalculatorService(mockRepo)
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
 
 	testCases := []struct {
 		name        string
-		orderQty    uint32
-		expected    map[uint32]uint32
+		orderQty    uint64
+		expected    map[uint32]uint64
 		expectError bool
 	}{
 		{
 			name:     "Exact match with large packs",
 			orderQty: 5000,
-			expected: map[uint32]uint32{5000: 1},
+			expected: map[uint32]uint64{5000: 1},
 		},
 		{
 			name:     "Multiple packs, exact match",
 			orderQty: 7500,
-			expected: map[uint32]uint32{5000: 1, 2000: 1, 500: 1},
+			expected: map[uint32]uint64{5000: 1, 2000: 1, 500: 1},
 		},
 		{
 			name:     "Use smallest pack for remainder",
 			orderQty: 5250,
-			expected: map[uint32]uint32{5000: 1, 250: 1},
+			expected: map[uint32]uint64{5000: 1, 250: 1},
 		},
 		{
 			name:     "Large order with multiple pack sizes",
 			orderQty: 13000,
-			expected: map[uint32]uint32{5000: 2, 2000: 1, 1000: 1},
+			expected: map[uint32]uint64{5000: 2, 2000: 1, 1000: 1},
 		},
 		{
 			name:     "Smallest pack for remainder",
 			orderQty: 2650,
-			expected: map[uint32]uint32{2000: 1, 500: 1, 250: 1},
+			expected: map[uint32]uint64{2000: 1, 500: 1, 250: 1},
 		},
 		{
 			name:     "Order less than smallest pack size",
 			orderQty: 100,
-			expected: map[uint32]uint32{250: 1}, // Smallest pack size used
+			expected: map[uint32]uint64{250: 1}, // Smallest pack size used
+		},
+		{
+			name:     "Single larger pack beats two smaller ones",
+			orderQty: 251,
+			expected: map[uint32]uint64{500: 1},
+		},
+		{
+			name:     "Zero quantity",
+			orderQty: 0,
+			expected: map[uint32]uint64{},
 		},
 	}
 
 	// Run all test cases
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := service.CalculatePacks(tc.orderQty)
+			result, err := service.CalculatePacks(tc.orderQty, CalculateOptions{})
 
 			if tc.expectError {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, StrategyOptimal, result.Strategy)
+			}
+
+			assert.Equal(t, tc.expected, result.Packs)
+		})
+	}
+}
+
+func TestCalculatePacksNonDivisibleSizes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+	mockCalculationRepo.EXPECT().SaveCalculation(gomock.Any()).Return(nil).AnyTimes()
+
+	mockPackSizes := []models.PackSize{
+		{ID: 1, Size: 53},
+		{ID: 2, Size: 31},
+		{ID: 3, Size: 23},
+	}
+	mockRepo.EXPECT().GetCatalogVersion(uint32(0)).Return(&models.CatalogVersion{ID: 1, PackSizes: mockPackSizes}, nil).Times(1)
+	mockStockRepo.EXPECT().GetStock().Return(nil, nil).Times(1)
+
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	result, err := service.CalculatePacks(500000, CalculateOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint32]uint64{23: 2, 31: 7, 53: 9429}, result.Packs)
+}
+
+func TestCalculatePacksWithStrategy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+	mockCalculationRepo.EXPECT().SaveCalculation(gomock.Any()).Return(nil).AnyTimes()
+
+	mockPackSizes := []models.PackSize{
+		{ID: 1, Size: 500},
+		{ID: 2, Size: 250},
+	}
+	mockRepo.EXPECT().GetCatalogVersion(uint32(0)).Return(&models.CatalogVersion{ID: 1, PackSizes: mockPackSizes}, nil).AnyTimes()
+	mockStockRepo.EXPECT().GetStock().Return(nil, nil).AnyTimes()
+
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	testCases := []struct {
+		name             string
+		strategy         string
+		expectedStrategy string
+		expectedPacks    map[uint32]uint64
+		expectedErr      error
+	}{
+		{
+			name:             "Default strategy",
+			strategy:         "",
+			expectedStrategy: StrategyOptimal,
+			expectedPacks:    map[uint32]uint64{500: 1},
+		},
+		{
+			name:             "Legacy greedy strategy",
+			strategy:         StrategyGreedy,
+			expectedStrategy: StrategyGreedy,
+			expectedPacks:    map[uint32]uint64{250: 2},
+		},
+		{
+			name:        "Unknown strategy",
+			strategy:    "cheapest",
+			expectedErr: ErrUnknownStrategy,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := service.CalculatePacks(251, CalculateOptions{Strategy: tc.strategy})
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.Nil(t, result)
+				return
 			}
 
-			assert.Equal(t, tc.expected, result)
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(251), result.Quantity)
+			assert.Equal(t, tc.expectedStrategy, result.Strategy)
+			assert.Equal(t, tc.expectedPacks, result.Packs)
+		})
+	}
+}
+
+func TestCalculatePacksCostBreakdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+	mockCalculationRepo.EXPECT().SaveCalculation(gomock.Any()).Return(nil).AnyTimes()
+
+	mockPackSizes := []models.PackSize{
+		{ID: 1, Size: 1000, Price: 20, Weight: 4, HandlingCost: 1},
+		{ID: 2, Size: 500, Price: 6, Weight: 2, HandlingCost: 1},
+		{ID: 3, Size: 250, Price: 5, Weight: 1, HandlingCost: 1},
+	}
+	mockRepo.EXPECT().GetCatalogVersion(uint32(0)).Return(&models.CatalogVersion{ID: 1, PackSizes: mockPackSizes}, nil).Times(1)
+	mockStockRepo.EXPECT().GetStock().Return(nil, nil).Times(1)
+
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	result, err := service.CalculatePacks(1001, CalculateOptions{Strategy: StrategyMinCost, ShippingRate: 0.5})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint32]uint64{500: 2, 250: 1}, result.Packs)
+	assert.Equal(t, models.CostBreakdown{
+		Lines: []models.CostLine{
+			{Size: 500, Count: 2, Price: 12, HandlingCost: 2, Weight: 4, ShippingCost: 2, Total: 16},
+			{Size: 250, Count: 1, Price: 5, HandlingCost: 1, Weight: 1, ShippingCost: 0.5, Total: 6.5},
+		},
+		Price:        17,
+		HandlingCost: 3,
+		Weight:       5,
+		ShippingCost: 2.5,
+		Total:        22.5,
+	}, result.Cost)
+}
+
+func TestCalculatePacksAlternatives(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+	mockCalculationRepo.EXPECT().SaveCalculation(gomock.Any()).Return(nil).AnyTimes()
+
+	mockPackSizes := []models.PackSize{
+		{ID: 1, Size: 1000, Price: 20, Weight: 4, HandlingCost: 1},
+		{ID: 2, Size: 500, Price: 6, Weight: 2, HandlingCost: 1},
+		{ID: 3, Size: 250, Price: 5, Weight: 1, HandlingCost: 1},
+	}
+	mockRepo.EXPECT().GetCatalogVersion(uint32(0)).Return(&models.CatalogVersion{ID: 1, PackSizes: mockPackSizes}, nil).AnyTimes()
+	mockStockRepo.EXPECT().GetStock().Return(nil, nil).AnyTimes()
+
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	result, err := service.CalculatePacks(1001, CalculateOptions{Strategy: StrategyMinCost, ShippingRate: 0.5, Alternatives: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.PackAlternative{
+		{Packs: map[uint32]uint64{500: 2, 250: 1}, Items: 1250, Overfill: 249, PackCount: 3, TotalCost: 22.5},
+		{Packs: map[uint32]uint64{500: 3}, Items: 1500, Overfill: 499, PackCount: 3, TotalCost: 24},
+		{Packs: map[uint32]uint64{500: 1, 250: 3}, Items: 1250, Overfill: 249, PackCount: 4, TotalCost: 27.5},
+	}, result.Alternatives)
+	assert.Equal(t, result.Packs, result.Alternatives[0].Packs)
+
+	// The count is capped, and the chosen combination leads even when others rank with it.
+	result, err = service.CalculatePacks(1000, CalculateOptions{Alternatives: MaxAlternatives + 5})
+	assert.NoError(t, err)
+	assert.Len(t, result.Alternatives, MaxAlternatives)
+	assert.Equal(t, map[uint32]uint64{1000: 1}, result.Alternatives[0].Packs)
+
+	result, err = service.CalculatePacks(1000, CalculateOptions{})
+	assert.NoError(t, err)
+	assert.Nil(t, result.Alternatives)
+
+	_, err = service.CalculatePacks(1000, CalculateOptions{Strategy: StrategyGreedy, Alternatives: 2})
+	assert.ErrorIs(t, err, ErrAlternativesUnsupported)
+}
+
+func TestCalculatePacksWithStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+	mockCalculationRepo.EXPECT().SaveCalculation(gomock.Any()).Return(nil).AnyTimes()
+
+	mockPackSizes := []models.PackSize{
+		{ID: 1, Size: 1000},
+		{ID: 2, Size: 500},
+		{ID: 3, Size: 250},
+	}
+	mockStock := []models.PackStock{
+		{Size: 1000, Quantity: 2},
+		{Size: 250, Quantity: 0},
+	}
+	mockRepo.EXPECT().GetCatalogVersion(uint32(0)).Return(&models.CatalogVersion{ID: 1, PackSizes: mockPackSizes}, nil).AnyTimes()
+	mockStockRepo.EXPECT().GetStock().Return(mockStock, nil).AnyTimes()
+
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	// Without 250 packs the best mix overfills with a 500 pack instead.
+	result, err := service.CalculatePacks(2250, CalculateOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint32]uint64{1000: 2, 500: 1}, result.Packs)
+
+	// Only the unlimited 500 packs can make up for the missing 1000 packs.
+	result, err = service.CalculatePacks(3000, CalculateOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint32]uint64{1000: 2, 500: 2}, result.Packs)
+}
+
+func TestCalculatePacksWithCatalogVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+	mockCalculationRepo.EXPECT().SaveCalculation(gomock.Any()).Return(nil).AnyTimes()
+
+	historical := &models.CatalogVersion{ID: 3, PackSizes: []models.PackSize{{ID: 1, Size: 500}, {ID: 2, Size: 250}}}
+	mockRepo.EXPECT().GetCatalogVersion(uint32(3)).Return(historical, nil).Times(1)
+	mockRepo.EXPECT().GetCatalogVersion(uint32(9)).Return(nil, models.ErrCatalogVersionNotFound).Times(1)
+	// Pinned calculations reproduce past results, so the current stock is never read.
+	mockStockRepo.EXPECT().GetStock().Times(0)
+
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	result, err := service.CalculatePacks(750, CalculateOptions{CatalogVersion: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), result.CatalogVersion)
+	assert.Equal(t, map[uint32]uint64{500: 1, 250: 1}, result.Packs)
+
+	result, err = service.CalculatePacks(750, CalculateOptions{CatalogVersion: 9})
+	assert.ErrorIs(t, err, models.ErrCatalogVersionNotFound)
+	assert.Nil(t, result)
+}
+
+func TestCalculatePacksHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+
+	catalog := &models.CatalogVersion{ID: 5, PackSizes: []models.PackSize{{ID: 1, Size: 500, Price: 4}, {ID: 2, Size: 250, Price: 2.5}}}
+	mockRepo.EXPECT().GetCatalogVersion(uint32(0)).Return(catalog, nil).Times(2)
+	mockStockRepo.EXPECT().GetStock().Return(nil, nil).Times(2)
+
+	var saved *models.CalculationRecord
+	mockCalculationRepo.EXPECT().SaveCalculation(gomock.Any()).DoAndReturn(func(record *models.CalculationRecord) error {
+		saved = record
+		record.ID = 42
+		return nil
+	}).Times(1)
+	mockCalculationRepo.EXPECT().SaveCalculation(gomock.Any()).Return(errors.New("insert failed")).Times(1)
+
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	result, err := service.CalculatePacks(501, CalculateOptions{Strategy: StrategyOptimal, ShippingRate: 0.5, Caller: "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), result.ID)
+
+	saved.LatencyMicros = 0
+	assert.Equal(t, &models.CalculationRecord{
+		ID:             42,
+		Quantity:       501,
+		Strategy:       StrategyOptimal,
+		CatalogVersion: 5,
+		ShippingRate:   0.5,
+		Packs:          map[uint32]uint64{500: 1, 250: 1},
+		Items:          750,
+		Overfill:       249,
+		TotalCost:      6.5,
+		Caller:         "alice",
+	}, saved)
+
+	// A calculation that cannot be kept in the history is not returned.
+	result, err = service.CalculatePacks(501, CalculateOptions{})
+	assert.EqualError(t, err, "insert failed")
+	assert.Nil(t, result)
+}
+
+func TestCalculatePacksRepositoryErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+	mockCalculationRepo.EXPECT().SaveCalculation(gomock.Any()).Return(nil).AnyTimes()
+
+	mockRepo.EXPECT().GetCatalogVersion(uint32(0)).Return(&models.CatalogVersion{ID: 1, PackSizes: []models.PackSize{{ID: 1, Size: 250}}}, nil).Times(1)
+	mockStockRepo.EXPECT().GetStock().Return(nil, errors.New("stock error")).Times(1)
+
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	result, err := service.CalculatePacks(100, CalculateOptions{})
+	assert.EqualError(t, err, "stock error")
+	assert.Nil(t, result)
+}
+
+func TestUpdatePackSize(t *testing.T) {
+	size := uint32(750)
+	price := 5.5
+
+	testCases := []struct {
+		name        string
+		update      models.PackSizeUpdateRequest
+		existing    *models.PackSize
+		existingErr error
+		mockSetup   func(mockRepo *mocks.MockPackSizeRepository)
+		expected    *models.PackSize
+		expectError bool
+	}{
+		{
+			name:     "Change size in place",
+			update:   models.PackSizeUpdateRequest{Size: &size},
+			existing: &models.PackSize{ID: 2, Size: 500, Price: 4},
+			mockSetup: func(mockRepo *mocks.MockPackSizeRepository) {
+				mockRepo.EXPECT().UpdatePackSize(models.PackSize{ID: 2, Size: 750, Price: 4}, "alice").Return(nil).Times(1)
+			},
+			expected: &models.PackSize{ID: 2, Size: 750, Price: 4},
+		},
+		{
+			name:     "Change price only",
+			update:   models.PackSizeUpdateRequest{Price: &price},
+			existing: &models.PackSize{ID: 2, Size: 500, Price: 4},
+			mockSetup: func(mockRepo *mocks.MockPackSizeRepository) {
+				mockRepo.EXPECT().UpdatePackSize(models.PackSize{ID: 2, Size: 500, Price: 5.5}, "alice").Return(nil).Times(1)
+			},
+			expected: &models.PackSize{ID: 2, Size: 500, Price: 5.5},
+		},
+		{
+			name:        "Pack size not found",
+			update:      models.PackSizeUpdateRequest{Size: &size},
+			existingErr: models.ErrPackSizeNotFound,
+			mockSetup:   func(mockRepo *mocks.MockPackSizeRepository) {},
+			expectError: true,
+		},
+		{
+			name:     "Repository error",
+			update:   models.PackSizeUpdateRequest{Size: &size},
+			existing: &models.PackSize{ID: 2, Size: 500},
+			mockSetup: func(mockRepo *mocks.MockPackSizeRepository) {
+				mockRepo.EXPECT().UpdatePackSize(gomock.Any(), "alice").Return(errors.New("update failed")).Times(1)
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+			mockStockRepo := mocks.NewMockStockRepository(ctrl)
+			mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+
+			mockRepo.EXPECT().GetPackSize(uint32(2)).Return(tc.existing, tc.existingErr).Times(1)
+			tc.mockSetup(mockRepo)
+
+			service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+			pack, err := service.UpdatePackSize(2, tc.update, "alice")
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expected, pack)
+		})
+	}
+}
+
+func TestListPackSizes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+
+	mockRepo.EXPECT().GetPackSizes().Return(nil, nil).Times(1)
+
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	packSizes, err := service.ListPackSizes()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.PackSize{}, packSizes)
+}
+
+func TestPackSizeValidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	err := service.AddPackSize(models.PackSize{Size: 0}, "alice")
+	assert.ErrorIs(t, err, models.ErrInvalidSize)
+
+	zero := uint32(0)
+	pack, err := service.UpdatePackSize(1, models.PackSizeUpdateRequest{Size: &zero}, "alice")
+	assert.ErrorIs(t, err, models.ErrInvalidSize)
+	assert.Nil(t, pack)
+}
+
+func TestCalculatePacksNoPackSizes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+	mockCalculationRepo.EXPECT().SaveCalculation(gomock.Any()).Return(nil).AnyTimes()
+
+	mockRepo.EXPECT().GetCatalogVersion(uint32(0)).Return(&models.CatalogVersion{ID: 1}, nil).Times(1)
+
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	result, err := service.CalculatePacks(100, CalculateOptions{})
+	assert.ErrorIs(t, err, models.ErrNoPackSizes)
+	assert.Nil(t, result)
+}
+
+func TestReplacePackSizes(t *testing.T) {
+	testCases := []struct {
+		name        string
+		packs       []models.PackSize
+		mockSetup   func(mockRepo *mocks.MockPackSizeRepository)
+		expected    *models.CatalogChange
+		expectedErr error
+	}{
+		{
+			name:  "Replace catalog",
+			packs: []models.PackSize{{Size: 1000}, {Size: 300}},
+			mockSetup: func(mockRepo *mocks.MockPackSizeRepository) {
+				mockRepo.EXPECT().ReplacePackSizes([]models.PackSize{{Size: 1000}, {Size: 300}}, "alice").
+					Return(&models.CatalogChange{Added: []uint32{300}, Removed: []uint32{500}}, nil).Times(1)
+			},
+			expected: &models.CatalogChange{Added: []uint32{300}, Removed: []uint32{500}},
+		},
+		{
+			name:        "Empty catalog",
+			mockSetup:   func(mockRepo *mocks.MockPackSizeRepository) {},
+			expectedErr: models.ErrNoPackSizes,
+		},
+		{
+			name:        "Zero size",
+			packs:       []models.PackSize{{Size: 1000}, {Size: 0}},
+			mockSetup:   func(mockRepo *mocks.MockPackSizeRepository) {},
+			expectedErr: models.ErrInvalidSize,
+		},
+		{
+			name:        "Duplicate size",
+			packs:       []models.PackSize{{Size: 1000}, {Size: 1000, Price: 2}},
+			mockSetup:   func(mockRepo *mocks.MockPackSizeRepository) {},
+			expectedErr: models.ErrDuplicateSize,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+			mockStockRepo := mocks.NewMockStockRepository(ctrl)
+			mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+			tc.mockSetup(mockRepo)
+
+			service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+			change, err := service.ReplacePackSizes(tc.packs, "alice")
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.expected, change)
+		})
+	}
+}
+
+func TestCalculateBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+
+	// The catalog and stock are loaded once for the whole batch.
+	catalog := &models.CatalogVersion{ID: 5, PackSizes: []models.PackSize{{ID: 1, Size: 500, Price: 4}, {ID: 2, Size: 250, Price: 2.5}}}
+	mockRepo.EXPECT().GetCatalogVersion(uint32(0)).Return(catalog, nil).Times(1)
+	mockStockRepo.EXPECT().GetStock().Return([]models.PackStock{{Size: 500, Quantity: 2}, {Size: 250, Quantity: 1}}, nil).Times(1)
+
+	var saved []*models.CalculationRecord
+	mockCalculationRepo.EXPECT().SaveCalculations(gomock.Any()).DoAndReturn(func(records []*models.CalculationRecord) error {
+		saved = records
+		for i, record := range records {
+			record.ID = uint64(100 + i)
+		}
+		return nil
+	}).Times(1)
+
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	items := []BatchItem{{ID: "line-1", Quantity: 251}, {ID: "line-2", Quantity: 5000}, {Quantity: 1}}
+	batch, err := service.CalculateBatch(items, CalculateOptions{Caller: "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, StrategyOptimal, batch.Strategy)
+	assert.Equal(t, uint32(5), batch.CatalogVersion)
+	assert.Len(t, batch.Results, 3)
+
+	// Every item is calculated on its own against the full stock, in request order.
+	assert.Equal(t, items[0], batch.Results[0].Item)
+	assert.NoError(t, batch.Results[0].Err)
+	assert.Equal(t, map[uint32]uint64{500: 1}, batch.Results[0].Calculation.Packs)
+	assert.Equal(t, uint64(100), batch.Results[0].Calculation.ID)
+
+	assert.Equal(t, items[1], batch.Results[1].Item)
+	var stockErr *InsufficientStockError
+	assert.ErrorAs(t, batch.Results[1].Err, &stockErr)
+	assert.Nil(t, batch.Results[1].Calculation)
+
+	assert.Equal(t, items[2], batch.Results[2].Item)
+	assert.Equal(t, map[uint32]uint64{250: 1}, batch.Results[2].Calculation.Packs)
+	assert.Equal(t, uint64(101), batch.Results[2].Calculation.ID)
+
+	// Only the calculated items are kept in the history.
+	assert.Len(t, saved, 2)
+	assert.Equal(t, uint64(251), saved[0].Quantity)
+	assert.Equal(t, "alice", saved[0].Caller)
+	assert.Equal(t, uint64(1), saved[1].Quantity)
+}
+
+func TestCalculateBatchErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	batch, err := service.CalculateBatch([]BatchItem{{Quantity: 1}}, CalculateOptions{Strategy: "cheapest"})
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+	assert.Nil(t, batch)
+
+	mockRepo.EXPECT().GetCatalogVersion(uint32(3)).Return(&models.CatalogVersion{ID: 3, PackSizes: []models.PackSize{{Size: 250}}}, nil).Times(1)
+	mockCalculationRepo.EXPECT().SaveCalculations(gomock.Any()).Return(errors.New("insert failed")).Times(1)
+
+	batch, err = service.CalculateBatch([]BatchItem{{Quantity: 1}}, CalculateOptions{CatalogVersion: 3})
+	assert.EqualError(t, err, "insert failed")
+	assert.Nil(t, batch)
+}
+
+func TestCalculateStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+
+	catalog := &models.CatalogVersion{ID: 5, PackSizes: []models.PackSize{{ID: 1, Size: 500}, {ID: 2, Size: 250}}}
+	mockRepo.EXPECT().GetCatalogVersion(uint32(0)).Return(catalog, nil).Times(1)
+	mockStockRepo.EXPECT().GetStock().Return(nil, nil).Times(1)
+
+	// Every calculated line is recorded on its own.
+	nextID := uint64(1)
+	mockCalculationRepo.EXPECT().SaveCalculation(gomock.Any()).DoAndReturn(func(record *models.CalculationRecord) error {
+		record.ID = nextID
+		nextID++
+		return nil
+	}).Times(101)
+
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	// A line's result is emitted before the next line is read.
+	var results []BatchResult
+	lineErr := errors.New("unreadable line")
+	items := func(yield func(BatchItem, error) bool) {
+		for line := 1; line <= 102; line++ {
+			assert.Len(t, results, line-1)
+
+			var err error
+			if line == 2 {
+				err = lineErr
+			}
+			if !yield(BatchItem{Line: line, Quantity: uint64(line)}, err) {
+				return
+			}
+		}
+	}
+
+	err := service.CalculateStream(items, CalculateOptions{}, func(result BatchResult) error {
+		results = append(results, result)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 102)
+
+	// Results keep the order of the items, and unreadable items report their error inline.
+	assert.Equal(t, 1, results[0].Item.Line)
+	assert.Equal(t, uint64(1), results[0].Calculation.ID)
+	assert.Equal(t, map[uint32]uint64{250: 1}, results[0].Calculation.Packs)
+	assert.ErrorIs(t, results[1].Err, lineErr)
+	assert.Nil(t, results[1].Calculation)
+	assert.Equal(t, uint64(2), results[2].Calculation.ID)
+	assert.Equal(t, 102, results[101].Item.Line)
+	assert.Equal(t, uint64(101), results[101].Calculation.ID)
+}
+
+func TestCalculateStreamErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	items := func(yield func(BatchItem, error) bool) {
+		yield(BatchItem{Line: 1, Quantity: 1}, nil)
+	}
+	emit := func(BatchResult) error { return errors.New("connection closed") }
+
+	err := service.CalculateStream(items, CalculateOptions{Strategy: "cheapest"}, emit)
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+
+	mockRepo.EXPECT().GetCatalogVersion(uint32(3)).Return(&models.CatalogVersion{ID: 3, PackSizes: []models.PackSize{{Size: 250}}}, nil).Times(1)
+	mockCalculationRepo.EXPECT().SaveCalculation(gomock.Any()).Return(nil).Times(1)
+
+	err = service.CalculateStream(items, CalculateOptions{CatalogVersion: 3}, emit)
+	assert.EqualError(t, err, "connection closed")
+
+	// A line that cannot be recorded ends the stream before its result is emitted.
+	mockRepo.EXPECT().GetCatalogVersion(uint32(3)).Return(&models.CatalogVersion{ID: 3, PackSizes: []models.PackSize{{Size: 250}}}, nil).Times(1)
+	mockCalculationRepo.EXPECT().SaveCalculation(gomock.Any()).Return(errors.New("insert failed")).Times(1)
+
+	err = service.CalculateStream(items, CalculateOptions{CatalogVersion: 3}, emit)
+	assert.EqualError(t, err, "insert failed")
+}
+
+func TestCalculatePacksWhatIf(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+
+	// What-if calculations read neither the catalog nor the stock, and are not kept in the history.
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+	packs := []models.PackSize{{Size: 23}, {Size: 31}, {Size: 53}}
+	calculation, err := service.CalculatePacks(263, CalculateOptions{PackSizes: packs, CatalogVersion: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), calculation.ID)
+	assert.Equal(t, uint32(0), calculation.CatalogVersion)
+	assert.Equal(t, uint32(263), uint32(packedItems(calculation.Packs)))
+
+	_, err = service.CalculatePacks(1, CalculateOptions{PackSizes: []models.PackSize{}})
+	assert.ErrorIs(t, err, models.ErrNoPackSizes)
+	_, err = service.CalculatePacks(1, CalculateOptions{PackSizes: []models.PackSize{{Size: 0}}})
+	assert.ErrorIs(t, err, models.ErrInvalidSize)
+	_, err = service.CalculatePacks(1, CalculateOptions{PackSizes: []models.PackSize{{Size: 5}, {Size: 5}}})
+	assert.ErrorIs(t, err, models.ErrDuplicateSize)
+}
+
+func TestCompareCalculation(t *testing.T) {
+	tests := []struct {
+		name          string
+		stock         []models.PackStock
+		catalogError  error
+		expectedLive  map[uint32]uint64
+		expectedError error
+		liveError     bool
+	}{
+		{
+			name:         "Live catalog calculates the order",
+			expectedLive: map[uint32]uint64{500: 1},
+		},
+		{
+			name:      "Live stock cannot cover the order",
+			stock:     []models.PackStock{{Size: 500, Quantity: 0}, {Size: 250, Quantity: 0}},
+			liveError: true,
+		},
+		{
+			name:          "Live catalog unavailable",
+			catalogError:  errors.New("connection refused"),
+			expectedError: errors.New("connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+			mockStockRepo := mocks.NewMockStockRepository(ctrl)
+			mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+
+			catalog := &models.CatalogVersion{ID: 5, PackSizes: []models.PackSize{{Size: 500}, {Size: 250}}}
+			mockRepo.EXPECT().GetCatalogVersion(uint32(0)).Return(catalog, tt.catalogError).Times(1)
+			if tt.catalogError == nil {
+				mockStockRepo.EXPECT().GetStock().Return(tt.stock, nil).Times(1)
+			}
+
+			service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+
+			opts := CalculateOptions{PackSizes: []models.PackSize{{Size: 23}, {Size: 31}, {Size: 53}}, CatalogVersion: 3}
+			comparison, err := service.CompareCalculation(300, opts)
+			if tt.expectedError != nil {
+				assert.EqualError(t, err, tt.expectedError.Error())
+				assert.Nil(t, comparison)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(300), packedItems(comparison.WhatIf.Packs))
+			if tt.liveError {
+				var stockErr *InsufficientStockError
+				assert.ErrorAs(t, comparison.LiveErr, &stockErr)
+				assert.Nil(t, comparison.Live)
+				return
+			}
+			assert.NoError(t, comparison.LiveErr)
+			assert.Equal(t, uint32(5), comparison.Live.CatalogVersion)
+			assert.Equal(t, tt.expectedLive, comparison.Live.Packs)
 		})
 	}
 }
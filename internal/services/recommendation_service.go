@@ -0,0 +1,71 @@
+package services
+
+import (
+	"github.com/klemis/packs-calculator/internal/repositories"
+	"github.com/klemis/packs-calculator/models"
+)
+
+// Sources of the order quantities a recommendation is made for.
+const (
+	RecommendationSourceUpload  = "upload"
+	RecommendationSourceHistory = "history"
+)
+
+// PackRecommender defines the interface for recommending pack catalogs from order quantities.
+type PackRecommender interface {
+	RecommendPackSizes(opts RecommendationOptions) (*models.PackRecommendation, error)
+}
+
+// RecommendationOptions describes the orders a recommendation is made for and the catalogs it may
+// recommend.
+type RecommendationOptions struct {
+	// Quantities is the distribution of order quantities; nil draws it from the calculation history
+	// matching History.
+	Quantities []models.QuantityCount
+	History    models.CalculationFilter
+	// Sizes is the number of pack sizes in a recommended catalog, up to MaxRecommendedSizes.
+	Sizes int
+	// MinSize and MaxSize bound the pack sizes; a zero MaxSize allows sizes up to the largest order.
+	MinSize uint32
+	MaxSize uint32
+	// MultipleOf is the step every pack size is a multiple of; 0 allows any size.
+	MultipleOf uint32
+	// PackWeight is how many items of overfill one more expected pack is worth in the score.
+	PackWeight float64
+	// Limit is the number of catalogs recommended, up to MaxRecommendations; 0 selects
+	// DefaultRecommendations.
+	Limit int
+}
+
+// RecommendationService is an implementation of PackRecommender.
+type RecommendationService struct {
+	repo repositories.CalculationRepository
+}
+
+// NewRecommendationService creates a new instance of PackRecommender with an injected repository.
+func NewRecommendationService(calculationRepo repositories.CalculationRepository) PackRecommender {
+	return &RecommendationService{
+		repo: calculationRepo,
+	}
+}
+
+// RecommendPackSizes ranks the catalogs that would have packed the orders best, covering every order
+// with the fewest items and then the fewest packs.
+func (s *RecommendationService) RecommendPackSizes(opts RecommendationOptions) (*models.PackRecommendation, error) {
+	source, quantities := RecommendationSourceUpload, opts.Quantities
+	if quantities == nil {
+		var err error
+		source = RecommendationSourceHistory
+		if quantities, err = s.repo.GetQuantityDistribution(opts.History); err != nil {
+			return nil, err
+		}
+	}
+
+	recommendation, err := recommendPackSizes(quantities, opts)
+	if err != nil {
+		return nil, err
+	}
+	recommendation.Source = source
+
+	return recommendation, nil
+}
@@ -0,0 +1,344 @@
+package services
+
+import (
+	"errors"
+	"math"
+)
+
+const (
+	// maxTableTotal is the largest total a calculation tabulates every total up to, for orders the
+	// residues cannot solve.
+	maxTableTotal = 1 << 22
+	// maxResidueModulus caps the pack size residues are taken modulo, which sizes their tables.
+	maxResidueModulus = 1 << 20
+)
+
+// ErrQuantityTooLarge is returned when an order is too large to calculate with the pack sizes: every
+// total covering it exceeds math.MaxUint64, or it is beyond what the solvers can take on.
+var ErrQuantityTooLarge = errors.New("order quantity too large for the pack sizes")
+
+// packSolver answers, for the totals of one order, whether the pack sizes add up to a total exactly
+// and with which cheapest combination, breaking cost ties by the fewest packs.
+type packSolver interface {
+	// limit returns the largest total worth looking at.
+	limit() uint64
+	reachable(total uint64) bool
+	packCount(total uint64) uint64
+	totalCost(total uint64) float64
+	combination(total uint64) map[uint32]uint64
+}
+
+// solver prepares to solve the totals the mode allows. The residues solve any order they can at a cost
+// that depends on the pack sizes alone, so they are tried first once the totals reach past the largest
+// size, where a table would already cost as much. Other orders up to maxTableTotal are solved by a table
+// over every total; larger ones fail with ErrQuantityTooLarge rather than tabulate more totals than the
+// server can hold.
+func (p PackingProblem) solver(sizes []uint32, unitCosts []float64) (packSolver, error) {
+	_, high := p.Mode.bounds(p.Quantity, sizes[0])
+	if high >= uint64(sizes[0]) {
+		if solver, ok := newResidueSolver(p, sizes, unitCosts, high); ok {
+			return solver, nil
+		}
+	}
+	if high > maxTableTotal {
+		return nil, ErrQuantityTooLarge
+	}
+
+	return buildPackTable(sizes, unitCosts, p.limits(sizes), high), nil
+}
+
+// residueSolver solves orders far larger than the pack sizes. Every combination is a base of packs of
+// the other sizes topped up with packs of one fold size, and the best base of every remainder modulo the
+// fold size is found one size at a time, as a table over totals adds them. A total takes the best base
+// of its remainder and fills the rest with packs of the fold size.
+//
+// The fold size has the lowest cost per item, the largest size breaking ties. Trading base packs that
+// add up to a multiple of it for packs of it then never costs more or uses more packs, so the best
+// bases hold fewer packs than the fold size, and bases are only compared by what they cost and use
+// beyond what the same items in packs of the fold size would.
+type residueSolver struct {
+	fold     uint64
+	foldCost float64
+	// base[r] is the total of the best base with remainder r, or math.MaxUint64 when there is none;
+	// baseCost[r] and basePacks[r] are its cost and number of packs.
+	base      []uint64
+	baseCost  []float64
+	basePacks []uint64
+	// sizes are the other pack sizes in the order they were added, and take[i][r] the number of packs of
+	// sizes[i] in the best base of r using sizes[:i+1].
+	sizes []uint32
+	take  [][]uint32
+	// high is the largest total worth looking at: beyond a full round of remainders, a total only adds
+	// a pack of the fold size to one below it.
+	high uint64
+
+	// excessCost[r] and excessPacks[r] weigh the best base of r beyond the fold size while it is built.
+	excessCost  []float64
+	excessPacks []int64
+}
+
+// newResidueSolver builds the best base of every remainder for sizes, sorted descending, priced by
+// unitCosts or free when nil. Sizes whose stock runs out before the totals looked at are bounded by it,
+// and the fold size is the cheapest of the others. It reports false when the residues cannot solve the
+// order: when stock caps every size, the fold size is above maxResidueModulus, stock caps sizes of an
+// order beyond math.MaxInt64, or a best base exceeds the totals looked at.
+func newResidueSolver(p PackingProblem, sizes []uint32, unitCosts []float64, high uint64) (*residueSolver, bool) {
+	costOf := func(i int) float64 {
+		if unitCosts == nil {
+			return 0
+		}
+		return unitCosts[i]
+	}
+	limits := p.limits(sizes)
+	bounded := func(i int) bool {
+		return limits != nil && uint64(limits[i]) < high/uint64(sizes[i])
+	}
+
+	fold := -1
+	for i := range sizes {
+		if bounded(i) {
+			// The excess of a bounded size is kept in an int64.
+			if high > math.MaxInt64 {
+				return nil, false
+			}
+			continue
+		}
+		if fold < 0 || costOf(i)*float64(sizes[fold]) < costOf(fold)*float64(sizes[i]) {
+			fold = i
+		}
+	}
+	if fold < 0 || sizes[fold] > maxResidueModulus {
+		return nil, false
+	}
+
+	modulus := uint64(sizes[fold])
+	s := &residueSolver{
+		fold:        modulus,
+		foldCost:    costOf(fold),
+		base:        make([]uint64, modulus),
+		baseCost:    make([]float64, modulus),
+		basePacks:   make([]uint64, modulus),
+		high:        min(high, addCapped(p.Quantity, modulus-1)),
+		excessCost:  make([]float64, modulus),
+		excessPacks: make([]int64, modulus),
+	}
+	for r := range s.base {
+		s.base[r] = math.MaxUint64
+	}
+	s.base[0] = 0
+
+	// The sizes are added largest first, as the table adds them, so that ties between bases break the
+	// same way: a base keeps the packs of the sizes added before one that is no better.
+	for i, size := range sizes {
+		switch {
+		case i == fold:
+		case bounded(i):
+			s.addBounded(size, costOf(i), limits[i])
+		default:
+			s.addUnbounded(size, costOf(i))
+		}
+	}
+	s.excessCost, s.excessPacks = nil, nil
+
+	// A total below a best base might be reached by a worse base that was not kept. Underfilling looks
+	// up to a fold size below the quantity.
+	maxBase := uint64(0)
+	for _, base := range s.base {
+		if base != math.MaxUint64 {
+			maxBase = max(maxBase, base)
+		}
+	}
+	if p.Quantity < addCapped(maxBase, modulus) {
+		return nil, false
+	}
+
+	return s, true
+}
+
+// addUnbounded adds a pack size without a cap on its number of packs to the bases. The remainders fall
+// into cycles of the size, and each is walked round once from its best base, which nothing in the
+// cycle can improve on, as every pack of the size costs more than its items in packs of the fold size.
+func (s *residueSolver) addUnbounded(size uint32, unitCost float64) {
+	take := make([]uint32, s.fold)
+	s.sizes, s.take = append(s.sizes, size), append(s.take, take)
+
+	step := uint64(size) % s.fold
+	if step == 0 {
+		// A multiple of the fold size never makes a better base.
+		return
+	}
+	stepCost, stepPacks := s.excessOf(size, unitCost)
+
+	cycles := gcd(s.fold, step)
+	for start := uint64(0); start < cycles; start++ {
+		from := uint64(math.MaxUint64)
+		for r := start; r < s.fold; r += cycles {
+			if s.base[r] != math.MaxUint64 && (from == math.MaxUint64 || cheaper(s.excessCost[r], s.excessPacks[r], s.excessCost[from], s.excessPacks[from])) {
+				from = r
+			}
+		}
+		if from == math.MaxUint64 {
+			continue
+		}
+
+		for i := uint64(1); i < s.fold/cycles; i++ {
+			r := (from + step) % s.fold
+			cost, packs := s.excessCost[from]+stepCost, s.excessPacks[from]+stepPacks
+			if s.base[r] == math.MaxUint64 || cheaper(cost, packs, s.excessCost[r], s.excessPacks[r]) {
+				s.base[r] = s.base[from] + uint64(size)
+				s.baseCost[r] = s.baseCost[from] + unitCost
+				s.basePacks[r] = s.basePacks[from] + 1
+				s.excessCost[r], s.excessPacks[r] = cost, packs
+				take[r] = take[from] + 1
+			}
+			from = r
+		}
+	}
+}
+
+// addBounded adds a pack size with at most maxPacks packs in stock to the bases. Within a cycle of the
+// size, the base of a remainder takes k packs onto the base k steps back, so a monotone queue over each
+// cycle keeps the best base within reach, as the table does over totals. Going round a cycle returns to
+// the same remainder, so only a round of counts is worth trying: the fewest when a pack costs more than
+// its items in packs of the fold size, and the most otherwise.
+func (s *residueSolver) addBounded(size uint32, unitCost float64, maxPacks uint32) {
+	take := make([]uint32, s.fold)
+	s.sizes, s.take = append(s.sizes, size), append(s.take, take)
+
+	step := uint64(size) % s.fold
+	stepCost, stepPacks := s.excessOf(size, unitCost)
+	cycles := gcd(s.fold, step)
+	length := s.fold / cycles
+	window := min(uint64(maxPacks), length-1)
+
+	if cheaper(stepCost, stepPacks, 0, 0) {
+		// Every base takes the packs below the round of counts tried up front.
+		shift := uint64(maxPacks) - window
+		base, baseCost, basePacks := s.base, s.baseCost, s.basePacks
+		excessCost, excessPacks := s.excessCost, s.excessPacks
+		s.base = make([]uint64, s.fold)
+		s.baseCost = make([]float64, s.fold)
+		s.basePacks = make([]uint64, s.fold)
+		s.excessCost = make([]float64, s.fold)
+		s.excessPacks = make([]int64, s.fold)
+		for r := range s.base {
+			s.base[r] = math.MaxUint64
+		}
+		for r, b := range base {
+			if b == math.MaxUint64 {
+				continue
+			}
+			next := (uint64(r) + shift*uint64(size)) % s.fold
+			s.base[next] = b + shift*uint64(size)
+			s.baseCost[next] = baseCost[r] + float64(shift)*unitCost
+			s.basePacks[next] = basePacks[r] + shift
+			s.excessCost[next] = excessCost[r] + float64(shift)*stepCost
+			s.excessPacks[next] = excessPacks[r] + int64(shift)*stepPacks
+			take[next] = uint32(shift)
+		}
+	}
+
+	// candidate is the base of the u-th remainder of a cycle, with the excess of u packs of the size
+	// taken off so candidates at different steps compare directly.
+	type candidate struct {
+		u           uint64
+		excessCost  float64
+		excessPacks int64
+		base        uint64
+		cost        float64
+		packs       uint64
+		take        uint32
+	}
+	queue := make([]candidate, 0)
+
+	for start := uint64(0); start < cycles; start++ {
+		queue = queue[:0]
+		head := 0
+
+		// The first window of the cycle is read again before any remainder is written, so every
+		// remainder is written once, from the bases before the size was added.
+		for u := uint64(0); u < length+window; u++ {
+			r := (start + u*step) % s.fold
+			if s.base[r] != math.MaxUint64 {
+				next := candidate{
+					u:           u,
+					excessCost:  s.excessCost[r] - float64(u)*stepCost,
+					excessPacks: s.excessPacks[r] - int64(u)*stepPacks,
+					base:        s.base[r],
+					cost:        s.baseCost[r],
+					packs:       s.basePacks[r],
+					take:        take[r],
+				}
+				// Newer candidates stay usable for longer, so they evict any that are no better.
+				for len(queue) > head && !cheaper(queue[len(queue)-1].excessCost, queue[len(queue)-1].excessPacks, next.excessCost, next.excessPacks) {
+					queue = queue[:len(queue)-1]
+				}
+				queue = append(queue, next)
+			}
+			for head < len(queue) && queue[head].u+window < u {
+				head++
+			}
+			if u < window {
+				continue
+			}
+
+			if head == len(queue) {
+				s.base[r] = math.MaxUint64
+				continue
+			}
+			best, n := queue[head], u-queue[head].u
+			s.base[r] = best.base + n*uint64(size)
+			s.baseCost[r] = best.cost + float64(n)*unitCost
+			s.basePacks[r] = best.packs + n
+			s.excessCost[r] = best.excessCost + float64(u)*stepCost
+			s.excessPacks[r] = best.excessPacks + int64(u)*stepPacks
+			take[r] = best.take + uint32(n)
+		}
+	}
+}
+
+// excessOf returns what a pack of size costs and uses beyond its items in packs of the fold size, the
+// packs scaled by the fold size to stay whole.
+func (s *residueSolver) excessOf(size uint32, unitCost float64) (float64, int64) {
+	return unitCost - float64(size)*s.foldCost/float64(s.fold), int64(s.fold) - int64(size)
+}
+
+// limit returns the largest total worth looking at.
+func (s *residueSolver) limit() uint64 {
+	return s.high
+}
+
+// reachable reports whether total can be composed exactly from the pack sizes.
+func (s *residueSolver) reachable(total uint64) bool {
+	base := s.base[total%s.fold]
+	return base != math.MaxUint64 && base <= total
+}
+
+// packCount returns the number of packs in the best combination for a reachable total.
+func (s *residueSolver) packCount(total uint64) uint64 {
+	r := total % s.fold
+	return s.basePacks[r] + (total-s.base[r])/s.fold
+}
+
+// totalCost returns the cost of the best combination for a reachable total.
+func (s *residueSolver) totalCost(total uint64) float64 {
+	r := total % s.fold
+	return s.baseCost[r] + float64((total-s.base[r])/s.fold)*s.foldCost
+}
+
+// combination rebuilds the pack counts of the best combination for a reachable total.
+func (s *residueSolver) combination(total uint64) map[uint32]uint64 {
+	result := make(map[uint32]uint64)
+	r := total % s.fold
+	if n := (total - s.base[r]) / s.fold; n > 0 {
+		result[uint32(s.fold)] = n
+	}
+	for i := len(s.sizes) - 1; i >= 0; i-- {
+		if n := uint64(s.take[i][r]); n > 0 {
+			result[s.sizes[i]] = n
+			r = (r + s.fold - n*uint64(s.sizes[i])%s.fold) % s.fold
+		}
+	}
+
+	return result
+}
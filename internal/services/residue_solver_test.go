@@ -0,0 +1,250 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResidueSolverMatchesTable(t *testing.T) {
+	for _, sizes := range oraclePackSets {
+		sorted := sortedSizes(toPackSizes(sizes))
+		stocks := []map[uint32]uint32{nil}
+		if len(sorted) > 1 {
+			// Stock on the largest size moves the fold off it.
+			stocks = append(stocks, map[uint32]uint32{sorted[len(sorted)-1]: 3}, map[uint32]uint32{sorted[0]: 2})
+		}
+
+		for _, stock := range stocks {
+			for _, priced := range []bool{false, true} {
+				assertResidueSolverMatchesTable(t, sorted, stock, priced)
+			}
+		}
+	}
+}
+
+// assertResidueSolverMatchesTable checks the residues against a table over every total, bounded by the
+// same stock, for 200 quantities from the smallest the residues solve.
+func assertResidueSolverMatchesTable(t *testing.T, sorted []uint32, stock map[uint32]uint32, priced bool) {
+	t.Helper()
+
+	var costs []float64
+	if priced {
+		// Prices that are not proportional to size move the fold off the largest size.
+		costs = make([]float64, len(sorted))
+		for i, size := range sorted {
+			costs[i] = float64(size%7 + 1)
+		}
+	}
+
+	// Start from the smallest quantity the residues can solve, past where the stock runs out.
+	problem := PackingProblem{Stock: stock}
+	if stock != nil {
+		problem.Quantity = 10_000
+	}
+	for ; ; problem.Quantity++ {
+		_, high := problem.Mode.bounds(problem.Quantity, sorted[0])
+		if _, ok := newResidueSolver(problem, sorted, costs, high); ok {
+			break
+		}
+	}
+
+	for end := problem.Quantity + 200; problem.Quantity < end; problem.Quantity++ {
+		msg := fmt.Sprintf("sizes %v, stock %v, priced %v, quantity %d", sorted, stock, priced, problem.Quantity)
+		_, high := problem.Mode.bounds(problem.Quantity, sorted[0])
+		solver, ok := newResidueSolver(problem, sorted, costs, high)
+		if !assert.True(t, ok, msg) {
+			return
+		}
+		table := buildPackTable(sorted, costs, problem.limits(sorted), high)
+
+		assert.LessOrEqual(t, solver.limit(), high, msg)
+		// Underfilling looks at most a fold size below the quantity.
+		for total := problem.Quantity + 1 - solver.fold; total <= solver.limit(); total++ {
+			assert.Equal(t, table.reachable(total), solver.reachable(total), "%s: total %d", msg, total)
+			if !table.reachable(total) {
+				continue
+			}
+			assert.Equal(t, table.packCount(total), solver.packCount(total), "%s: total %d", msg, total)
+			assert.InDelta(t, table.totalCost(total), solver.totalCost(total), costEpsilon, "%s: total %d", msg, total)
+
+			combination := solver.combination(total)
+			assert.Equal(t, total, packedItems(combination), "%s: total %d", msg, total)
+			assert.Equal(t, solver.packCount(total), PackCount(combination), "%s: total %d", msg, total)
+			for size, n := range stock {
+				assert.LessOrEqual(t, combination[size], uint64(n), "%s: total %d", msg, total)
+			}
+			if !priced && solver.fold == uint64(sorted[0]) {
+				// Both add the sizes largest first, so they break ties between combinations alike.
+				assert.Equal(t, table.combination(total), combination, "%s: total %d", msg, total)
+			}
+		}
+	}
+}
+
+func TestResidueSolverNotApplicable(t *testing.T) {
+	sizes := []uint32{500, 250}
+
+	_, ok := newResidueSolver(PackingProblem{Quantity: 1 << 30, Stock: map[uint32]uint32{500: 4, 250: 4}}, sizes, nil, 1<<30+499)
+	assert.False(t, ok, "stock caps every size")
+
+	_, ok = newResidueSolver(PackingProblem{Quantity: math.MaxInt64, Stock: map[uint32]uint32{250: 4}}, sizes, nil, math.MaxUint64)
+	assert.False(t, ok, "stock caps a size beyond math.MaxInt64")
+
+	_, ok = newResidueSolver(PackingProblem{Quantity: 1 << 40}, []uint32{maxResidueModulus + 1, 3}, nil, math.MaxUint64)
+	assert.False(t, ok, "fold size above the modulus cap")
+
+	_, ok = newResidueSolver(PackingProblem{Quantity: 600}, sizes, nil, math.MaxUint64)
+	assert.False(t, ok, "quantity within a fold size of the bases")
+}
+
+func TestStrategiesLargeQuantities(t *testing.T) {
+	packSizes := []models.PackSize{
+		{ID: 1, Size: 250, Price: 1},
+		{ID: 2, Size: 500, Price: 1.5},
+		{ID: 3, Size: 1000, Price: 2.5},
+		{ID: 4, Size: 2000, Price: 4.5},
+		{ID: 5, Size: 5000, Price: 12},
+	}
+
+	testCases := []struct {
+		name     string
+		strategy PackingStrategy
+		quantity uint64
+		mode     FillMode
+		expected map[uint32]uint64
+	}{
+		{
+			name:     "Optimal over a trillion items",
+			strategy: OptimalStrategy{},
+			quantity: 1_000_000_000_001,
+			expected: map[uint32]uint64{5000: 200_000_000, 250: 1},
+		},
+		{
+			name:     "Optimal at the top of the range",
+			strategy: OptimalStrategy{},
+			quantity: math.MaxUint64 - 4615,
+			expected: map[uint32]uint64{5000: 3_689_348_814_741_909, 2000: 1},
+		},
+		{
+			name:     "Fewest packs over a trillion items",
+			strategy: FewestPacksStrategy{},
+			quantity: 1_000_000_000_001,
+			expected: map[uint32]uint64{5000: 200_000_000, 250: 1},
+		},
+		{
+			name:     "Min cost folds into the cheapest size per item",
+			strategy: MinCostStrategy{},
+			quantity: 1_000_000_000_001,
+			expected: map[uint32]uint64{2000: 500_000_000, 250: 1},
+		},
+		{
+			name:     "Underfill over a trillion items",
+			strategy: OptimalStrategy{},
+			quantity: 1_000_000_000_249,
+			mode:     FillMode{Name: FillUnderfill},
+			expected: map[uint32]uint64{5000: 200_000_000},
+		},
+		{
+			name:     "Greedy over a trillion items",
+			strategy: GreedyStrategy{},
+			quantity: 1_000_000_000_001,
+			expected: map[uint32]uint64{5000: 200_000_000, 250: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.strategy.Pack(PackingProblem{PackSizes: packSizes, Quantity: tc.quantity, Mode: tc.mode})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestStrategiesLargeQuantitiesWithStock(t *testing.T) {
+	packSizes := []models.PackSize{
+		{ID: 1, Size: 250, Price: 1},
+		{ID: 2, Size: 500, Price: 1.5},
+		{ID: 3, Size: 1000, Price: 2.5},
+		{ID: 4, Size: 2000, Price: 4.5},
+		{ID: 5, Size: 5000, Price: 12},
+	}
+
+	testCases := []struct {
+		name     string
+		strategy PackingStrategy
+		quantity uint64
+		stock    map[uint32]uint32
+		expected map[uint32]uint64
+	}{
+		{
+			name:     "Stock on the smallest size",
+			strategy: OptimalStrategy{},
+			quantity: 5_000_001,
+			stock:    map[uint32]uint32{250: 10},
+			expected: map[uint32]uint64{5000: 1000, 250: 1},
+		},
+		{
+			name:     "Stock on the largest size",
+			strategy: OptimalStrategy{},
+			quantity: 1_000_000_001,
+			stock:    map[uint32]uint32{5000: 10},
+			expected: map[uint32]uint64{5000: 10, 2000: 499_975, 250: 1},
+		},
+		{
+			name:     "Fewest packs without the smallest size",
+			strategy: FewestPacksStrategy{},
+			quantity: 1_000_000_001,
+			stock:    map[uint32]uint32{250: 0},
+			expected: map[uint32]uint64{5000: 200_000, 500: 1},
+		},
+		{
+			name:     "Min cost uses up the cheapest size per item",
+			strategy: MinCostStrategy{},
+			quantity: 1_000_000_000,
+			stock:    map[uint32]uint32{2000: 100},
+			expected: map[uint32]uint64{5000: 199_960, 2000: 100},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.strategy.Pack(PackingProblem{PackSizes: packSizes, Quantity: tc.quantity, Stock: tc.stock})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestStrategiesQuantityTooLarge(t *testing.T) {
+	packSizes := toPackSizes([]uint32{250, 500, 1000, 2000, 5000})
+
+	for _, strategy := range []PackingStrategy{GreedyStrategy{}, OptimalStrategy{}, FewestPacksStrategy{}, MinCostStrategy{}} {
+		t.Run(strategy.Name(), func(t *testing.T) {
+			// No multiple of 250 is at or above math.MaxUint64 - 100 without overflowing.
+			_, err := strategy.Pack(PackingProblem{PackSizes: packSizes, Quantity: math.MaxUint64 - 100})
+			assert.ErrorIs(t, err, ErrQuantityTooLarge)
+
+			// Exact fills still fail as no fit.
+			_, err = strategy.Pack(PackingProblem{PackSizes: packSizes, Quantity: math.MaxUint64, Mode: FillMode{Name: FillExact}})
+			assert.ErrorIs(t, err, ErrNoExactFit)
+		})
+	}
+
+	// Stock capping every size keeps the residues out of reach, and the table stops well short of the
+	// quantity.
+	stock := map[uint32]uint32{250: 1_000_000, 500: 1_000_000, 1000: 1_000_000, 2000: 1_000_000, 5000: 100_000}
+	for _, strategy := range []PackingStrategy{OptimalStrategy{}, FewestPacksStrategy{}, MinCostStrategy{}} {
+		_, err := strategy.Pack(PackingProblem{PackSizes: packSizes, Quantity: 4_000_000_000, Stock: stock})
+		assert.ErrorIs(t, err, ErrQuantityTooLarge, strategy.Name())
+	}
+
+	// A single pack size of one reaches every total.
+	result, err := OptimalStrategy{}.Pack(PackingProblem{PackSizes: toPackSizes([]uint32{1}), Quantity: math.MaxUint64})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint32]uint64{1: math.MaxUint64}, result)
+}
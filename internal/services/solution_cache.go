@@ -0,0 +1,332 @@
+package services
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/klemis/packs-calculator/models"
+)
+
+const (
+	// SolutionCacheLimit is the largest order quantity the solution cache answers; larger orders are
+	// solved on demand.
+	SolutionCacheLimit = 100_000
+	// SolutionCacheMaxSizes is the most pack sizes the solution cache holds a table for. Every size adds
+	// a layer of about 1.6MB, so larger catalogs are solved on demand.
+	SolutionCacheMaxSizes = 16
+)
+
+// solutionCache holds the optimal combination of every order quantity up to its limit for the pack
+// sizes of the live catalog, so that the most common calculations are looked up instead of solved.
+//
+// The table is built one pack size at a time, largest first, keeping the table after every size as a
+// layer. Adding or removing a size only rebuilds the layers of the sizes after it, and the table
+// always ends up as OptimalStrategy would build it, so cached and solved calculations agree exactly.
+//
+// Tables are never changed once published: updates build a new table beside the current one, sharing
+// its untouched layers, and swap it in, so lookups never wait for them.
+type solutionCache struct {
+	limit    uint64
+	maxSizes int
+
+	// mu serialises the updates of the table.
+	mu    sync.Mutex
+	table atomic.Pointer[solutionTable]
+	// rebuilding is set while a lookup's rebuild runs in the background, and pending tracks it.
+	rebuilding atomic.Bool
+	pending    sync.WaitGroup
+
+	hits, misses, builds, updates atomic.Uint64
+}
+
+// solutionTable is one published table of the solution cache.
+type solutionTable struct {
+	// sizes are the pack sizes of the table, largest first.
+	sizes []uint32
+	// top is the largest total tabulated, fixed when the table is built from scratch.
+	top uint64
+	// packs[i][t] is the fewest packs of sizes[:i+1] adding up to exactly t, or unreachable.
+	packs [][]uint32
+	// take[i][t] is the number of packs of sizes[i] in that combination.
+	take [][]uint32
+	// cover[q] is the smallest reachable total from q on, or unreachable when it is beyond top.
+	cover []uint32
+}
+
+// newSolutionCache returns an empty cache for order quantities up to limit and at most maxSizes pack
+// sizes.
+func newSolutionCache(limit uint64, maxSizes int) *solutionCache {
+	return &solutionCache{limit: limit, maxSizes: maxSizes}
+}
+
+// solve looks up the combination OptimalStrategy would pack for a problem. It reports false when the
+// problem is one the table does not answer: another strategy or fill mode, stock on any of the sizes,
+// more sizes than the cache holds, or a quantity beyond the limit. A table for other pack sizes is
+// rebuilt in the background, leaving this calculation to be solved.
+func (c *solutionCache) solve(problem PackingProblem, strategy PackingStrategy) (map[uint32]uint64, bool) {
+	sizes := sortedSizes(problem.PackSizes)
+	if !c.serves(problem, strategy, sizes) {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	table := c.table.Load()
+	if table == nil || !slices.Equal(table.sizes, sizes) {
+		c.misses.Add(1)
+		c.rebuildInBackground(sizes)
+		return nil, false
+	}
+	if table.cover[problem.Quantity] == unreachable {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	return table.combination(uint64(table.cover[problem.Quantity])), true
+}
+
+// serves reports whether the table answers a problem on the given sizes.
+func (c *solutionCache) serves(problem PackingProblem, strategy PackingStrategy, sizes []uint32) bool {
+	if _, ok := strategy.(OptimalStrategy); !ok || len(sizes) == 0 || len(sizes) > c.maxSizes || problem.Quantity > c.limit {
+		return false
+	}
+	if problem.Mode.Name != "" && problem.Mode.Name != FillOverfill {
+		return false
+	}
+	for _, size := range sizes {
+		if _, ok := problem.Stock[size]; ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// rebuildInBackground rebuilds the table for sizes unless a rebuild is already running.
+func (c *solutionCache) rebuildInBackground(sizes []uint32) {
+	if !c.rebuilding.CompareAndSwap(false, true) {
+		return
+	}
+
+	c.pending.Add(1)
+	go func() {
+		defer c.pending.Done()
+		defer c.rebuilding.Store(false)
+		c.rebuild(sizes)
+	}()
+}
+
+// rebuild builds the table for sizes, sorted descending, from scratch. The totals run far enough past
+// the limit for the smallest size to cover every quantity up to it. No sizes, or more than the cache
+// holds, leave it empty.
+func (c *solutionCache) rebuild(sizes []uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(sizes) == 0 || len(sizes) > c.maxSizes {
+		c.table.Store(nil)
+		return
+	}
+	if current := c.table.Load(); current != nil && slices.Equal(current.sizes, sizes) {
+		return
+	}
+
+	table := &solutionTable{
+		sizes: slices.Clone(sizes),
+		top:   c.limit + min(uint64(sizes[len(sizes)-1]), c.limit+1) - 1,
+		packs: make([][]uint32, len(sizes)),
+		take:  make([][]uint32, len(sizes)),
+	}
+	table.rebuildFrom(0, c.limit)
+	c.table.Store(table)
+	c.builds.Add(1)
+}
+
+// add folds a pack size added to the catalog into the table, rebuilding only the layers of the sizes
+// smaller than it. An empty table is left for the next lookup to rebuild, and one that would hold more
+// sizes than the cache allows is dropped.
+func (c *solutionCache) add(size uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := c.table.Load()
+	if current == nil {
+		return
+	}
+	i, found := slices.BinarySearchFunc(current.sizes, size, descending)
+	if found {
+		return
+	}
+	if len(current.sizes) >= c.maxSizes {
+		c.table.Store(nil)
+		c.updates.Add(1)
+		return
+	}
+
+	table := &solutionTable{
+		sizes: slices.Insert(slices.Clone(current.sizes), i, size),
+		top:   current.top,
+		packs: slices.Insert(slices.Clone(current.packs), i, nil),
+		take:  slices.Insert(slices.Clone(current.take), i, nil),
+	}
+	table.rebuildFrom(i, c.limit)
+	c.table.Store(table)
+	c.updates.Add(1)
+}
+
+// remove drops a pack size deleted from the catalog from the table, rebuilding only the layers of the
+// sizes smaller than it. Removing the last size empties the table.
+func (c *solutionCache) remove(size uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := c.table.Load()
+	if current == nil {
+		return
+	}
+	i, found := slices.BinarySearchFunc(current.sizes, size, descending)
+	if !found {
+		return
+	}
+	if len(current.sizes) == 1 {
+		c.table.Store(nil)
+		c.updates.Add(1)
+		return
+	}
+
+	table := &solutionTable{
+		sizes: slices.Delete(slices.Clone(current.sizes), i, i+1),
+		top:   current.top,
+		packs: slices.Delete(slices.Clone(current.packs), i, i+1),
+		take:  slices.Delete(slices.Clone(current.take), i, i+1),
+	}
+	table.rebuildFrom(i, c.limit)
+	c.table.Store(table)
+	c.updates.Add(1)
+}
+
+// resize moves a pack size of the catalog to another size in the table, rebuilding only the layers
+// from the first position either size takes.
+func (c *solutionCache) resize(from, to uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := c.table.Load()
+	if current == nil {
+		return
+	}
+	i, found := slices.BinarySearchFunc(current.sizes, from, descending)
+	if !found {
+		return
+	}
+	sizes := slices.Delete(slices.Clone(current.sizes), i, i+1)
+	j, found := slices.BinarySearchFunc(sizes, to, descending)
+	if found {
+		// The catalog does not hold a size twice, so the table no longer matches it.
+		c.table.Store(nil)
+		c.updates.Add(1)
+		return
+	}
+
+	table := &solutionTable{
+		sizes: slices.Insert(sizes, j, to),
+		top:   current.top,
+		packs: slices.Clone(current.packs),
+		take:  slices.Clone(current.take),
+	}
+	table.rebuildFrom(min(i, j), c.limit)
+	c.table.Store(table)
+	c.updates.Add(1)
+}
+
+// descending orders pack sizes largest first for binary searches.
+func descending(a, b uint32) int {
+	switch {
+	case a > b:
+		return -1
+	case a < b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// rebuildFrom computes the layers of sizes[i:] on top of the layer before them, then the cover of
+// every quantity up to limit. Earlier layers are only read, as other tables may share them.
+func (t *solutionTable) rebuildFrom(i int, limit uint64) {
+	for ; i < len(t.sizes); i++ {
+		packs := make([]uint32, t.top+1)
+		if i > 0 {
+			copy(packs, t.packs[i-1])
+		} else {
+			for total := range packs[1:] {
+				packs[total+1] = unreachable
+			}
+		}
+		take := make([]uint32, t.top+1)
+
+		// Walking upwards lets a total reuse the packs of the size already added to total - size.
+		size := uint64(t.sizes[i])
+		for total := size; total <= t.top; total++ {
+			prev := packs[total-size]
+			if prev != unreachable && prev+1 < packs[total] {
+				packs[total] = prev + 1
+				take[total] = take[total-size] + 1
+			}
+		}
+		t.packs[i], t.take[i] = packs, take
+	}
+
+	last := t.packs[len(t.packs)-1]
+	t.cover = make([]uint32, limit+1)
+	next := unreachable
+	for total := t.top; ; total-- {
+		if last[total] != unreachable {
+			next = uint32(total)
+		}
+		if total <= limit {
+			t.cover[total] = next
+		}
+		if total == 0 {
+			break
+		}
+	}
+}
+
+// combination rebuilds the pack counts of the combination tabulated for a reachable total.
+func (t *solutionTable) combination(total uint64) map[uint32]uint64 {
+	result := make(map[uint32]uint64)
+	for i := len(t.sizes) - 1; i >= 0; i-- {
+		if n := t.take[i][total]; n > 0 {
+			result[t.sizes[i]] = uint64(n)
+			total -= uint64(n) * uint64(t.sizes[i])
+		}
+	}
+
+	return result
+}
+
+// stats describes the table and how often it answered calculations.
+func (c *solutionCache) stats() models.SolutionCacheStats {
+	stats := models.SolutionCacheStats{
+		Limit:    c.limit,
+		MaxSizes: c.maxSizes,
+		Sizes:    []uint32{},
+		Hits:     c.hits.Load(),
+		Misses:   c.misses.Load(),
+		Builds:   c.builds.Load(),
+		Updates:  c.updates.Load(),
+	}
+	if table := c.table.Load(); table != nil {
+		stats.Sizes = slices.Clone(table.sizes)
+		stats.Totals = table.top + 1
+		// Every layer holds two counts per total, and the cover one per quantity.
+		stats.MemoryBytes = 4 * (2*uint64(len(table.sizes))*stats.Totals + uint64(len(table.cover)) + uint64(len(table.sizes)))
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+
+	return stats
+}
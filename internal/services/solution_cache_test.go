@@ -0,0 +1,285 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/repositories/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// assertCacheMatchesOptimal checks that every quantity up to the limit of the cache is answered as
+// OptimalStrategy packs it, and from the cache whenever the table reaches the total it ships.
+func assertCacheMatchesOptimal(t *testing.T, cache *solutionCache, sizes []uint32) {
+	t.Helper()
+
+	packSizes := toPackSizes(sizes)
+	top := cache.table.Load().top
+	for orderQty := uint64(0); orderQty <= cache.limit; orderQty++ {
+		msg := fmt.Sprintf("sizes %v, quantity %d", sizes, orderQty)
+		expected, err := OptimalStrategy{}.Pack(PackingProblem{PackSizes: packSizes, Quantity: orderQty})
+		assert.NoError(t, err, msg)
+
+		packs, ok := cache.solve(PackingProblem{PackSizes: packSizes, Quantity: orderQty}, OptimalStrategy{})
+		if packedItems(expected) <= top {
+			assert.True(t, ok, msg)
+		}
+		if ok {
+			assert.Equal(t, expected, packs, msg)
+		}
+	}
+}
+
+func TestSolutionCacheMatchesOptimal(t *testing.T) {
+	for _, sizes := range oraclePackSets {
+		cache := newSolutionCache(600, SolutionCacheMaxSizes)
+		cache.rebuild(sortedSizes(toPackSizes(sizes)))
+		assertCacheMatchesOptimal(t, cache, sizes)
+
+		stats := cache.stats()
+		assert.Equal(t, uint64(1), stats.Builds, "sizes %v", sizes)
+		assert.Equal(t, uint64(601), stats.Hits, "sizes %v", sizes)
+		assert.Equal(t, uint64(0), stats.Misses, "sizes %v", sizes)
+	}
+}
+
+func TestSolutionCacheIncrementalUpdates(t *testing.T) {
+	cache := newSolutionCache(600, SolutionCacheMaxSizes)
+	cache.add(250)
+	assert.Nil(t, cache.table.Load(), "an unbuilt table is not updated")
+
+	cache.rebuild([]uint32{500, 250})
+	steps := []struct {
+		add    bool
+		size   uint32
+		result []uint32
+	}{
+		{add: true, size: 1000, result: []uint32{1000, 500, 250}},
+		{add: true, size: 53, result: []uint32{1000, 500, 250, 53}},
+		{add: false, size: 500, result: []uint32{1000, 250, 53}},
+		{add: true, size: 31, result: []uint32{1000, 250, 53, 31}},
+		{add: false, size: 31, result: []uint32{1000, 250, 53}},
+		{add: false, size: 1000, result: []uint32{250, 53}},
+		{add: true, size: 250, result: []uint32{250, 53}},
+		{add: false, size: 7, result: []uint32{250, 53}},
+	}
+	for _, step := range steps {
+		if step.add {
+			cache.add(step.size)
+		} else {
+			cache.remove(step.size)
+		}
+		table := cache.table.Load()
+		assert.Equal(t, step.result, table.sizes)
+
+		// The layers end up as a table built from scratch for the same sizes would.
+		fresh := &solutionTable{
+			sizes: step.result,
+			top:   table.top,
+			packs: make([][]uint32, len(step.result)),
+			take:  make([][]uint32, len(step.result)),
+		}
+		fresh.rebuildFrom(0, cache.limit)
+		assert.Equal(t, fresh, table, "sizes %v", step.result)
+
+		assertCacheMatchesOptimal(t, cache, step.result)
+	}
+
+	stats := cache.stats()
+	assert.Equal(t, uint64(1), stats.Builds)
+	assert.Equal(t, uint64(6), stats.Updates)
+
+	cache.remove(53)
+	cache.remove(250)
+	assert.Nil(t, cache.table.Load())
+	assert.Equal(t, uint64(0), cache.stats().MemoryBytes)
+}
+
+func TestSolutionCacheResize(t *testing.T) {
+	cache := newSolutionCache(600, SolutionCacheMaxSizes)
+	cache.rebuild([]uint32{500, 250, 53})
+
+	steps := []struct {
+		from, to uint32
+		result   []uint32
+	}{
+		{from: 250, to: 1000, result: []uint32{1000, 500, 53}},
+		{from: 53, to: 31, result: []uint32{1000, 500, 31}},
+		{from: 1000, to: 250, result: []uint32{500, 250, 31}},
+		{from: 7, to: 11, result: []uint32{500, 250, 31}},
+	}
+	for _, step := range steps {
+		cache.resize(step.from, step.to)
+		table := cache.table.Load()
+		assert.Equal(t, step.result, table.sizes)
+		assertCacheMatchesOptimal(t, cache, step.result)
+	}
+	assert.Equal(t, uint64(3), cache.stats().Updates)
+
+	// Moving onto a size already held leaves a table the catalog cannot have.
+	cache.resize(500, 250)
+	assert.Nil(t, cache.table.Load())
+}
+
+func TestSolutionCacheMaxSizes(t *testing.T) {
+	cache := newSolutionCache(100, 3)
+	cache.rebuild([]uint32{50, 20, 10, 5})
+	assert.Nil(t, cache.table.Load(), "too many sizes are not tabulated")
+
+	cache.rebuild([]uint32{50, 20, 10})
+	cache.add(5)
+	assert.Nil(t, cache.table.Load(), "a size past the cap drops the table")
+
+	problem := PackingProblem{PackSizes: toPackSizes([]uint32{50, 20, 10, 5}), Quantity: 60}
+	packs, ok := cache.solve(problem, OptimalStrategy{})
+	assert.False(t, ok)
+	assert.Nil(t, packs)
+	cache.pending.Wait()
+	assert.Nil(t, cache.table.Load(), "a lookup with too many sizes does not rebuild")
+
+	stats := cache.stats()
+	assert.Equal(t, 3, stats.MaxSizes)
+	assert.Equal(t, []uint32{}, stats.Sizes)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(0), stats.MemoryBytes)
+}
+
+func TestSolutionCacheServes(t *testing.T) {
+	packSizes := toPackSizes([]uint32{500, 250})
+	testCases := []struct {
+		name     string
+		problem  PackingProblem
+		strategy PackingStrategy
+		expected bool
+	}{
+		{name: "Optimal overfill", problem: PackingProblem{PackSizes: packSizes, Quantity: 251}, strategy: OptimalStrategy{}, expected: true},
+		{name: "Named overfill", problem: PackingProblem{PackSizes: packSizes, Quantity: 251, Mode: FillMode{Name: FillOverfill}}, strategy: OptimalStrategy{}, expected: true},
+		{name: "Stock on another size", problem: PackingProblem{PackSizes: packSizes, Quantity: 251, Stock: map[uint32]uint32{1000: 1}}, strategy: OptimalStrategy{}, expected: true},
+		{name: "Another strategy", problem: PackingProblem{PackSizes: packSizes, Quantity: 251}, strategy: FewestPacksStrategy{}},
+		{name: "Another fill mode", problem: PackingProblem{PackSizes: packSizes, Quantity: 251, Mode: FillMode{Name: FillExact}}, strategy: OptimalStrategy{}},
+		{name: "Stock on a size", problem: PackingProblem{PackSizes: packSizes, Quantity: 251, Stock: map[uint32]uint32{250: 1}}, strategy: OptimalStrategy{}},
+		{name: "Beyond the limit", problem: PackingProblem{PackSizes: packSizes, Quantity: 1001}, strategy: OptimalStrategy{}},
+		{name: "Too many sizes", problem: PackingProblem{PackSizes: toPackSizes([]uint32{500, 250, 100}), Quantity: 251}, strategy: OptimalStrategy{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := newSolutionCache(1000, 2)
+			cache.rebuild([]uint32{500, 250})
+
+			packs, ok := cache.solve(tc.problem, tc.strategy)
+			assert.Equal(t, tc.expected, ok)
+			stats := cache.stats()
+			if tc.expected {
+				assert.Equal(t, map[uint32]uint64{500: 1}, packs)
+				assert.Equal(t, uint64(1), stats.Hits)
+				assert.Equal(t, 1.0, stats.HitRate)
+			} else {
+				assert.Nil(t, packs)
+				assert.Equal(t, uint64(1), stats.Misses)
+				assert.Equal(t, 0.0, stats.HitRate)
+			}
+		})
+	}
+}
+
+func TestSolutionCacheRebuildsInBackground(t *testing.T) {
+	cache := newSolutionCache(1000, SolutionCacheMaxSizes)
+	cache.rebuild([]uint32{500, 250})
+
+	// The lookup for other sizes is solved on demand while the table is rebuilt for the next one.
+	problem := PackingProblem{PackSizes: toPackSizes([]uint32{300}), Quantity: 301}
+	packs, ok := cache.solve(problem, OptimalStrategy{})
+	assert.False(t, ok)
+	assert.Nil(t, packs)
+	cache.pending.Wait()
+
+	packs, ok = cache.solve(problem, OptimalStrategy{})
+	assert.True(t, ok)
+	assert.Equal(t, map[uint32]uint64{300: 2}, packs)
+
+	stats := cache.stats()
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, []uint32{300}, stats.Sizes)
+	assert.Equal(t, uint64(2), stats.Builds)
+	assert.Equal(t, uint64(1000+300), stats.Totals)
+	// Two counts per total for the one size, a cover per quantity and the size itself.
+	assert.Equal(t, uint64(4*(2*1300+1001+1)), stats.MemoryBytes)
+}
+
+func TestPacksCalculatorSolutionCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockPackSizeRepository(ctrl)
+	mockStockRepo := mocks.NewMockStockRepository(ctrl)
+	mockCalculationRepo := mocks.NewMockCalculationRepository(ctrl)
+	mockCalculationRepo.EXPECT().SaveCalculation(gomock.Any()).Return(nil).AnyTimes()
+	mockStockRepo.EXPECT().GetStock().Return(nil, nil).AnyTimes()
+
+	packSizes := toPackSizes([]uint32{500, 250})
+	mockRepo.EXPECT().GetPackSizes().Return(packSizes, nil).Times(1)
+	service := NewPacksCalculatorService(mockRepo, mockStockRepo, mockCalculationRepo)
+	assert.NoError(t, service.WarmSolutionCache())
+	stats := service.SolutionCacheStats()
+	assert.Equal(t, uint64(SolutionCacheLimit), stats.Limit)
+	assert.Equal(t, []uint32{500, 250}, stats.Sizes)
+	assert.Equal(t, uint64(1), stats.Builds)
+
+	// Adding and deleting a pack size updates the table in place.
+	mockRepo.EXPECT().CreatePackSize(models.PackSize{Size: 1000}, "alice").Return(nil).Times(1)
+	mockRepo.EXPECT().DeletePackSize(uint32(250), "alice").Return(nil).Times(1)
+	mockRepo.EXPECT().DeletePackSize(uint32(750), "alice").Return(models.ErrPackSizeNotFound).Times(1)
+	assert.NoError(t, service.AddPackSize(models.PackSize{Size: 1000}, "alice"))
+	assert.NoError(t, service.DeletePackSize(250, "alice"))
+	assert.ErrorIs(t, service.DeletePackSize(750, "alice"), models.ErrPackSizeNotFound)
+
+	catalog := &models.CatalogVersion{ID: 2, PackSizes: toPackSizes([]uint32{1000, 500})}
+	mockRepo.EXPECT().GetCatalogVersion(uint32(0)).Return(catalog, nil).Times(3)
+	for _, orderQty := range []uint64{1001, SolutionCacheLimit + 1} {
+		result, err := service.CalculatePacks(orderQty, CalculateOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, packedItems(result.Packs), (orderQty+499)/500*500)
+	}
+	_, err := service.CalculatePacks(1001, CalculateOptions{Strategy: StrategyFewestPacks})
+	assert.NoError(t, err)
+
+	stats = service.SolutionCacheStats()
+	assert.Equal(t, []uint32{1000, 500}, stats.Sizes)
+	assert.Equal(t, uint64(1), stats.Builds)
+	assert.Equal(t, uint64(2), stats.Updates)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Misses)
+	assert.InDelta(t, 1.0/3, stats.HitRate, 1e-9)
+
+	// Every other catalog change brings the table up to date before the next calculation.
+	size := uint32(750)
+	mockRepo.EXPECT().GetPackSize(uint32(3)).Return(&models.PackSize{ID: 3, Size: 1000}, nil).Times(1)
+	mockRepo.EXPECT().UpdatePackSize(models.PackSize{ID: 3, Size: 750}, "alice").Return(nil).Times(1)
+	_, err = service.UpdatePackSize(3, models.PackSizeUpdateRequest{Size: &size}, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{750, 500}, service.SolutionCacheStats().Sizes)
+
+	mockRepo.EXPECT().ReplacePackSizes(toPackSizes([]uint32{300, 200}), "alice").Return(&models.CatalogChange{}, nil).Times(1)
+	_, err = service.ReplacePackSizes(toPackSizes([]uint32{300, 200}), "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{300, 200}, service.SolutionCacheStats().Sizes)
+
+	mockRepo.EXPECT().GetPackSizes().Return(toPackSizes([]uint32{300, 200}), nil).Times(1)
+	mockRepo.EXPECT().MergePackSizes(gomock.Any(), "alice").Return(nil).Times(1)
+	_, err = service.ImportPackSizes(strings.NewReader("size\n100\n"), ImportOptions{Format: CatalogFormatCSV, Actor: "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{300, 200, 100}, service.SolutionCacheStats().Sizes)
+
+	mockRepo.EXPECT().GetPackSizes().Return(toPackSizes([]uint32{300, 200, 100}), nil).Times(1)
+	mockRepo.EXPECT().ReplacePackSizes(gomock.Any(), "alice").Return(&models.CatalogChange{}, nil).Times(1)
+	_, err = service.ImportPackSizes(strings.NewReader("size\n400\n"), ImportOptions{Format: CatalogFormatCSV, Mode: ImportModeReplace, Actor: "alice"})
+	assert.NoError(t, err)
+
+	stats = service.SolutionCacheStats()
+	assert.Equal(t, []uint32{400}, stats.Sizes)
+	assert.Equal(t, uint64(3), stats.Builds)
+	assert.Equal(t, uint64(4), stats.Updates)
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"github.com/klemis/packs-calculator/internal/repositories"
+	"github.com/klemis/packs-calculator/models"
+)
+
+// StockManager defines the interface for reading and changing the packs on hand.
+type StockManager interface {
+	GetStock() ([]models.PackStock, error)
+	SetStock(size uint32, quantity uint32) error
+	AdjustStock(size uint32, delta int64) (uint32, error)
+	ClearStock(size uint32) error
+}
+
+// StockService is an implementation of StockManager.
+type StockService struct {
+	repo repositories.StockRepository
+}
+
+// NewStockService creates a new instance of StockManager with injected repository.
+func NewStockService(stockRepo repositories.StockRepository) StockManager {
+	return &StockService{
+		repo: stockRepo,
+	}
+}
+
+// GetStock returns the stock of every pack size that has its stock tracked.
+func (s *StockService) GetStock() ([]models.PackStock, error) {
+	stock, err := s.repo.GetStock()
+	if err != nil {
+		return nil, err
+	}
+	if stock == nil {
+		stock = []models.PackStock{}
+	}
+
+	return stock, nil
+}
+
+// SetStock sets the number of packs on hand for a pack size.
+func (s *StockService) SetStock(size uint32, quantity uint32) error {
+	return s.repo.SetStock(size, quantity)
+}
+
+// AdjustStock adds delta packs (negative to remove) to the stock of a pack size and returns the new quantity.
+func (s *StockService) AdjustStock(size uint32, delta int64) (uint32, error) {
+	return s.repo.AdjustStock(size, delta)
+}
+
+// ClearStock stops tracking the stock of a pack size, making its supply unlimited.
+func (s *StockService) ClearStock(size uint32) error {
+	return s.repo.ClearStock(size)
+}
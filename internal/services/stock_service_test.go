@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klemis/packs-calculator/internal/repositories/mocks"
+	"github.com/klemis/packs-calculator/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetStock(t *testing.T) {
+	testCases := []struct {
+		name         string
+		mockResponse []models.PackStock
+		mockError    error
+		expected     []models.PackStock
+		expectError  bool
+	}{
+		{
+			name:         "Tracked stock",
+			mockResponse: []models.PackStock{{Size: 500, Quantity: 3}},
+			expected:     []models.PackStock{{Size: 500, Quantity: 3}},
+		},
+		{
+			name:         "No tracked stock",
+			mockResponse: nil,
+			expected:     []models.PackStock{},
+		},
+		{
+			name:        "Repository error",
+			mockError:   errors.New("query error"),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockRepo := mocks.NewMockStockRepository(ctrl)
+			mockRepo.EXPECT().GetStock().Return(tc.mockResponse, tc.mockError).Times(1)
+
+			service := NewStockService(mockRepo)
+
+			stock, err := service.GetStock()
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expected, stock)
+		})
+	}
+}
+
+func TestAdjustStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockStockRepository(ctrl)
+	mockRepo.EXPECT().AdjustStock(uint32(500), int64(-2)).Return(uint32(8), nil).Times(1)
+
+	service := NewStockService(mockRepo)
+
+	quantity, err := service.AdjustStock(500, -2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(8), quantity)
+}
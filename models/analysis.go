@@ -0,0 +1,36 @@
+package models
+
+// PackSetAnalysis describes how a set of pack sizes covers order quantities.
+type PackSetAnalysis struct {
+	// PackSizes are the sizes analysed, largest first.
+	PackSizes []uint32 `json:"pack_sizes"`
+	// GCD is the greatest common divisor of the sizes; only its multiples can be shipped exactly.
+	GCD uint64 `json:"gcd"`
+	// FrobeniusNumber is the largest quantity that cannot be shipped exactly, or -1 when every quantity
+	// can. It is nil when the GCD is above 1, as infinitely many quantities cannot.
+	FrobeniusNumber *int64 `json:"frobenius_number"`
+	// Unreachable lists the quantities from 1 up to, but excluding, UnreachableBelow that cannot be
+	// shipped exactly.
+	UnreachableBelow uint64   `json:"unreachable_below"`
+	Unreachable      []uint64 `json:"unreachable"`
+	// Overfill measures the overfill of the smallest covering combination over a range of quantities.
+	Overfill OverfillAnalysis `json:"overfill"`
+	// Redundant lists the sizes the other sizes add up to exactly, largest first.
+	Redundant []RedundantSize `json:"redundant"`
+}
+
+// OverfillAnalysis is the overfill of covering every quantity from From to To with the fewest items.
+type OverfillAnalysis struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+	// Worst is the largest overfill in the range, first seen at WorstQuantity.
+	Worst         uint64  `json:"worst"`
+	WorstQuantity uint64  `json:"worst_quantity"`
+	Average       float64 `json:"average"`
+}
+
+// RedundantSize is a pack size that a combination of the other sizes adds up to.
+type RedundantSize struct {
+	Size  uint32            `json:"size"`
+	Packs map[uint32]uint64 `json:"packs"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Audit actions recorded for catalog changes.
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionReplace = "replace"
+	AuditActionImport  = "import"
+)
+
+// AuditEntry records who changed the pack catalog, how and when, with the catalog before and after.
+type AuditEntry struct {
+	ID        uint64          `json:"id"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	CreatedAt time.Time       `json:"created_at"`
+	Before    *CatalogVersion `json:"before"`
+	After     *CatalogVersion `json:"after"`
+}
+
+// AuditFilter selects a page of the audit entries. Zero times and an empty actor do not filter.
+type AuditFilter struct {
+	From   time.Time
+	To     time.Time
+	Actor  string
+	Limit  uint32
+	Offset uint64
+}
+
+// AuditPage is a page of the audit entries, newest first.
+type AuditPage struct {
+	Entries []AuditEntry `json:"entries"`
+	Total   uint64       `json:"total"`
+	Limit   uint32       `json:"limit"`
+	Offset  uint64       `json:"offset"`
+}
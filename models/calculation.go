@@ -0,0 +1,133 @@
+package models
+
+import "time"
+
+// Calculation is the outcome of calculating packs for an order quantity.
+type Calculation struct {
+	ID             uint64            `json:"id"`
+	Quantity       uint64            `json:"quantity"`
+	Strategy       string            `json:"strategy"`
+	CatalogVersion uint32            `json:"catalog_version"`
+	Packs          map[uint32]uint64 `json:"packs"`
+	Cost           CostBreakdown     `json:"cost"`
+	// Alternatives are the best pack combinations for the quantity, best first, when asked for.
+	Alternatives []PackAlternative `json:"alternatives,omitempty"`
+}
+
+// PackAlternative is one of the best pack combinations for an order quantity, ranked by the objective
+// of the strategy that calculated it.
+type PackAlternative struct {
+	Packs     map[uint32]uint64 `json:"packs"`
+	Items     uint64            `json:"items"`
+	Overfill  uint64            `json:"overfill"`
+	PackCount uint64            `json:"pack_count"`
+	TotalCost float64           `json:"total_cost"`
+}
+
+// CostBreakdown itemises what a pack combination costs to ship.
+type CostBreakdown struct {
+	Lines        []CostLine `json:"lines"`
+	Price        float64    `json:"price"`
+	HandlingCost float64    `json:"handling_cost"`
+	Weight       float64    `json:"weight"`
+	ShippingCost float64    `json:"shipping_cost"`
+	Total        float64    `json:"total"`
+}
+
+// CostLine is the cost of all packs of one size in a combination.
+type CostLine struct {
+	Size         uint32  `json:"size"`
+	Count        uint64  `json:"count"`
+	Price        float64 `json:"price"`
+	HandlingCost float64 `json:"handling_cost"`
+	Weight       float64 `json:"weight"`
+	ShippingCost float64 `json:"shipping_cost"`
+	Total        float64 `json:"total"`
+}
+
+// CalculationRecord is a calculation kept in the history for dispute handling and analytics.
+type CalculationRecord struct {
+	ID             uint64            `json:"id"`
+	Quantity       uint64            `json:"quantity"`
+	Strategy       string            `json:"strategy"`
+	CatalogVersion uint32            `json:"catalog_version"`
+	ShippingRate   float64           `json:"shipping_rate"`
+	Packs          map[uint32]uint64 `json:"packs"`
+	Items          uint64            `json:"items"`
+	Overfill       uint64            `json:"overfill"`
+	TotalCost      float64           `json:"total_cost"`
+	LatencyMicros  int64             `json:"latency_us"`
+	Caller         string            `json:"caller"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+// CalculationFilter selects a page of the calculation history. Zero times do not filter.
+type CalculationFilter struct {
+	From        time.Time
+	To          time.Time
+	MinQuantity uint64
+	// MaxQuantity bounds the quantity inclusively; math.MaxUint64 leaves it unbounded.
+	MaxQuantity uint64
+	Limit       uint32
+	Offset      uint64
+}
+
+// CalculationPage is a page of the calculation history, newest first.
+type CalculationPage struct {
+	Calculations []CalculationRecord `json:"calculations"`
+	Total        uint64              `json:"total"`
+	Limit        uint32              `json:"limit"`
+	Offset       uint64              `json:"offset"`
+}
+
+// BatchCalculationRequest asks for the packs of many order quantities against one catalog.
+type BatchCalculationRequest struct {
+	Items              []BatchItemRequest `json:"items" binding:"required,min=1,max=100000,dive"`
+	Strategy           string             `json:"strategy"`
+	ShippingRate       float64            `json:"shipping_rate" binding:"min=0"`
+	Mode               string             `json:"mode"`
+	MaxOverfill        *uint64            `json:"max_overfill"`
+	MaxOverfillPercent *float64           `json:"max_overfill_percent" binding:"omitempty,min=0"`
+	CatalogVersion     uint32             `json:"catalog_version"`
+}
+
+// BatchItemRequest is one order quantity of a batch, with an optional ID echoed back in its result.
+type BatchItemRequest struct {
+	ID       string  `json:"id"`
+	Quantity *uint64 `json:"quantity" binding:"required"`
+}
+
+// WhatIfCalculationRequest asks for the packs of an order quantity against a caller-supplied set of pack
+// sizes instead of the catalog, optionally compared with the live catalog.
+type WhatIfCalculationRequest struct {
+	Quantity           *uint64           `json:"quantity" binding:"required"`
+	PackSizes          []PackSizeRequest `json:"pack_sizes" binding:"required,min=1,dive"`
+	Strategy           string            `json:"strategy"`
+	ShippingRate       float64           `json:"shipping_rate" binding:"min=0"`
+	Mode               string            `json:"mode"`
+	MaxOverfill        *uint64           `json:"max_overfill"`
+	MaxOverfillPercent *float64          `json:"max_overfill_percent" binding:"omitempty,min=0"`
+	Compare            bool              `json:"compare"`
+}
+
+// SolutionCacheStats describes the precomputed solutions of the optimal strategy for the live catalog
+// and how often they answered calculations against it.
+type SolutionCacheStats struct {
+	// Limit is the largest order quantity the cache answers.
+	Limit uint64 `json:"limit"`
+	// MaxSizes is the most pack sizes the cache holds solutions for.
+	MaxSizes int `json:"max_sizes"`
+	// Sizes are the pack sizes the solutions are for, largest first; empty until they are computed, and
+	// while the catalog has more than MaxSizes.
+	Sizes       []uint32 `json:"sizes"`
+	Totals      uint64   `json:"totals"`
+	MemoryBytes uint64   `json:"memory_bytes"`
+	// Hits counts the calculations answered from the cache and Misses those solved on demand.
+	Hits    uint64  `json:"hits"`
+	Misses  uint64  `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+	// Builds counts the times the solutions were computed from scratch and Updates the times a pack
+	// size was added or removed in place.
+	Builds  uint64 `json:"builds"`
+	Updates uint64 `json:"updates"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// CatalogVersion is an immutable snapshot of the pack sizes, recorded on every catalog change.
+type CatalogVersion struct {
+	ID        uint32     `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	PackSizes []PackSize `json:"packs"`
+}
+
+// ImportLineError reports why a line of an imported catalog was rejected.
+type ImportLineError struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportReport describes what a catalog import changed, or would change on a dry run, largest first.
+type ImportReport struct {
+	Mode      string   `json:"mode"`
+	DryRun    bool     `json:"dry_run"`
+	Rows      int      `json:"rows"`
+	Added     []uint32 `json:"added"`
+	Updated   []uint32 `json:"updated"`
+	Unchanged []uint32 `json:"unchanged"`
+	Removed   []uint32 `json:"removed"`
+}
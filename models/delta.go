@@ -0,0 +1,67 @@
+package models
+
+import (
+	"cmp"
+	"math/big"
+	"strconv"
+)
+
+// Delta is the signed difference between two counts, kept as a sign and a magnitude so that counts
+// anywhere in the uint64 range can be told apart without wrapping. It marshals to a JSON number.
+type Delta struct {
+	Negative  bool
+	Magnitude uint64
+}
+
+// NewDelta returns the difference to minus from.
+func NewDelta(from, to uint64) Delta {
+	if to < from {
+		return Delta{Negative: true, Magnitude: from - to}
+	}
+
+	return Delta{Magnitude: to - from}
+}
+
+// Sign returns -1, 0 or 1 as the delta is negative, zero or positive.
+func (d Delta) Sign() int {
+	switch {
+	case d.Magnitude == 0:
+		return 0
+	case d.Negative:
+		return -1
+	}
+
+	return 1
+}
+
+// Compare returns -1, 0 or 1 as d is less than, equal to or greater than other.
+func (d Delta) Compare(other Delta) int {
+	if c := cmp.Compare(d.Sign(), other.Sign()); c != 0 {
+		return c
+	}
+	if d.Negative {
+		return cmp.Compare(other.Magnitude, d.Magnitude)
+	}
+
+	return cmp.Compare(d.Magnitude, other.Magnitude)
+}
+
+// Int returns the delta as a big integer, for sums that may not fit in 64 bits.
+func (d Delta) Int() *big.Int {
+	i := new(big.Int).SetUint64(d.Magnitude)
+	if d.Negative {
+		i.Neg(i)
+	}
+
+	return i
+}
+
+// MarshalJSON writes the delta as a JSON number.
+func (d Delta) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 0, 21)
+	if d.Negative && d.Magnitude != 0 {
+		b = append(b, '-')
+	}
+
+	return strconv.AppendUint(b, d.Magnitude, 10), nil
+}
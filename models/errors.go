@@ -0,0 +1,27 @@
+package models
+
+import "errors"
+
+// Domain errors shared by the repositories, services and handlers.
+var (
+	ErrPackSizeExists   = errors.New("pack size already exists")
+	ErrPackSizeNotFound = errors.New("pack size not found")
+	ErrNoPackSizes      = errors.New("no pack sizes configured")
+	ErrInvalidSize      = errors.New("invalid pack size")
+	ErrDuplicateSize    = errors.New("duplicate pack size")
+	ErrInvalidCatalog   = errors.New("invalid catalog file")
+
+	ErrCatalogVersionNotFound = errors.New("catalog version not found")
+	ErrCalculationNotFound    = errors.New("calculation not found")
+	ErrInvalidLine            = errors.New("invalid line")
+
+	ErrOrderNotFound          = errors.New("order not found")
+	ErrInvalidOrderTransition = errors.New("invalid order transition")
+	ErrNotEnoughStock         = errors.New("not enough stock to ship order")
+
+	ErrIdempotencyKeyReused     = errors.New("idempotency key already used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still in progress")
+
+	ErrStockNotTracked        = errors.New("stock not tracked for pack size")
+	ErrInvalidStockAdjustment = errors.New("stock not tracked for pack size or adjustment would take it below 0 or above 2147483647")
+)
@@ -0,0 +1,16 @@
+package models
+
+// StoredResponse is the response to a request made with an idempotency key, replayed on retries.
+type StoredResponse struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// IdempotencyRecord is what is known about a used idempotency key.
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string
+	// Response is nil while the first request with the key is still being handled.
+	Response *StoredResponse
+}
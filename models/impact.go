@@ -0,0 +1,51 @@
+package models
+
+import "math/big"
+
+// CatalogImpactRequest proposes a catalog change to report the impact of: the packs added or replacing
+// the catalog, the size removed, or the ID of the pack size updated with the fields of Update.
+type CatalogImpactRequest struct {
+	Change string                 `json:"change" binding:"required,oneof=add remove update replace"`
+	Packs  []PackSizeRequest      `json:"packs" binding:"omitempty,dive"`
+	Size   uint32                 `json:"size"`
+	ID     uint32                 `json:"id"`
+	Update *PackSizeUpdateRequest `json:"update"`
+}
+
+// CatalogImpact reports how a proposed catalog change would repack a set of orders, each calculated
+// against the current and the proposed pack sizes.
+type CatalogImpact struct {
+	// Change is "add", "remove", "update" or "replace".
+	Change string `json:"change"`
+	// Source is "range" when every quantity of a range was recalculated once, or "history" when the
+	// quantities of recent calculations were, weighted by how often each was asked for.
+	Source   string `json:"source"`
+	Strategy string `json:"strategy"`
+	// CurrentSizes and ProposedSizes are the pack sizes before and after the change, largest first.
+	CurrentSizes  []uint32 `json:"current_sizes"`
+	ProposedSizes []uint32 `json:"proposed_sizes"`
+	Quantities    int      `json:"quantities"`
+	Orders        uint64   `json:"orders"`
+	// ChangedOrders is the number of orders that would ship a different mix of packs, of which
+	// WorseOrders would ship more items, or as many items in more packs, and BetterOrders the reverse.
+	ChangedOrders uint64 `json:"changed_orders"`
+	WorseOrders   uint64 `json:"worse_orders"`
+	BetterOrders  uint64 `json:"better_orders"`
+	// OverfillDelta and PackCountDelta are the changes in items and packs shipped over all the orders,
+	// which can outgrow 64 bits when many orders change.
+	OverfillDelta  *big.Int `json:"overfill_delta"`
+	PackCountDelta *big.Int `json:"pack_count_delta"`
+	// Worse lists the quantities the change makes worse, hardest hit first.
+	Worse []QuantityImpact `json:"worse"`
+}
+
+// QuantityImpact is how a catalog change would repack the orders for one quantity.
+type QuantityImpact struct {
+	Quantity uint64            `json:"quantity"`
+	Orders   uint64            `json:"orders"`
+	Current  map[uint32]uint64 `json:"current"`
+	Proposed map[uint32]uint64 `json:"proposed"`
+	// OverfillDelta and PackCountDelta are the changes in items and packs shipped per order.
+	OverfillDelta  Delta `json:"overfill_delta"`
+	PackCountDelta Delta `json:"pack_count_delta"`
+}
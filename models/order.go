@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// OrderStatus is a stage of the order lifecycle.
+type OrderStatus string
+
+// Order lifecycle statuses. Orders start quoted; shipped and cancelled orders are final.
+const (
+	OrderQuoted    OrderStatus = "quoted"
+	OrderConfirmed OrderStatus = "confirmed"
+	OrderPacked    OrderStatus = "packed"
+	OrderShipped   OrderStatus = "shipped"
+	OrderCancelled OrderStatus = "cancelled"
+)
+
+// Order is a customer order with the packs allocated to it by a calculation.
+type Order struct {
+	ID             uint64            `json:"id"`
+	Status         OrderStatus       `json:"status"`
+	Quantity       uint64            `json:"quantity"`
+	CalculationID  uint64            `json:"calculation_id"`
+	CatalogVersion uint32            `json:"catalog_version"`
+	Strategy       string            `json:"strategy"`
+	Packs          map[uint32]uint64 `json:"packs"`
+	TotalCost      float64           `json:"total_cost"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+type OrderRequest struct {
+	Quantity           uint64   `json:"quantity" binding:"required"`
+	Strategy           string   `json:"strategy"`
+	ShippingRate       float64  `json:"shipping_rate" binding:"min=0"`
+	Mode               string   `json:"mode"`
+	MaxOverfill        *uint64  `json:"max_overfill"`
+	MaxOverfillPercent *float64 `json:"max_overfill_percent" binding:"omitempty,min=0"`
+}
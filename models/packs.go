@@ -1,10 +1,40 @@
 package models
 
 type PackSize struct {
-	ID   uint32 `json:"id"`
-	Size uint32 `json:"size"`
+	ID           uint32  `json:"id"`
+	Size         uint32  `json:"size"`
+	Price        float64 `json:"price"`
+	Weight       float64 `json:"weight"`
+	HandlingCost float64 `json:"handling_cost"`
+}
+
+// UnitCost returns the cost of shipping one pack, given the shipping cost per unit of weight.
+func (p PackSize) UnitCost(shippingRate float64) float64 {
+	return p.Price + p.HandlingCost + p.Weight*shippingRate
 }
 
 type PackSizeRequest struct {
-	Size uint32 `json:"size" binding:"required"`
+	Size         uint32  `json:"size" binding:"required"`
+	Price        float64 `json:"price" binding:"min=0"`
+	Weight       float64 `json:"weight" binding:"min=0"`
+	HandlingCost float64 `json:"handling_cost" binding:"min=0"`
+}
+
+// PackSizeUpdateRequest changes only the fields that are present.
+type PackSizeUpdateRequest struct {
+	Size         *uint32  `json:"size" binding:"omitempty,gt=0"`
+	Price        *float64 `json:"price" binding:"omitempty,min=0"`
+	Weight       *float64 `json:"weight" binding:"omitempty,min=0"`
+	HandlingCost *float64 `json:"handling_cost" binding:"omitempty,min=0"`
+}
+
+// PackSizesReplaceRequest is the full set of pack sizes the catalog is replaced with.
+type PackSizesReplaceRequest struct {
+	Packs []PackSizeRequest `json:"packs" binding:"required,min=1,dive"`
+}
+
+// CatalogChange reports the sizes a catalog replacement added and removed, largest first.
+type CatalogChange struct {
+	Added   []uint32 `json:"added"`
+	Removed []uint32 `json:"removed"`
 }
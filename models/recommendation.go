@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// QuantityCount is how many orders asked for one quantity.
+type QuantityCount struct {
+	Quantity uint64 `json:"quantity"`
+	Count    uint64 `json:"count"`
+}
+
+// PackRecommendationRequest asks for the pack catalogs that best serve a distribution of order quantities,
+// uploaded or else drawn from the calculation history between From and To.
+type PackRecommendationRequest struct {
+	Quantities []QuantityCountRequest `json:"quantities" binding:"omitempty,max=100000,dive"`
+	From       *time.Time             `json:"from"`
+	To         *time.Time             `json:"to"`
+	Sizes      int                    `json:"sizes" binding:"required,min=1"`
+	MinSize    uint32                 `json:"min_size"`
+	MaxSize    uint32                 `json:"max_size"`
+	MultipleOf uint32                 `json:"multiple_of"`
+	PackWeight float64                `json:"pack_weight" binding:"min=0"`
+	Limit      int                    `json:"limit" binding:"omitempty,min=1"`
+}
+
+// QuantityCountRequest is one order quantity of an uploaded distribution; a missing count is 1.
+type QuantityCountRequest struct {
+	Quantity uint64 `json:"quantity" binding:"required"`
+	Count    uint64 `json:"count"`
+}
+
+// PackRecommendation ranks candidate pack catalogs for a distribution of order quantities, best first.
+type PackRecommendation struct {
+	// Source is "upload" or "history".
+	Source     string             `json:"source"`
+	Orders     uint64             `json:"orders"`
+	Quantities int                `json:"quantities"`
+	Candidates []CatalogCandidate `json:"candidates"`
+}
+
+// CatalogCandidate is a recommended set of pack sizes with how it would have packed the orders, each
+// covered with the fewest items and then the fewest packs.
+type CatalogCandidate struct {
+	Rank int `json:"rank"`
+	// Sizes are the pack sizes of the catalog, largest first.
+	Sizes []uint32 `json:"sizes"`
+	// Score is the expected overfill plus the pack weight per expected pack; lower is better.
+	Score             float64 `json:"score"`
+	ExpectedOverfill  float64 `json:"expected_overfill"`
+	OverfillPercent   float64 `json:"overfill_percent"`
+	ExpectedPackCount float64 `json:"expected_pack_count"`
+	WorstOverfill     uint64  `json:"worst_overfill"`
+	// ExactFitRate is the share of orders shipped without overfill.
+	ExactFitRate float64 `json:"exact_fit_rate"`
+}
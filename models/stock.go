@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// PackStock is the number of packs of one size on hand.
+type PackStock struct {
+	Size      uint32    `json:"size"`
+	Quantity  uint32    `json:"quantity"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StockRequest sets the packs on hand, which the pack_stock column holds as a 32-bit signed integer.
+type StockRequest struct {
+	Quantity *uint32 `json:"quantity" binding:"required,max=2147483647"`
+}
+
+// StockAdjustmentRequest adds or removes packs on hand. No larger delta can leave the stock in range.
+type StockAdjustmentRequest struct {
+	Delta int64 `json:"delta" binding:"required,min=-2147483647,max=2147483647"`
+}
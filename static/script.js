@@ -12,7 +12,7 @@ document.getElementById('add-pack-form').addEventListener('submit', function (e)
     })
         .then(response => response.json())
         .then(data => {
-            document.getElementById('add-pack-message').textContent = data.message || data.error; // ID matches the message div
+            document.getElementById('add-pack-message').textContent = data.message || data.detail || data.title; // ID matches the message div
         })
         .catch(error => {
             document.getElementById('add-pack-message').textContent = 'Error: ' + error;
@@ -33,7 +33,7 @@ document.getElementById('delete-pack-form').addEventListener('submit', function
     })
         .then(response => response.json())
         .then(data => {
-            document.getElementById('delete-pack-message').textContent = data.message || data.error; // ID matches the message div
+            document.getElementById('delete-pack-message').textContent = data.message || data.detail || data.title; // ID matches the message div
         })
         .catch(error => {
             document.getElementById('delete-pack-message').textContent = 'Error: ' + error;
@@ -44,12 +44,19 @@ document.getElementById('delete-pack-form').addEventListener('submit', function
 document.getElementById('calculate-pack-form').addEventListener('submit', function (e) {
     e.preventDefault();
     const items = document.getElementById('items').value;
+    const strategy = document.getElementById('strategy').value;
 
-    fetch(`/api/v1/calculate?quantity=${items}`, {
+    fetch(`/api/v1/calculate?quantity=${items}&strategy=${strategy}`, {
         method: 'GET',
     })
         .then(response => response.json())
         .then(data => {
+            // Failures are reported as problem details rather than a calculation.
+            if (data.status && data.title) {
+                document.getElementById('calculate-result').textContent = 'Error: ' + (data.detail || data.title);
+                return;
+            }
+
             let result = 'Packs required: ';
             for (const [size, count] of Object.entries(data.packs)) {
                 result += `${count} x ${size} pack(s), `;